@@ -3,14 +3,72 @@ package config
 import (
 	"github.com/spf13/viper"
 	"log"
+	"time"
 )
 
 type Config struct {
-	PORT        string
-	DB_NAME     string
-	DB_USERNAME string
-	DB_PASSWORD string
-	DB_URL      string
+	PORT                 string
+	DB_NAME              string
+	DB_USERNAME          string
+	DB_PASSWORD          string
+	DB_URL               string
+	TRASH_RETENTION      time.Duration
+	TRASH_PURGE_INTERVAL time.Duration
+	ACCESS_TOKEN_TTL     time.Duration
+	REFRESH_TOKEN_TTL    time.Duration
+	TOKEN_PURGE_INTERVAL time.Duration
+	// JWT_ALGORITHM is HS256 (signed with SECRET_TOKEN), RS256 or EdDSA.
+	JWT_ALGORITHM             string
+	JWT_KEYS_DIR              string
+	JWT_KEY_ROTATION_INTERVAL time.Duration
+	JWT_KEY_RELOAD_INTERVAL   time.Duration
+	APP_URL                   string
+	// MAILER is "smtp" or "outbox"; the outbox keeps messages in memory and
+	// writes them to MAIL_OUTBOX_DIR if set.
+	MAILER                   string
+	MAIL_FROM                string
+	MAIL_OUTBOX_DIR          string
+	SMTP_HOST                string
+	SMTP_PORT                string
+	SMTP_USERNAME            string
+	SMTP_PASSWORD            string
+	EMAIL_VERIFICATION_TTL   time.Duration
+	VERIFICATION_RESEND_WAIT time.Duration
+	PASSWORD_RESET_TTL       time.Duration
+	// PASSWORD_RESET_URL is the frontend page password reset emails link
+	// to. When empty they link to the form at APP_URL/password/reset.
+	PASSWORD_RESET_URL string
+	// Failed logins lock an account or client IP once they reach the
+	// threshold.
+	LOGIN_LOCKOUT_THRESHOLD    int
+	LOGIN_LOCKOUT_DURATION     time.Duration
+	LOGIN_IP_LOCKOUT_THRESHOLD int
+	TOTP_ISSUER                string
+	MFA_CHALLENGE_TTL          time.Duration
+	PASSWORD_MIN_LENGTH        int
+	PASSWORD_MIN_CLASSES       int
+	// BREACHED_PASSWORDS_DIR holds SHA-1 hash prefix files of breached
+	// passwords. The check is off when empty.
+	BREACHED_PASSWORDS_DIR string
+	// PASSWORD_HASHER is "bcrypt" or "argon2id". Existing hashes are
+	// upgraded on the next successful login. ARGON2_MEMORY is in KiB.
+	PASSWORD_HASHER    string
+	BCRYPT_COST        int
+	ARGON2_MEMORY      uint32
+	ARGON2_ITERATIONS  uint32
+	ARGON2_PARALLELISM uint8
+	// OIDC_PROVIDERS is a comma separated list of OpenID Connect provider
+	// names, each configured through OIDC_<NAME>_ISSUER, _CLIENT_ID,
+	// _CLIENT_SECRET and optionally _SCOPES.
+	OIDC_PROVIDERS string
+	OIDC_LOGIN_TTL time.Duration
+	// BOOTSTRAP_ADMIN_EMAIL names an existing account that is made an admin
+	// at startup, so that the first admin can be created without SQL.
+	BOOTSTRAP_ADMIN_EMAIL string
+	// Deleted accounts are erased once ACCOUNT_DELETION_GRACE has passed;
+	// signing in before that keeps the account.
+	ACCOUNT_DELETION_GRACE   time.Duration
+	ACCOUNT_ERASURE_INTERVAL time.Duration
 }
 
 var ENV *Config
@@ -20,6 +78,41 @@ func LoadConfig() {
 	viper.SetConfigType("env")
 	viper.SetConfigName(".env")
 
+	viper.SetDefault("TRASH_RETENTION", "720h")
+	viper.SetDefault("TRASH_PURGE_INTERVAL", "1h")
+	viper.SetDefault("ACCESS_TOKEN_TTL", "15m")
+	viper.SetDefault("REFRESH_TOKEN_TTL", "720h")
+	viper.SetDefault("TOKEN_PURGE_INTERVAL", "1h")
+	viper.SetDefault("JWT_ALGORITHM", "HS256")
+	viper.SetDefault("JWT_KEYS_DIR", "keys")
+	viper.SetDefault("JWT_KEY_ROTATION_INTERVAL", "0s")
+	viper.SetDefault("JWT_KEY_RELOAD_INTERVAL", "1m")
+	viper.SetDefault("APP_URL", "http://localhost:1323")
+	viper.SetDefault("MAILER", "outbox")
+	viper.SetDefault("MAIL_FROM", "no-reply@localhost")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("EMAIL_VERIFICATION_TTL", "24h")
+	viper.SetDefault("VERIFICATION_RESEND_WAIT", "1m")
+	viper.SetDefault("PASSWORD_RESET_TTL", "1h")
+	viper.SetDefault("PASSWORD_RESET_URL", "")
+	viper.SetDefault("LOGIN_LOCKOUT_THRESHOLD", 10)
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", "15m")
+	viper.SetDefault("LOGIN_IP_LOCKOUT_THRESHOLD", 100)
+	viper.SetDefault("TOTP_ISSUER", "Wishlist")
+	viper.SetDefault("MFA_CHALLENGE_TTL", "5m")
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	viper.SetDefault("PASSWORD_MIN_CLASSES", 2)
+	viper.SetDefault("PASSWORD_HASHER", "bcrypt")
+	viper.SetDefault("BCRYPT_COST", 10)
+	viper.SetDefault("ARGON2_MEMORY", 65536)
+	viper.SetDefault("ARGON2_ITERATIONS", 3)
+	viper.SetDefault("ARGON2_PARALLELISM", 2)
+	viper.SetDefault("OIDC_PROVIDERS", "")
+	viper.SetDefault("OIDC_LOGIN_TTL", "10m")
+	viper.SetDefault("BOOTSTRAP_ADMIN_EMAIL", "")
+	viper.SetDefault("ACCOUNT_DELETION_GRACE", "720h")
+	viper.SetDefault("ACCOUNT_ERASURE_INTERVAL", "1h")
+
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatal(err)
 	}
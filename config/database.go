@@ -5,6 +5,7 @@ import (
 	"go-wishlist-api-2/entities"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"log"
 )
 
 var DB *gorm.DB
@@ -18,5 +19,119 @@ func InitDatabase() {
 		panic("failed to connect database")
 	}
 	DB = db
-	DB.AutoMigrate(&entities.Wishlist{}, &entities.User{})
+	verifyExistingUsers := DB.Migrator().HasTable(&entities.User{}) &&
+		!DB.Migrator().HasColumn(&entities.User{}, "EmailVerifiedAt")
+	if err := migrateOwnerlessWishlists(DB, ENV.BOOTSTRAP_ADMIN_EMAIL); err != nil {
+		log.Fatal(err)
+	}
+	err = DB.AutoMigrate(&entities.List{}, &entities.Tag{}, &entities.Wishlist{}, &entities.AchievementEvent{}, &entities.User{},
+		&entities.RefreshToken{}, &entities.RevokedToken{}, &entities.PasswordResetToken{},
+		&entities.TOTPCredential{}, &entities.RecoveryCode{}, &entities.PersonalAccessToken{},
+		&entities.ExternalIdentity{}, &entities.OIDCLoginState{}, &entities.Session{}, &entities.AccountDeletion{}, &entities.ListShare{},
+		&entities.GiftClaim{})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := migrateAchievedAt(DB); err != nil {
+		log.Fatal(err)
+	}
+	if err := migrateWishlistDefaults(DB); err != nil {
+		log.Fatal(err)
+	}
+	if verifyExistingUsers {
+		if err := migrateVerifiedUsers(DB); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if ENV.BOOTSTRAP_ADMIN_EMAIL != "" {
+		if err := promoteAdmin(DB, ENV.BOOTSTRAP_ADMIN_EMAIL); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// promoteAdmin gives the account with the given email the admin role. It does
+// nothing if the account does not exist yet; it is retried on every startup.
+func promoteAdmin(db *gorm.DB, email string) error {
+	result := db.Model(&entities.User{}).
+		Where("email = ? AND role <> ?", email, entities.RoleAdmin).
+		UpdateColumn("role", entities.RoleAdmin)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("promoted %s to admin", email)
+	}
+	return nil
+}
+
+// migrateVerifiedUsers treats accounts created before email verification
+// existed as verified. It only runs once, when the column is first added.
+func migrateVerifiedUsers(db *gorm.DB) error {
+	return db.Model(&entities.User{}).
+		Where("email_verified_at IS NULL").
+		UpdateColumn("email_verified_at", gorm.Expr("created_at")).Error
+}
+
+// migrateOwnerlessWishlists prepares wishlists created before they belonged to
+// a user for the owner foreign key. Wishlists without an existing owner are
+// given to the account with ownerEmail, or deleted when there is no such
+// account. It runs before AutoMigrate, which cannot add the constraint while
+// such rows exist.
+func migrateOwnerlessWishlists(db *gorm.DB, ownerEmail string) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&entities.Wishlist{}) || !migrator.HasTable(&entities.User{}) {
+		return nil
+	}
+	if !migrator.HasColumn(&entities.Wishlist{}, "UserID") {
+		if err := migrator.AddColumn(&entities.Wishlist{}, "UserID"); err != nil {
+			return err
+		}
+	}
+
+	ownerless := db.Unscoped().Model(&entities.Wishlist{}).
+		Where("user_id IS NULL OR user_id NOT IN (?)", db.Model(&entities.User{}).Select("id"))
+	var owner entities.User
+	if ownerEmail != "" {
+		err := db.Select("id").Where("email = ?", ownerEmail).Limit(1).Find(&owner).Error
+		if err != nil {
+			return err
+		}
+	}
+	var result *gorm.DB
+	if owner.Id != 0 {
+		result = ownerless.UpdateColumn("user_id", owner.Id)
+	} else {
+		result = ownerless.Delete(&entities.Wishlist{})
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("migrated %d wishlists without an owner", result.RowsAffected)
+	}
+	return nil
+}
+
+// migrateWishlistDefaults gives wishlists created before Quantity and Priority
+// had column defaults a quantity of one and medium priority.
+func migrateWishlistDefaults(db *gorm.DB) error {
+	err := db.Unscoped().Model(&entities.Wishlist{}).
+		Where("quantity = ?", 0).
+		UpdateColumn("quantity", 1).Error
+	if err != nil {
+		return err
+	}
+	return db.Unscoped().Model(&entities.Wishlist{}).
+		Where("priority = ?", "").
+		UpdateColumn("priority", entities.PriorityMedium).Error
+}
+
+// migrateAchievedAt gives wishlists achieved before AchievedAt existed their
+// last update time as the best known achievement time.
+func migrateAchievedAt(db *gorm.DB) error {
+	return db.Unscoped().Model(&entities.Wishlist{}).
+		Where("is_achieved = ? AND achieved_at IS NULL", true).
+		UpdateColumn("achieved_at", gorm.Expr("updated_at")).Error
 }
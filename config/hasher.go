@@ -0,0 +1,29 @@
+package config
+
+import (
+	"go-wishlist-api-2/helper"
+	"log"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func InitPasswordHasher() {
+	switch ENV.PASSWORD_HASHER {
+	case "bcrypt":
+		if ENV.BCRYPT_COST < bcrypt.MinCost || ENV.BCRYPT_COST > bcrypt.MaxCost {
+			log.Fatalf("BCRYPT_COST must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		helper.UsePasswordHasher(helper.BcryptHasher{Cost: ENV.BCRYPT_COST})
+	case "argon2id":
+		hasher := helper.DefaultArgon2idHasher
+		hasher.Memory = ENV.ARGON2_MEMORY
+		hasher.Iterations = ENV.ARGON2_ITERATIONS
+		hasher.Parallelism = ENV.ARGON2_PARALLELISM
+		if hasher.Memory == 0 || hasher.Iterations == 0 || hasher.Parallelism == 0 {
+			log.Fatal("ARGON2_MEMORY, ARGON2_ITERATIONS and ARGON2_PARALLELISM must be positive")
+		}
+		helper.UsePasswordHasher(hasher)
+	default:
+		log.Fatalf("unknown PASSWORD_HASHER %q", ENV.PASSWORD_HASHER)
+	}
+}
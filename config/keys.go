@@ -0,0 +1,23 @@
+package config
+
+import (
+	"go-wishlist-api-2/helper"
+	"log"
+)
+
+var SigningKeys *helper.KeyManager
+
+// InitSigningKeys loads the JWT signing keys. A rotated-out key is kept until
+// every token signed with it has expired, including tokens signed by other
+// instances that have not reloaded the keys yet.
+func InitSigningKeys() {
+	SigningKeys = &helper.KeyManager{
+		Algorithm:        ENV.JWT_ALGORITHM,
+		Dir:              ENV.JWT_KEYS_DIR,
+		RotationInterval: ENV.JWT_KEY_ROTATION_INTERVAL,
+		MaxKeyAge:        ENV.ACCESS_TOKEN_TTL + ENV.JWT_KEY_RELOAD_INTERVAL,
+	}
+	if err := SigningKeys.Refresh(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"go-wishlist-api-2/mailer"
+	"log"
+)
+
+var Mailer mailer.Mailer
+
+func InitMailer() {
+	switch ENV.MAILER {
+	case "smtp":
+		Mailer = mailer.NewSMTPMailer(ENV.SMTP_HOST, ENV.SMTP_PORT, ENV.SMTP_USERNAME, ENV.SMTP_PASSWORD, ENV.MAIL_FROM)
+	case "outbox":
+		Mailer = mailer.NewOutbox(ENV.MAIL_OUTBOX_DIR)
+	default:
+		log.Fatalf("unknown MAILER %q", ENV.MAILER)
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"go-wishlist-api-2/oidc"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+var OIDCProviders map[string]*oidc.Provider
+
+var providerName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+func InitOIDCProviders() {
+	OIDCProviders = map[string]*oidc.Provider{}
+	for _, name := range strings.Split(ENV.OIDC_PROVIDERS, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !providerName.MatchString(name) {
+			log.Fatalf("invalid OIDC provider name %q", name)
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		config := oidc.Config{
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			RedirectURL:  strings.TrimRight(ENV.APP_URL, "/") + "/auth/oidc/" + name + "/callback",
+			Scopes:       strings.Fields(viper.GetString(prefix + "SCOPES")),
+		}
+		if config.Issuer == "" || config.ClientID == "" {
+			log.Fatalf("%sISSUER and %sCLIENT_ID must be set", prefix, prefix)
+		}
+		OIDCProviders[name] = oidc.NewProvider(name, config, nil)
+	}
+}
@@ -0,0 +1,11 @@
+package achievement
+
+import "time"
+
+type AchievementEvent struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WishlistID uint      `json:"wishlist_id" gorm:"not null;index"`
+	UserID     int       `json:"user_id" gorm:"not null"`
+	Achieved   bool      `json:"achieved"`
+	CreatedAt  time.Time `json:"created_at"`
+}
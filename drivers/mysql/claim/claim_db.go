@@ -0,0 +1,14 @@
+package claim
+
+import "time"
+
+type GiftClaim struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WishlistID uint      `json:"wishlist_id" gorm:"not null;index"`
+	Name       string    `json:"name" gorm:"type:varchar(100);not null"`
+	Status     string    `json:"status" gorm:"type:varchar(20);not null"`
+	Quantity   int       `json:"quantity" gorm:"not null;default:1"`
+	TokenHash  string    `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
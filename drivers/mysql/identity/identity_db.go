@@ -0,0 +1,26 @@
+package identity
+
+import "time"
+
+type ExternalIdentity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    int       `json:"user_id" gorm:"not null;index"`
+	Provider  string    `json:"provider" gorm:"type:varchar(50);not null;uniqueIndex:idx_external_identities_provider_subject"`
+	Subject   string    `json:"subject" gorm:"type:varchar(255);not null;uniqueIndex:idx_external_identities_provider_subject"`
+	Email     string    `json:"email" gorm:"type:varchar(100)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type OIDCLoginState struct {
+	StateHash    string    `json:"-" gorm:"primaryKey;type:char(64)"`
+	Provider     string    `json:"provider" gorm:"type:varchar(50);not null"`
+	Nonce        string    `json:"-" gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `json:"-" gorm:"type:varchar(128);not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
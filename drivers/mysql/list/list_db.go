@@ -0,0 +1,29 @@
+package list
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type List struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	UserID    int    `json:"user_id" gorm:"not null;index"`
+	Name      string `json:"name" gorm:"type:varchar(100);not null"`
+	IsDefault bool   `json:"is_default"`
+	// DefaultOwner allows only one default list per user.
+	DefaultOwner *int           `json:"-" gorm:"->;type:int GENERATED ALWAYS AS (IF(is_default, user_id, NULL)) STORED;uniqueIndex"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+type ListShare struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	ListID       uint       `json:"list_id" gorm:"not null;uniqueIndex"`
+	TokenHash    string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	PasswordHash string     `json:"-" gorm:"type:varchar(255)"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	RevealClaims bool       `json:"reveal_claims" gorm:"not null;default:false"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
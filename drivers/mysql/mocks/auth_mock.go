@@ -3,6 +3,7 @@ package mocks
 import (
 	"github.com/stretchr/testify/mock"
 	"go-wishlist-api-2/entities"
+	"time"
 )
 
 type MockAuthRepository struct {
@@ -17,6 +18,14 @@ func (m *MockAuthRepository) FindByEmail(email string) (*entities.User, error) {
 	return args.Get(0).(*entities.User), nil
 }
 
+func (m *MockAuthRepository) FindByID(id int) (*entities.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), nil
+}
+
 func (m *MockAuthRepository) CreateUser(user *entities.User) (*entities.User, error) {
 	args := m.Called(user)
 	if args.Get(0) == nil {
@@ -24,3 +33,124 @@ func (m *MockAuthRepository) CreateUser(user *entities.User) (*entities.User, er
 	}
 	return args.Get(0).(*entities.User), nil
 }
+
+func (m *MockAuthRepository) UpdateUser(user *entities.User) (*entities.User, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), nil
+}
+
+func (m *MockAuthRepository) CreatePasswordResetToken(token *entities.PasswordResetToken) (*entities.PasswordResetToken, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PasswordResetToken), nil
+}
+
+func (m *MockAuthRepository) FindPasswordResetTokenByHash(hash string) (*entities.PasswordResetToken, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PasswordResetToken), nil
+}
+
+func (m *MockAuthRepository) CountPasswordResetTokensSince(userID int, since time.Time) (int64, error) {
+	args := m.Called(userID, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthRepository) ResetPassword(token *entities.PasswordResetToken, user *entities.User) error {
+	args := m.Called(token, user)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) UpdatePassword(user *entities.User, keepAccessJTI string, revokePersonalAccessTokens bool) error {
+	args := m.Called(user, keepAccessJTI, revokePersonalAccessTokens)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) UpdatePasswordHash(userID int, oldHash, newHash string) error {
+	args := m.Called(userID, oldHash, newHash)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) UpdateEmail(userID int, email string) error {
+	args := m.Called(userID, email)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) FindTOTPCredential(userID int) (*entities.TOTPCredential, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TOTPCredential), nil
+}
+
+func (m *MockAuthRepository) SaveTOTPCredential(credential *entities.TOTPCredential) error {
+	args := m.Called(credential)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ConfirmTOTPCredential(credential *entities.TOTPCredential, codes []*entities.RecoveryCode) error {
+	args := m.Called(credential, codes)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) DeleteTOTPCredential(userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) UseTOTPStep(userID int, step int64) error {
+	args := m.Called(userID, step)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) UseRecoveryCode(userID int, hash string) error {
+	args := m.Called(userID, hash)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) FindExternalIdentity(provider, subject string) (*entities.ExternalIdentity, error) {
+	args := m.Called(provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ExternalIdentity), nil
+}
+
+func (m *MockAuthRepository) CreateExternalIdentity(identity *entities.ExternalIdentity) error {
+	args := m.Called(identity)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) CreateUserWithIdentity(user *entities.User, identity *entities.ExternalIdentity) (*entities.User, error) {
+	args := m.Called(user, identity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), nil
+}
+
+func (m *MockAuthRepository) CreateOIDCLoginState(state *entities.OIDCLoginState) error {
+	args := m.Called(state)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ConsumeOIDCLoginState(stateHash string) (*entities.OIDCLoginState, error) {
+	args := m.Called(stateHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.OIDCLoginState), nil
+}
+
+func (m *MockAuthRepository) CancelAccountDeletion(userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/entities"
+)
+
+type MockClaimRepository struct {
+	mock.Mock
+}
+
+func (m *MockClaimRepository) FindByID(id uint) (*entities.GiftClaim, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.GiftClaim), nil
+}
+
+func (m *MockClaimRepository) FindByWishlistIDs(wishlistIDs []uint) ([]*entities.GiftClaim, error) {
+	args := m.Called(wishlistIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.GiftClaim), nil
+}
+
+func (m *MockClaimRepository) CreateClaim(claim *entities.GiftClaim) (*entities.GiftClaim, error) {
+	args := m.Called(claim)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.GiftClaim), nil
+}
+
+func (m *MockClaimRepository) UpdateStatus(id uint, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockClaimRepository) DeleteClaim(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
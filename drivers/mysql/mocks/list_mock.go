@@ -0,0 +1,94 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/entities"
+)
+
+type MockListRepository struct {
+	mock.Mock
+}
+
+func (m *MockListRepository) GetAll(userID int) ([]*entities.List, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.List), nil
+}
+
+func (m *MockListRepository) FindByID(id uint) (*entities.List, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.List), nil
+}
+
+func (m *MockListRepository) FindOrCreateDefault(userID int) (*entities.List, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.List), nil
+}
+
+func (m *MockListRepository) CreateList(list *entities.List) (*entities.List, error) {
+	args := m.Called(list)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.List), nil
+}
+
+func (m *MockListRepository) UpdateList(list *entities.List) (*entities.List, error) {
+	args := m.Called(list)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.List), nil
+}
+
+func (m *MockListRepository) DeleteList(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockListRepository) FindShare(listID uint) (*entities.ListShare, error) {
+	args := m.Called(listID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ListShare), nil
+}
+
+func (m *MockListRepository) FindShareByTokenHash(hash string) (*entities.ListShare, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ListShare), nil
+}
+
+func (m *MockListRepository) SaveShare(share *entities.ListShare) (*entities.ListShare, error) {
+	args := m.Called(share)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ListShare), nil
+}
+
+func (m *MockListRepository) UpdateShareToken(listID uint, hash string) error {
+	args := m.Called(listID, hash)
+	return args.Error(0)
+}
+
+func (m *MockListRepository) UpdateShareRevealClaims(listID uint, reveal bool) error {
+	args := m.Called(listID, reveal)
+	return args.Error(0)
+}
+
+func (m *MockListRepository) DeleteShare(listID uint) error {
+	args := m.Called(listID)
+	return args.Error(0)
+}
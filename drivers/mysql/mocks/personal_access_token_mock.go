@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/entities"
+	"time"
+)
+
+type MockPersonalAccessTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockPersonalAccessTokenRepository) CreateToken(token *entities.PersonalAccessToken) (*entities.PersonalAccessToken, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PersonalAccessToken), nil
+}
+
+func (m *MockPersonalAccessTokenRepository) FindByHash(hash string) (*entities.PersonalAccessToken, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PersonalAccessToken), nil
+}
+
+func (m *MockPersonalAccessTokenRepository) FindByUser(userID int) ([]*entities.PersonalAccessToken, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.PersonalAccessToken), nil
+}
+
+func (m *MockPersonalAccessTokenRepository) CountByUser(userID int) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPersonalAccessTokenRepository) DeleteToken(userID int, id uint) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
+
+func (m *MockPersonalAccessTokenRepository) TouchToken(id uint, usedAt time.Time) error {
+	args := m.Called(id, usedAt)
+	return args.Error(0)
+}
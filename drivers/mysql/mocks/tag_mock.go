@@ -0,0 +1,55 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/entities"
+)
+
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) Search(userID int, prefix string, limit int) ([]*entities.Tag, error) {
+	args := m.Called(userID, prefix, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Tag), nil
+}
+
+func (m *MockTagRepository) FindByID(id uint) (*entities.Tag, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Tag), nil
+}
+
+func (m *MockTagRepository) FindByName(userID int, name string) (*entities.Tag, error) {
+	args := m.Called(userID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Tag), nil
+}
+
+func (m *MockTagRepository) FindOrCreate(userID int, names []string) ([]entities.Tag, error) {
+	args := m.Called(userID, names)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Tag), nil
+}
+
+func (m *MockTagRepository) UpdateTag(tag *entities.Tag) (*entities.Tag, error) {
+	args := m.Called(tag)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Tag), nil
+}
+
+func (m *MockTagRepository) MergeTags(sourceID, targetID uint) error {
+	args := m.Called(sourceID, targetID)
+	return args.Error(0)
+}
@@ -0,0 +1,95 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/entities"
+	"time"
+)
+
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) CreateRefreshToken(token *entities.RefreshToken) (*entities.RefreshToken, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.RefreshToken), nil
+}
+
+func (m *MockTokenRepository) FindRefreshTokenByHash(hash string) (*entities.RefreshToken, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.RefreshToken), nil
+}
+
+func (m *MockTokenRepository) RotateRefreshToken(old *entities.RefreshToken, next *entities.RefreshToken) (*entities.RefreshToken, error) {
+	args := m.Called(old, next)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.RefreshToken), nil
+}
+
+func (m *MockTokenRepository) RevokeRefreshToken(token *entities.RefreshToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeAllForUser(userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) DenyAccessToken(jti string, expiresAt time.Time) error {
+	args := m.Called(jti, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) IsAccessTokenDenied(jti string) (bool, error) {
+	args := m.Called(jti)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTokenRepository) PurgeExpiredTokens(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// CreateSession gives session the id of the *entities.Session it is set up
+// to return and stores the issued token through CreateRefreshToken, so that
+// tests can set expectations on both.
+func (m *MockTokenRepository) CreateSession(session *entities.Session, issue func(session *entities.Session) (*entities.RefreshToken, error)) error {
+	args := m.Called(session)
+	if args.Get(0) == nil {
+		return args.Error(1)
+	}
+	session.ID = args.Get(0).(*entities.Session).ID
+	token, err := issue(session)
+	if err != nil {
+		return err
+	}
+	_, err = m.CreateRefreshToken(token)
+	return err
+}
+
+func (m *MockTokenRepository) FindActiveSessions(userID int) ([]*entities.Session, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Session), nil
+}
+
+func (m *MockTokenRepository) RevokeSession(userID int, id uint) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) IsSessionRevoked(id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
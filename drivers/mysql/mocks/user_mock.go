@@ -0,0 +1,77 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"time"
+)
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) FindUsers(filter *dto.UserFilter) ([]*entities.User, int64, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.User), args.Get(1).(int64), nil
+}
+
+func (m *MockUserRepository) FindByID(id int) (*entities.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), nil
+}
+
+func (m *MockUserRepository) SetDisabled(id int, disabledAt *time.Time) error {
+	args := m.Called(id, disabledAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateRole(id int, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateProfile(user *entities.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindAccountData(userID int) (*entities.AccountData, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.AccountData), nil
+}
+
+func (m *MockUserRepository) ScheduleDeletion(deletion *entities.AccountDeletion) error {
+	args := m.Called(deletion)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindDueDeletions(now time.Time) ([]*entities.AccountDeletion, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.AccountDeletion), nil
+}
+
+func (m *MockUserRepository) EraseAccount(deletion *entities.AccountDeletion) error {
+	args := m.Called(deletion)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindDeletions(filter *dto.DeletionFilter) ([]*entities.AccountDeletion, int64, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.AccountDeletion), args.Get(1).(int64), nil
+}
@@ -2,19 +2,29 @@ package mocks
 
 import (
 	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/dto"
 	"go-wishlist-api-2/entities"
+	"time"
 )
 
 type MockWishlistRepository struct {
 	mock.Mock
 }
 
-func (m *MockWishlistRepository) GetAll() ([]*entities.Wishlist, error) {
-	args := m.Called()
+func (m *MockWishlistRepository) GetAll(filter *dto.WishlistFilter) ([]*entities.Wishlist, int64, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.Wishlist), args.Get(1).(int64), nil
+}
+
+func (m *MockWishlistRepository) FindByID(id uint) (*entities.Wishlist, error) {
+	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*entities.Wishlist), nil
+	return args.Get(0).(*entities.Wishlist), nil
 }
 
 func (m *MockWishlistRepository) CreateWishlist(wishlist *entities.Wishlist) (*entities.Wishlist, error) {
@@ -24,3 +34,55 @@ func (m *MockWishlistRepository) CreateWishlist(wishlist *entities.Wishlist) (*e
 	}
 	return args.Get(0).(*entities.Wishlist), nil
 }
+
+func (m *MockWishlistRepository) UpdateWishlist(wishlist *entities.Wishlist) (*entities.Wishlist, error) {
+	args := m.Called(wishlist)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Wishlist), nil
+}
+
+func (m *MockWishlistRepository) DeleteWishlist(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) GetTrash(userID int) ([]*entities.Wishlist, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Wishlist), nil
+}
+
+func (m *MockWishlistRepository) FindTrashedByID(id uint) (*entities.Wishlist, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Wishlist), nil
+}
+
+func (m *MockWishlistRepository) RestoreWishlist(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) PurgeWishlist(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) PurgeDeletedBefore(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetAchievementHistory(wishlistID uint) ([]*entities.AchievementEvent, error) {
+	args := m.Called(wishlistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.AchievementEvent), nil
+}
@@ -3,9 +3,21 @@ package user
 import "time"
 
 type User struct {
-	Id        int       `gorm:"primaryKey;not null" json:"id"`
-	Email     string    `gorm:"type:varchar(100);not null"json:"email"`
-	Password  string    `gorm:"type:varchar(255);not null"json:"password"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Id                  int        `gorm:"primaryKey;not null" json:"id"`
+	Email               string     `gorm:"type:varchar(100);not null" json:"email"`
+	Password            string     `gorm:"type:varchar(255);not null" json:"password"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	VerificationSentAt  *time.Time `json:"verification_sent_at"`
+	PendingEmail        *string    `gorm:"type:varchar(100)" json:"pending_email"`
+	Role                string     `gorm:"type:varchar(20);not null;default:user" json:"role"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	DisplayName         string     `gorm:"type:varchar(100)" json:"display_name"`
+	AvatarURL           string     `gorm:"type:varchar(2048)" json:"avatar_url"`
+	Birthday            *time.Time `gorm:"type:date" json:"birthday"`
+	Locale              string     `gorm:"type:varchar(35)" json:"locale"`
+	Timezone            string     `gorm:"type:varchar(64)" json:"timezone"`
+	DefaultCurrency     string     `gorm:"type:char(3)" json:"default_currency"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
@@ -6,10 +6,20 @@ import (
 )
 
 type Wishlist struct {
-	ID         uint           `json:"id" gorm:"primaryKey"`
-	Title      string         `json:"title"`
-	IsAchieved bool           `json:"is_achieved"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserID      int            `json:"user_id" gorm:"not null;index"`
+	ListID      *uint          `json:"list_id" gorm:"index"`
+	Title       string         `json:"title"`
+	IsAchieved  bool           `json:"is_achieved"`
+	AchievedAt  *time.Time     `json:"achieved_at"`
+	PriceAmount *int64         `json:"price_amount"`
+	Currency    string         `json:"currency" gorm:"type:char(3)"`
+	URL         string         `json:"url" gorm:"type:varchar(2048)"`
+	Priority    string         `json:"priority" gorm:"type:varchar(10);not null;default:medium"`
+	Quantity    int            `json:"quantity" gorm:"not null;default:1"`
+	Notes       string         `json:"notes" gorm:"type:text"`
+	TargetDate  *time.Time     `json:"target_date" gorm:"type:date"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
@@ -0,0 +1,77 @@
+package dto
+
+import "time"
+
+// AccountExport is the account.json file of a data export. It holds
+// everything stored about the user except credentials: passwords, token
+// hashes and two-factor secrets are never exported.
+type AccountExport struct {
+	ExportedAt       time.Time              `json:"exported_at"`
+	Profile          *UserResponse          `json:"profile"`
+	Lists            []ExportList           `json:"lists"`
+	Wishlists        []ExportWishlist       `json:"wishlists"`
+	Tags             []ExportTag            `json:"tags"`
+	Activity         []ExportActivity       `json:"activity"`
+	AccessTokens     []ExportAccessToken    `json:"access_tokens"`
+	LinkedIdentities []ExportLinkedIdentity `json:"linked_identities"`
+}
+
+type ExportList struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	IsDefault bool       `json:"is_default"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// ExportWishlist is a wishlist in an export. DeletedAt is set for wishlists
+// in the trash.
+type ExportWishlist struct {
+	ID         uint       `json:"id"`
+	ListID     *uint      `json:"list_id"`
+	Title      string     `json:"title"`
+	IsAchieved bool       `json:"is_achieved"`
+	AchievedAt *time.Time `json:"achieved_at"`
+	Price      *int64     `json:"price"`
+	Currency   string     `json:"currency"`
+	URL        string     `json:"url"`
+	Priority   string     `json:"priority"`
+	Quantity   int        `json:"quantity"`
+	Notes      string     `json:"notes"`
+	TargetDate *time.Time `json:"target_date"`
+	Tags       []string   `json:"tags"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+}
+
+type ExportTag struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportActivity is one event in the user's history: a sign-in, with the
+// device in Detail, or a wishlist being marked achieved or not achieved, with
+// its title in Detail.
+type ExportActivity struct {
+	At         time.Time `json:"at"`
+	Type       string    `json:"type"`
+	WishlistID *uint     `json:"wishlist_id,omitempty"`
+	Detail     string    `json:"detail"`
+}
+
+type ExportAccessToken struct {
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+type ExportLinkedIdentity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
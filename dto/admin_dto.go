@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// UserFilter selects the users listed by GET /admin/users. Email matches
+// any part of the address.
+type UserFilter struct {
+	Email  string
+	Role   string
+	Limit  int
+	Offset int
+}
+
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+// AdminUserResponse describes an account to administrators. It deliberately
+// leaves out the password hash and other credentials.
+type AdminUserResponse struct {
+	ID              int        `json:"id"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	DisabledAt      *time.Time `json:"disabled_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// DeletionFilter selects the account deletions listed by
+// GET /admin/deletions. A zero UserID lists deletions of every account.
+type DeletionFilter struct {
+	UserID int
+	Limit  int
+	Offset int
+}
+
+// AccountDeletionResponse describes a requested account deletion. Status is
+// "scheduled", "cancelled" or "erased"; Email is empty once the account was
+// erased.
+type AccountDeletionResponse struct {
+	ID           uint       `json:"id"`
+	UserID       int        `json:"user_id"`
+	Email        string     `json:"email,omitempty"`
+	Status       string     `json:"status"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	CancelledAt  *time.Time `json:"cancelled_at"`
+	ErasedAt     *time.Time `json:"erased_at"`
+}
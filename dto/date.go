@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var errInvalidDate = errors.New("dates must be YYYY-MM-DD, e.g. 2024-12-25")
+
+// Date is a calendar date in JSON requests. It is written as YYYY-MM-DD and
+// also accepts a full RFC 3339 timestamp, whose time of day is kept.
+type Date struct {
+	time.Time
+}
+
+// TimePtr returns the date as a *time.Time, or nil if d is nil.
+func (d *Date) TimePtr() *time.Time {
+	if d == nil {
+		return nil
+	}
+	t := d.Time
+	return &t
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return errInvalidDate
+	}
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		d.Time = t
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return errInvalidDate
+	}
+	d.Time = t
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(time.DateOnly))
+}
@@ -0,0 +1,5 @@
+package dto
+
+type ListRequest struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,53 @@
+package dto
+
+import "time"
+
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// WishlistQuery holds the raw query parameters accepted by GET /wishlists.
+type WishlistQuery struct {
+	ListID      *uint
+	Limit       int
+	Offset      int
+	Cursor      string
+	Sort        string
+	IsAchieved  *bool
+	Title       string
+	Tags        []string
+	TagMode     string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+}
+
+// WishlistFilter is the validated form of WishlistQuery handed to the
+// repository. After holds the sort key values of the last row of the
+// previous page when paginating by cursor. With MatchAllTags set only
+// wishlists carrying every tag in Tags match, otherwise any of them is enough.
+type WishlistFilter struct {
+	UserID       int
+	ListID       *uint
+	IsAchieved   *bool
+	Title        string
+	Tags         []string
+	MatchAllTags bool
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	UpdatedFrom  *time.Time
+	UpdatedTo    *time.Time
+	Sort         []SortField
+	After        []any
+	Limit        int
+	Offset       int
+}
+
+type PageMeta struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -5,4 +5,12 @@ type ResponseParam struct {
 	StatusCode int
 	Message    string
 	Data       any
+	Meta       any
+	Errors     []FieldError
+}
+
+// FieldError describes why the value of one request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
@@ -0,0 +1,110 @@
+package dto
+
+import "time"
+
+// ListShareRequest is the body of POST /lists/:id/share. An empty Password
+// shares the list without one and a nil ExpiresAt never expires. Claims are
+// hidden from the owner unless RevealClaims is set.
+type ListShareRequest struct {
+	Password     string     `json:"password"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	RevealClaims bool       `json:"reveal_claims"`
+}
+
+// ShareSettingsRequest is the body of PATCH /lists/:id/share.
+type ShareSettingsRequest struct {
+	RevealClaims *bool `json:"reveal_claims"`
+}
+
+// ListShareResponse describes the share link of a list. Token and URL are
+// only filled right after the link was created or regenerated; afterwards
+// only the token's hash is known.
+type ListShareResponse struct {
+	ListID       uint       `json:"list_id"`
+	Token        string     `json:"token,omitempty"`
+	URL          string     `json:"url,omitempty"`
+	HasPassword  bool       `json:"has_password"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	RevealClaims bool       `json:"reveal_claims"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ShareAccess is how a visitor opens a share link. ViewerID is the signed in
+// visitor, zero for anonymous ones. Wrong passwords are throttled per IP.
+type ShareAccess struct {
+	Password string
+	ViewerID int
+	IP       string
+}
+
+// SharedListQuery holds the access and paging of GET /shared/:token.
+type SharedListQuery struct {
+	ShareAccess
+	Limit  int
+	Offset int
+}
+
+// SharedListResponse is what visitors of a share link see. It leaves out
+// everything identifying the owner except their display name. ClaimsHidden
+// is set when the owner looks at their own list in surprise mode; the items
+// then carry no claims. Anonymous visitors only get the Claimed counts unless
+// the owner revealed the claims.
+type SharedListResponse struct {
+	Name         string                    `json:"name"`
+	OwnerName    string                    `json:"owner_name"`
+	ClaimsHidden bool                      `json:"claims_hidden"`
+	Items        []*SharedWishlistResponse `json:"items"`
+}
+
+type SharedWishlistResponse struct {
+	ID         uint       `json:"id"`
+	Title      string     `json:"title"`
+	IsAchieved bool       `json:"is_achieved"`
+	Price      *int64     `json:"price"`
+	Currency   string     `json:"currency"`
+	URL        string     `json:"url"`
+	Priority   string     `json:"priority"`
+	Quantity   int        `json:"quantity"`
+	Notes      string     `json:"notes"`
+	TargetDate *time.Time `json:"target_date"`
+	Tags       []string   `json:"tags"`
+	// Claimed is the number of units claimed so far.
+	Claimed *int                   `json:"claimed,omitempty"`
+	Claims  []*SharedClaimResponse `json:"claims,omitempty"`
+}
+
+type SharedClaimResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Quantity  int       `json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ClaimRequest is the body of POST /shared/:token/items/:id/claims. Status
+// defaults to reserved and Quantity to 1.
+type ClaimRequest struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Quantity int    `json:"quantity"`
+}
+
+// ClaimUpdateRequest is the body of PATCH /shared/:token/claims/:id.
+type ClaimUpdateRequest struct {
+	Status string `json:"status"`
+}
+
+// ClaimResponse describes a claim to the visitor who made it. Token is only
+// filled right after the claim was made; it is needed to change or release
+// the claim later.
+type ClaimResponse struct {
+	ID         uint      `json:"id"`
+	WishlistID uint      `json:"wishlist_id"`
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	Quantity   int       `json:"quantity"`
+	Token      string    `json:"token,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
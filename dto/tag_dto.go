@@ -0,0 +1,9 @@
+package dto
+
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+type TagMergeRequest struct {
+	TargetID uint `json:"target_id"`
+}
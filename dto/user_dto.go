@@ -1,14 +1,127 @@
 package dto
 
+import "time"
+
 type UserRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+// UserResponse is how an account is shown to its owner. It never includes the
+// password hash. Birthday is formatted as YYYY-MM-DD.
 type UserResponse struct {
-	Data any `json:"data"`
+	ID              int        `json:"id"`
+	Email           string     `json:"email"`
+	PendingEmail    *string    `json:"pending_email,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Role            string     `json:"role"`
+	DisplayName     string     `json:"display_name"`
+	AvatarURL       string     `json:"avatar_url"`
+	Birthday        *string    `json:"birthday"`
+	Locale          string     `json:"locale"`
+	Timezone        string     `json:"timezone"`
+	DefaultCurrency string     `json:"default_currency"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// ProfileRequest is the body of PATCH /me. Nil fields are left untouched and
+// an empty string clears a field. Birthday is a YYYY-MM-DD date.
+type ProfileRequest struct {
+	DisplayName     *string `json:"display_name"`
+	AvatarURL       *string `json:"avatar_url"`
+	Birthday        *string `json:"birthday"`
+	Locale          *string `json:"locale"`
+	Timezone        *string `json:"timezone"`
+	DefaultCurrency *string `json:"default_currency"`
 }
 
+// LoginResponse carries either a token pair or, for accounts with two-factor
+// authentication, an MFA challenge token to exchange at /login/mfa. ExpiresAt
+// is the expiry of whichever token is returned.
 type LoginResponse struct {
+	Token        string    `json:"token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	MFARequired  bool      `json:"mfa_required,omitempty"`
+	MFAToken     string    `json:"mfa_token,omitempty"`
+}
+
+// ClientInfo describes the device a login comes from. It is recorded with the
+// session the login starts.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+type PasswordResetRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ChangePasswordRequest changes the password of the signed in user.
+// Personal access tokens keep working unless RevokePersonalAccessTokens is
+// set; resetting a forgotten password always deletes them.
+type ChangePasswordRequest struct {
+	CurrentPassword            string `json:"current_password"`
+	NewPassword                string `json:"new_password"`
+	RevokePersonalAccessTokens bool   `json:"revoke_personal_access_tokens"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+type CodeRequest struct {
+	Code string `json:"code"`
+}
+
+type PasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// TokenRequest carries a token the user received by email.
+type TokenRequest struct {
 	Token string `json:"token"`
 }
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// OIDCLoginRedirect is where to send the user to sign in with an OpenID
+// Connect provider. State must be bound to the user's browser and checked
+// when the provider redirects back.
+type OIDCLoginRedirect struct {
+	URL   string
+	State string
+}
+
+// SessionResponse describes a signed-in device. Current marks the session of
+// the access token used for the request.
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
@@ -1,6 +1,35 @@
 package dto
 
+// WishlistRequest is the body of POST and PUT /wishlists. Price is expressed
+// in minor units of Currency, e.g. 1999 with "USD" for $19.99. A nil Tags
+// keeps the current tags on update while an empty list removes them all.
+// TargetDate is a YYYY-MM-DD date.
 type WishlistRequest struct {
-	Title      string `json:"title"`
-	IsAchieved bool   `json:"is_achieved"`
+	ListID     *uint    `json:"list_id"`
+	Title      string   `json:"title"`
+	IsAchieved bool     `json:"is_achieved"`
+	Price      *int64   `json:"price"`
+	Currency   string   `json:"currency"`
+	URL        string   `json:"url"`
+	Priority   string   `json:"priority"`
+	Quantity   int      `json:"quantity"`
+	Notes      string   `json:"notes"`
+	TargetDate *Date    `json:"target_date"`
+	Tags       []string `json:"tags"`
+}
+
+// WishlistPatchRequest only carries the fields the client wants to change;
+// nil fields are left untouched.
+type WishlistPatchRequest struct {
+	ListID     *uint    `json:"list_id"`
+	Title      *string  `json:"title"`
+	IsAchieved *bool    `json:"is_achieved"`
+	Price      *int64   `json:"price"`
+	Currency   *string  `json:"currency"`
+	URL        *string  `json:"url"`
+	Priority   *string  `json:"priority"`
+	Quantity   *int     `json:"quantity"`
+	Notes      *string  `json:"notes"`
+	TargetDate *Date    `json:"target_date"`
+	Tags       []string `json:"tags"`
 }
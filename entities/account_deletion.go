@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+// AccountDeletion is the audit record of a user asking for their account to
+// be erased. The account is erased once ScheduledFor has passed unless the
+// user signs in again before that, which cancels the deletion. The record
+// outlives the account, so it has no foreign key to users and Email is
+// cleared when the account is erased.
+type AccountDeletion struct {
+	ID           uint
+	UserID       int       `gorm:"index"`
+	Email        string    `gorm:"type:varchar(100)"`
+	ScheduledFor time.Time `gorm:"index"`
+	CancelledAt  *time.Time
+	ErasedAt     *time.Time
+	CreatedAt    time.Time
+}
+
+// AccountData is everything stored about a user, gathered for a data export.
+// It is not a table.
+type AccountData struct {
+	User                 *User
+	Lists                []*List
+	Wishlists            []*Wishlist
+	Tags                 []*Tag
+	AchievementEvents    []*AchievementEvent
+	Sessions             []*Session
+	PersonalAccessTokens []*PersonalAccessToken
+	ExternalIdentities   []*ExternalIdentity
+}
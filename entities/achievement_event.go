@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+// AchievementEvent records a single change of a wishlist's IsAchieved flag.
+// Events are only ever appended, never updated.
+type AchievementEvent struct {
+	ID         uint
+	WishlistID uint `gorm:"index"`
+	UserID     int
+	Achieved   bool
+	CreatedAt  time.Time
+}
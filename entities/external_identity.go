@@ -0,0 +1,34 @@
+package entities
+
+import "time"
+
+// ExternalIdentity links an account at an OpenID Connect provider to a user.
+// Subject is the provider's stable id of the account; the email address may
+// change at the provider and is only kept for reference.
+type ExternalIdentity struct {
+	ID        uint
+	UserID    int    `gorm:"index"`
+	User      *User  `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Provider  string `gorm:"type:varchar(50);uniqueIndex:idx_external_identities_provider_subject"`
+	Subject   string `gorm:"type:varchar(255);uniqueIndex:idx_external_identities_provider_subject"`
+	Email     string `gorm:"type:varchar(100)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OIDCLoginState is a sign-in with an OpenID Connect provider that was started
+// but not completed yet. It is found by the hash of the state parameter and
+// can be completed only once.
+type OIDCLoginState struct {
+	StateHash    string    `gorm:"primaryKey;type:char(64)"`
+	Provider     string    `gorm:"type:varchar(50)"`
+	Nonce        string    `gorm:"type:varchar(64)"`
+	CodeVerifier string    `gorm:"type:varchar(128)"`
+	ExpiresAt    time.Time `gorm:"index"`
+	CreatedAt    time.Time
+}
+
+// TableName keeps gorm from splitting the OIDC initialism into "o_id_c".
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
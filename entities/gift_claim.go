@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+const (
+	ClaimReserved  = "reserved"
+	ClaimPurchased = "purchased"
+)
+
+// GiftClaim is a visitor of a shared list reserving or buying Quantity units
+// of a wishlist, so that other visitors do not buy the same gift. Visitors
+// have no account; whoever holds the claim's token, of which only the
+// SHA-256 hash is stored, may change or release it.
+type GiftClaim struct {
+	ID         uint
+	WishlistID uint      `gorm:"index"`
+	Wishlist   *Wishlist `json:"-" gorm:"foreignKey:WishlistID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Name       string    `gorm:"type:varchar(100)"`
+	Status     string    `gorm:"type:varchar(20)"`
+	Quantity   int
+	TokenHash  string `json:"-" gorm:"type:char(64);uniqueIndex"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,20 @@
+package entities
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type List struct {
+	ID        uint
+	UserID    int
+	User      *User `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Name      string
+	IsDefault bool
+	// DefaultOwner is UserID on the default list and NULL otherwise; its
+	// unique index allows only one default list per user.
+	DefaultOwner *int `json:"-" gorm:"->;type:int GENERATED ALWAYS AS (IF(is_default, user_id, NULL)) STORED;uniqueIndex"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt
+}
@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// ListShare is a read-only link to a list for people without an account.
+// Only the SHA-256 hash of the link's token is stored. PasswordHash is empty
+// when the link needs no password and a nil ExpiresAt never expires. A list
+// has at most one share; regenerating it replaces the token. Visitors can
+// claim gifts on a shared list; unless RevealClaims is set the owner does not
+// see the claims (surprise mode). The owner is only recognised when signed in,
+// so until then visitors see how many units are claimed but not by whom.
+type ListShare struct {
+	ID           uint
+	ListID       uint   `gorm:"uniqueIndex"`
+	List         *List  `json:"-" gorm:"foreignKey:ListID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	TokenHash    string `json:"-" gorm:"type:char(64);uniqueIndex"`
+	PasswordHash string `json:"-" gorm:"type:varchar(255)"`
+	ExpiresAt    *time.Time
+	RevealClaims bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
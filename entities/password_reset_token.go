@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// PasswordResetToken is a single use token emailed to a user who forgot
+// their password. Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uint
+	UserID    int
+	User      *User     `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	TokenHash string    `gorm:"type:char(64);uniqueIndex"`
+	ExpiresAt time.Time `gorm:"index"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
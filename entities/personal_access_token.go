@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+// Scopes that can be granted to a personal access token. Write access to
+// wishlists includes read access.
+const (
+	ScopeWishlistsRead  = "wishlists:read"
+	ScopeWishlistsWrite = "wishlists:write"
+)
+
+// PersonalAccessToken lets scripts and integrations call the API without the
+// account password. Only the SHA-256 hash of the token is stored. Scopes is a
+// space separated list of the granted scopes and a nil ExpiresAt never
+// expires.
+type PersonalAccessToken struct {
+	ID         uint
+	UserID     int    `gorm:"index"`
+	User       *User  `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Name       string `gorm:"type:varchar(100)"`
+	TokenHash  string `gorm:"type:char(64);uniqueIndex"`
+	Scopes     string `gorm:"type:varchar(255)"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+// RefreshToken is a long-lived token that can be exchanged once for a new
+// access/refresh token pair. Only the SHA-256 hash of the token is stored.
+// AccessJTI is the id of the access token issued together with it, so that
+// revoking the refresh token can revoke that access token as well. SessionID
+// is zero for tokens issued before sessions were tracked.
+type RefreshToken struct {
+	ID              uint
+	UserID          int
+	User            *User  `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	SessionID       uint   `gorm:"index"`
+	TokenHash       string `gorm:"type:char(64);uniqueIndex"`
+	AccessJTI       string `gorm:"type:varchar(64)"`
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time `gorm:"index"`
+	RevokedAt       *time.Time
+	CreatedAt       time.Time
+}
@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+// RevokedToken is an access token that must be rejected before it expires.
+// Rows are useless once ExpiresAt has passed and are purged periodically.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;type:varchar(64)"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+// Session is one sign-in of a user on a device. The refresh tokens of a
+// session carry its id and its access tokens carry it as the sid claim, so
+// revoking the session signs the device out. LastSeenAt is updated whenever
+// the session's tokens are refreshed and ExpiresAt follows the expiry of its
+// latest refresh token.
+type Session struct {
+	ID         uint
+	UserID     int
+	User       *User  `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	UserAgent  string `gorm:"type:varchar(255)"`
+	IP         string `gorm:"type:varchar(45)"`
+	LastSeenAt time.Time
+	ExpiresAt  time.Time `gorm:"index"`
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
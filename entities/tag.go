@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+type Tag struct {
+	ID        uint
+	UserID    int    `gorm:"uniqueIndex:idx_tags_user_name"`
+	User      *User  `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Name      string `gorm:"type:varchar(50);uniqueIndex:idx_tags_user_name"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+// TOTPCredential is the authenticator app secret of a user. It only protects
+// logins once ConfirmedAt is set. LastUsedStep is the time step of the last
+// accepted code so that a code cannot be used twice.
+type TOTPCredential struct {
+	UserID       int   `gorm:"primaryKey;autoIncrement:false"`
+	User         *User `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Secret       string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// RecoveryCode is a single use code that replaces a TOTP code when the
+// authenticator is lost. Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint
+	UserID    int    `gorm:"index"`
+	User      *User  `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CodeHash  string `gorm:"type:char(64)"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -2,10 +2,34 @@ package entities
 
 import "time"
 
+// Roles a user can have. What each role may do is defined in package rbac.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// User is an account. Password holds the password hash and is never
+// serialized; handlers respond with dto.UserResponse instead. Birthday only
+// carries a date, Locale is a BCP 47 language tag, Timezone an IANA time zone
+// name and DefaultCurrency an ISO 4217 code. DeletionScheduledAt is set while
+// the account waits to be erased, see AccountDeletion.
 type User struct {
-	Id        int
-	Email     string
-	Password  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	Id                  int
+	Email               string
+	Password            string `json:"-"`
+	EmailVerifiedAt     *time.Time
+	VerificationSentAt  *time.Time
+	PendingEmail        *string
+	Role                string `gorm:"type:varchar(20);not null;default:user"`
+	DisabledAt          *time.Time
+	DisplayName         string     `gorm:"type:varchar(100)"`
+	AvatarURL           string     `gorm:"type:varchar(2048)"`
+	Birthday            *time.Time `gorm:"type:date"`
+	Locale              string     `gorm:"type:varchar(35)"`
+	Timezone            string     `gorm:"type:varchar(64)"`
+	DefaultCurrency     string     `gorm:"type:char(3)"`
+	DeletionScheduledAt *time.Time
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
 }
@@ -5,11 +5,33 @@ import (
 	"time"
 )
 
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
 type Wishlist struct {
-	ID         uint
-	Title      string
-	IsAchieved bool
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  gorm.DeletedAt
+	ID          uint
+	UserID      int   `gorm:"not null;index"`
+	User        *User `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ListID      *uint
+	List        *List `json:"-" gorm:"foreignKey:ListID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Title       string
+	IsAchieved  bool
+	AchievedAt  *time.Time
+	PriceAmount *int64
+	Currency    string     `gorm:"type:char(3)"`
+	URL         string     `gorm:"type:varchar(2048)"`
+	Priority    string     `gorm:"type:varchar(10);not null;default:medium"`
+	Quantity    int        `gorm:"not null;default:1"`
+	Notes       string     `gorm:"type:text"`
+	TargetDate  *time.Time `gorm:"type:date"`
+	Tags        []Tag      `gorm:"many2many:wishlist_tags;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// AchievementEvents is never preloaded; events appended to it are
+	// inserted together with the wishlist.
+	AchievementEvents []AchievementEvent `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         gorm.DeletedAt
 }
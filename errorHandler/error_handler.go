@@ -4,14 +4,18 @@ import (
 	"github.com/labstack/echo/v4"
 	"go-wishlist-api-2/dto"
 	"go-wishlist-api-2/helper"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 func HandleError(c echo.Context, err error) error {
 	var statusCode int
+	var fields []dto.FieldError
 	switch err.(type) {
 	case *BadRequestError:
 		statusCode = http.StatusBadRequest
+		fields = err.(*BadRequestError).Fields
 	case *InternalServerError:
 		statusCode = http.StatusInternalServerError
 	case *NotFoundError:
@@ -20,6 +24,13 @@ func HandleError(c echo.Context, err error) error {
 		statusCode = http.StatusUnauthorized
 	case *ForbiddenError:
 		statusCode = http.StatusForbidden
+	case *ConflictError:
+		statusCode = http.StatusConflict
+	case *TooManyRequestsError:
+		statusCode = http.StatusTooManyRequests
+		if retryAfter := err.(*TooManyRequestsError).RetryAfter; retryAfter > 0 {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
 	default:
 		statusCode = http.StatusInternalServerError
 	}
@@ -27,6 +38,7 @@ func HandleError(c echo.Context, err error) error {
 		Status:     false,
 		StatusCode: statusCode,
 		Message:    err.Error(),
+		Errors:     fields,
 	})
 
 	return c.JSON(statusCode, response)
@@ -2,9 +2,11 @@ package errorHandler
 
 import (
 	"errors"
+	"go-wishlist-api-2/dto"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -41,6 +43,16 @@ func TestHandleError(t *testing.T) {
 			err:      &ForbiddenError{Message: "Forbidden"},
 			expected: http.StatusForbidden,
 		},
+		{
+			name:     "ConflictError",
+			err:      &ConflictError{Message: "Conflict"},
+			expected: http.StatusConflict,
+		},
+		{
+			name:     "TooManyRequestsError",
+			err:      &TooManyRequestsError{Message: "Slow down", RetryAfter: 1500 * time.Millisecond},
+			expected: http.StatusTooManyRequests,
+		},
 		{
 			name:     "UnknownError",
 			err:      errors.New("Unknown error"),
@@ -63,7 +75,33 @@ func TestHandleError(t *testing.T) {
 			if err != nil {
 				assert.Equal(t, tt.expected, err.(*echo.HTTPError).Code)
 			}
+			assert.Equal(t, tt.expected, rec.Code)
 		})
 	}
 
+	t.Run("Retry-After", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		HandleError(c, &TooManyRequestsError{Message: "Slow down", RetryAfter: 1500 * time.Millisecond})
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("Field errors", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		HandleError(c, &BadRequestError{
+			Message: "Invalid request",
+			Fields:  []dto.FieldError{{Field: "password", Message: "must be at least 8 characters long"}},
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"Errors":[{"field":"password","message":"must be at least 8 characters long"}]`)
+	})
+
+	t.Run("No field errors", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		HandleError(c, &BadRequestError{Message: "Bad request"})
+		assert.NotContains(t, rec.Body.String(), "Errors")
+	})
+
 }
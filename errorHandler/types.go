@@ -1,7 +1,15 @@
 package errorHandler
 
+import (
+	"go-wishlist-api-2/dto"
+	"time"
+)
+
+// BadRequestError rejects a request. Fields optionally tells which request
+// fields were invalid and why.
 type BadRequestError struct {
 	Message string
+	Fields  []dto.FieldError
 }
 
 type InternalServerError struct {
@@ -19,6 +27,19 @@ type ForbiddenError struct {
 	Message string
 }
 
+// ConflictError rejects a request that conflicts with the current state of
+// the resource, such as claiming a gift that is already fully claimed.
+type ConflictError struct {
+	Message string
+}
+
+// TooManyRequestsError tells the client to slow down. RetryAfter, if set, is
+// sent back in the Retry-After header.
+type TooManyRequestsError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
 func (err *BadRequestError) Error() string {
 	return err.Message
 }
@@ -38,3 +59,11 @@ func (err *UnAuthorizedError) Error() string {
 func (err *ForbiddenError) Error() string {
 	return err.Message
 }
+
+func (err *ConflictError) Error() string {
+	return err.Message
+}
+
+func (err *TooManyRequestsError) Error() string {
+	return err.Message
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/usecases"
+	"net/http"
+)
+
+type accountHandler struct {
+	usecase usecases.AccountUsecase
+}
+
+func NewAccountHandler(uc usecases.AccountUsecase) *accountHandler {
+	return &accountHandler{uc}
+}
+
+// Export sends the user's data as a zip archive download.
+func (h *accountHandler) Export(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	archive, err := h.usecase.Export(claims.Id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	ctx.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="wishlist-export-%d.zip"`, claims.Id))
+	ctx.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return ctx.Blob(http.StatusOK, "application/zip", archive)
+}
+
+func (h *accountHandler) Delete(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	var request dto.PasswordRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	// Accounts without a password the user knows confirm by email instead.
+	if request.Password == "" {
+		if err := h.usecase.RequestDeletion(claims.Id); err != nil {
+			return errorHandler.HandleError(ctx, err)
+		}
+		response := helper.Response(dto.ResponseParam{
+			Status:     true,
+			StatusCode: http.StatusAccepted,
+			Message:    "Check your email to confirm the deletion of your account",
+		})
+		return ctx.JSON(http.StatusAccepted, response)
+	}
+	deletion, err := h.usecase.Delete(claims.Id, &request)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusAccepted,
+		Message:    "Account scheduled for deletion, sign in before it is erased to keep it",
+		Data:       deletion,
+	})
+	return ctx.JSON(http.StatusAccepted, response)
+}
+
+// confirmDeletionForm is the page deletion confirmation emails link to. Opening
+// the link alone deletes nothing, so mail scanners that follow links cannot
+// delete an account; the page posts the token from its URL to
+// POST /account/delete once the user confirms.
+const confirmDeletionForm = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Delete your account</title>
+</head>
+<body>
+<h1>Delete your account</h1>
+<form id="delete">
+<p>Your account and all of its data will be erased after the grace period.</p>
+<button type="submit">Delete my account</button>
+</form>
+<p id="result" role="status"></p>
+<script>
+document.getElementById("delete").addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const token = new URLSearchParams(location.search).get("token") || "";
+  const response = await fetch(location.pathname, {
+    method: "POST",
+    headers: {"Content-Type": "application/json"},
+    body: JSON.stringify({token}),
+  });
+  const body = await response.json().catch(() => ({}));
+  document.getElementById("result").textContent = body.Message || response.statusText;
+});
+</script>
+</body>
+</html>
+`
+
+// ConfirmDeletionForm serves the page behind the link in deletion
+// confirmation emails. The token in its URL must stay out of caches and
+// Referer headers.
+func (h *accountHandler) ConfirmDeletionForm(ctx echo.Context) error {
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderCacheControl, "no-store")
+	header.Set("Referrer-Policy", "no-referrer")
+	header.Set(echo.HeaderContentSecurityPolicy, "default-src 'none'; script-src 'unsafe-inline'; connect-src 'self'")
+	return ctx.HTML(http.StatusOK, confirmDeletionForm)
+}
+
+func (h *accountHandler) ConfirmDeletion(ctx echo.Context) error {
+	var request dto.TokenRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	deletion, err := h.usecase.ConfirmDeletion(&request)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusAccepted,
+		Message:    "Account scheduled for deletion, sign in before it is erased to keep it",
+		Data:       deletion,
+	})
+	return ctx.JSON(http.StatusAccepted, response)
+}
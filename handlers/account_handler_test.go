@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bytes"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAccountUsecase) Export(userID int) ([]byte, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), nil
+}
+
+func (m *MockAccountUsecase) Delete(userID int, request *dto.PasswordRequest) (*dto.AccountDeletionResponse, error) {
+	args := m.Called(userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AccountDeletionResponse), nil
+}
+
+func (m *MockAccountUsecase) RequestDeletion(userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockAccountUsecase) ConfirmDeletion(request *dto.TokenRequest) (*dto.AccountDeletionResponse, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AccountDeletionResponse), nil
+}
+
+func (m *MockAccountUsecase) EraseDueAccounts() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestAccountHandler_Export(t *testing.T) {
+	mockUsecase := new(MockAccountUsecase)
+	mockUsecase.On("Export", 1).Return([]byte("PK"), nil)
+
+	handler := NewAccountHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/me/export", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.Export(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/zip", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `attachment; filename="wishlist-export-1.zip"`, rec.Header().Get(echo.HeaderContentDisposition))
+	assert.Equal(t, "PK", rec.Body.String())
+}
+
+func TestAccountHandler_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockAccountUsecase)
+		mockUsecase.On("Delete", 1, &dto.PasswordRequest{Password: "secret"}).
+			Return(&dto.AccountDeletionResponse{ID: 7, UserID: 1, Status: "scheduled"}, nil)
+
+		handler := NewAccountHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/me", bytes.NewBufferString(`{"password":"secret"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.Delete(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"scheduled"`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		mockUsecase := new(MockAccountUsecase)
+		mockUsecase.On("Delete", 1, mock.Anything).Return(nil, &errorHandler.BadRequestError{Message: "Password is wrong"})
+
+		handler := NewAccountHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/me", bytes.NewBufferString(`{"password":"wrong"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.Delete(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+	t.Run("Without a password", func(t *testing.T) {
+		mockUsecase := new(MockAccountUsecase)
+		mockUsecase.On("RequestDeletion", 1).Return(nil)
+
+		handler := NewAccountHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/me", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.Delete(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		mockUsecase.AssertExpectations(t)
+		mockUsecase.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAccountHandler_ConfirmDeletionForm(t *testing.T) {
+	handler := NewAccountHandler(new(MockAccountUsecase))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/account/delete?token=abc", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.ConfirmDeletionForm(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get(echo.HeaderCacheControl))
+	assert.Equal(t, "no-referrer", rec.Header().Get("Referrer-Policy"))
+	assert.Contains(t, rec.Body.String(), "<form")
+}
+
+func TestAccountHandler_ConfirmDeletion(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockAccountUsecase)
+		mockUsecase.On("ConfirmDeletion", &dto.TokenRequest{Token: "abc"}).
+			Return(&dto.AccountDeletionResponse{ID: 7, UserID: 1, Status: "scheduled"}, nil)
+
+		handler := NewAccountHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/account/delete", bytes.NewBufferString(`{"token":"abc"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.ConfirmDeletion(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"scheduled"`)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		mockUsecase := new(MockAccountUsecase)
+		mockUsecase.On("ConfirmDeletion", mock.Anything).Return(nil, &errorHandler.BadRequestError{Message: "invalid or expired token"})
+
+		handler := NewAccountHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/account/delete", bytes.NewBufferString(`{"token":"bad"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.ConfirmDeletion(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
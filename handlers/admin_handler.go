@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/usecases"
+	"net/http"
+)
+
+type adminHandler struct {
+	usecase usecases.AdminUsecase
+}
+
+func NewAdminHandler(uc usecases.AdminUsecase) *adminHandler {
+	return &adminHandler{uc}
+}
+
+func (h *adminHandler) GetUsers(ctx echo.Context) error {
+	query := &dto.UserFilter{
+		Email: ctx.QueryParam("email"),
+		Role:  ctx.QueryParam("role"),
+	}
+	err := echo.QueryParamsBinder(ctx).
+		Int("limit", &query.Limit).
+		Int("offset", &query.Offset).
+		BindError()
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: "limit and offset must be integers"})
+	}
+	users, meta, err := h.usecase.ListUsers(query)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get users successfully",
+		Data:       users,
+		Meta:       meta,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *adminHandler) GetUser(ctx echo.Context) error {
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	user, err := h.usecase.GetUser(int(id))
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get user successfully",
+		Data:       user,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *adminHandler) DisableUser(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	user, err := h.usecase.DisableUser(claims.Id, int(id))
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "User disabled successfully",
+		Data:       user,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *adminHandler) EnableUser(ctx echo.Context) error {
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	user, err := h.usecase.EnableUser(int(id))
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "User enabled successfully",
+		Data:       user,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *adminHandler) UnlockUser(ctx echo.Context) error {
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	if err := h.usecase.UnlockUser(int(id)); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "User unlocked successfully",
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *adminHandler) SetRole(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	var request dto.RoleRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	user, err := h.usecase.SetRole(claims.Id, int(id), &request)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "User role updated successfully",
+		Data:       user,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *adminHandler) GetWishlist(ctx echo.Context) error {
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	wishlist, err := h.usecase.GetWishlist(id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get wishlist successfully",
+		Data:       wishlist,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *adminHandler) GetDeletions(ctx echo.Context) error {
+	query := &dto.DeletionFilter{}
+	err := echo.QueryParamsBinder(ctx).
+		Int("user_id", &query.UserID).
+		Int("limit", &query.Limit).
+		Int("offset", &query.Offset).
+		BindError()
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: "user_id, limit and offset must be integers"})
+	}
+	deletions, meta, err := h.usecase.ListDeletions(query)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get account deletions successfully",
+		Data:       deletions,
+		Meta:       meta,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
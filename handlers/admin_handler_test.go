@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"bytes"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAdminUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAdminUsecase) ListUsers(query *dto.UserFilter) ([]*dto.AdminUserResponse, *dto.PageMeta, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*dto.AdminUserResponse), args.Get(1).(*dto.PageMeta), nil
+}
+
+func (m *MockAdminUsecase) GetUser(id int) (*dto.AdminUserResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AdminUserResponse), nil
+}
+
+func (m *MockAdminUsecase) DisableUser(actorID int, id int) (*dto.AdminUserResponse, error) {
+	args := m.Called(actorID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AdminUserResponse), nil
+}
+
+func (m *MockAdminUsecase) EnableUser(id int) (*dto.AdminUserResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AdminUserResponse), nil
+}
+
+func (m *MockAdminUsecase) UnlockUser(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAdminUsecase) SetRole(actorID int, id int, request *dto.RoleRequest) (*dto.AdminUserResponse, error) {
+	args := m.Called(actorID, id, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.AdminUserResponse), nil
+}
+
+func (m *MockAdminUsecase) GetWishlist(id uint) (*entities.Wishlist, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Wishlist), nil
+}
+
+func (m *MockAdminUsecase) ListDeletions(query *dto.DeletionFilter) ([]*dto.AccountDeletionResponse, *dto.PageMeta, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*dto.AccountDeletionResponse), args.Get(1).(*dto.PageMeta), nil
+}
+
+func TestAdminHandler_GetUsers(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockAdminUsecase)
+		mockUsecase.On("ListUsers", &dto.UserFilter{Email: "bob", Role: "admin", Limit: 5}).
+			Return([]*dto.AdminUserResponse{{ID: 2, Email: "bob@example.com", Role: "admin"}}, &dto.PageMeta{Total: 1, Limit: 5}, nil)
+
+		handler := NewAdminHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/users?email=bob&role=admin&limit=5", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.GetUsers(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "bob@example.com")
+		assert.NotContains(t, rec.Body.String(), "password")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		handler := NewAdminHandler(new(MockAdminUsecase))
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/users?limit=many", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.GetUsers(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestAdminHandler_DisableUser(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		disabledAt := time.Now()
+		mockUsecase := new(MockAdminUsecase)
+		mockUsecase.On("DisableUser", 1, 2).Return(&dto.AdminUserResponse{ID: 2, DisabledAt: &disabledAt}, nil)
+
+		handler := NewAdminHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/2/disable", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("2")
+		setClaims(c, 1)
+
+		err := handler.DisableUser(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Own account", func(t *testing.T) {
+		mockUsecase := new(MockAdminUsecase)
+		mockUsecase.On("DisableUser", 1, 1).Return(nil, &errorHandler.BadRequestError{Message: "You cannot disable your own account"})
+
+		handler := NewAdminHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/1/disable", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		setClaims(c, 1)
+
+		err := handler.DisableUser(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestAdminHandler_UnlockUser(t *testing.T) {
+	mockUsecase := new(MockAdminUsecase)
+	mockUsecase.On("UnlockUser", 2).Return(nil)
+
+	handler := NewAdminHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/2/unlock", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+	setClaims(c, 1)
+
+	err := handler.UnlockUser(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAdminHandler_SetRole(t *testing.T) {
+	mockUsecase := new(MockAdminUsecase)
+	mockUsecase.On("SetRole", 1, 2, &dto.RoleRequest{Role: "moderator"}).
+		Return(&dto.AdminUserResponse{ID: 2, Role: "moderator"}, nil)
+
+	handler := NewAdminHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/admin/users/2/role", bytes.NewBufferString(`{"role":"moderator"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+	setClaims(c, 1)
+
+	err := handler.SetRole(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAdminHandler_GetWishlist(t *testing.T) {
+	mockUsecase := new(MockAdminUsecase)
+	mockUsecase.On("GetWishlist", uint(5)).Return(&entities.Wishlist{ID: 5, UserID: 9, Title: "Bike"}, nil)
+
+	handler := NewAdminHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/admin/wishlists/5", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("5")
+	setClaims(c, 1)
+
+	err := handler.GetWishlist(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Bike")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAdminHandler_GetDeletions(t *testing.T) {
+	mockUsecase := new(MockAdminUsecase)
+	mockUsecase.On("ListDeletions", &dto.DeletionFilter{UserID: 3, Limit: 10}).
+		Return([]*dto.AccountDeletionResponse{{ID: 7, UserID: 3, Status: "erased"}}, &dto.PageMeta{Total: 1, Limit: 10}, nil)
+
+	handler := NewAdminHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/admin/deletions?user_id=3&limit=10", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.GetDeletions(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"erased"`)
+	mockUsecase.AssertExpectations(t)
+}
@@ -20,7 +20,7 @@ func NewAuthHandler(usecase usecases.AuthUsecase) *authHandler {
 func (h *authHandler) Register(ctx echo.Context) error {
 	var user dto.UserRequest
 	if err := ctx.Bind(&user); err != nil {
-		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{err.Error()})
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
 	}
 
 	newUser, err := h.usecase.Register(&user)
@@ -41,10 +41,285 @@ func (h *authHandler) Register(ctx echo.Context) error {
 func (h *authHandler) Login(ctx echo.Context) error {
 	var user dto.UserRequest
 	if err := ctx.Bind(&user); err != nil {
-		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{err.Error()})
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
 	}
 
-	token, err := h.usecase.Login(&user)
+	token, err := h.usecase.Login(&user, clientInfo(ctx))
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	message := "Login successfully"
+	if token.MFARequired {
+		message = "Two-factor authentication code required"
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    message,
+		Data:       token,
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *authHandler) Refresh(ctx echo.Context) error {
+	var request dto.RefreshRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+
+	token, err := h.usecase.Refresh(&request)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Refresh token successfully",
+		Data:       token,
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *authHandler) Logout(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	var request dto.RefreshRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+
+	if err := h.usecase.Logout(claims, &request); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Logout successfully",
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *authHandler) LogoutAll(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+
+	if err := h.usecase.LogoutAll(claims); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Logout from all sessions successfully",
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// JWKS publishes the public signing keys in the standard JWK Set format so
+// other services can validate our tokens without sharing a secret.
+func (h *authHandler) JWKS(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, helper.PublicJWKS())
+}
+
+func (h *authHandler) VerifyEmail(ctx echo.Context) error {
+	user, err := h.usecase.VerifyEmail(ctx.QueryParam("token"))
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Email verified successfully",
+		Data:       user,
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *authHandler) ResendVerification(ctx echo.Context) error {
+	var request dto.EmailRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+
+	if err := h.usecase.ResendVerification(&request); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "If the account exists and is not verified yet, a verification email has been sent",
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *authHandler) ForgotPassword(ctx echo.Context) error {
+	var request dto.EmailRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+
+	if err := h.usecase.ForgotPassword(&request); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "If the email is registered, a password reset link has been sent",
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// resetPasswordForm is the page password reset emails link to when no
+// frontend is configured. It posts the token from its URL together with the
+// new password to POST /password/reset.
+const resetPasswordForm = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Reset your password</title>
+</head>
+<body>
+<h1>Reset your password</h1>
+<form id="reset">
+<label for="password">New password</label>
+<input id="password" type="password" autocomplete="new-password" required>
+<button type="submit">Reset password</button>
+</form>
+<p id="result" role="status"></p>
+<script>
+document.getElementById("reset").addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const token = new URLSearchParams(location.search).get("token") || "";
+  const password = document.getElementById("password").value;
+  const response = await fetch(location.pathname, {
+    method: "POST",
+    headers: {"Content-Type": "application/json"},
+    body: JSON.stringify({token, password}),
+  });
+  const body = await response.json().catch(() => ({}));
+  document.getElementById("result").textContent = body.Message || response.statusText;
+});
+</script>
+</body>
+</html>
+`
+
+// ResetPasswordForm serves the page behind the link in password reset
+// emails. The token in its URL must stay out of caches and Referer headers.
+func (h *authHandler) ResetPasswordForm(ctx echo.Context) error {
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderCacheControl, "no-store")
+	header.Set("Referrer-Policy", "no-referrer")
+	header.Set(echo.HeaderContentSecurityPolicy, "default-src 'none'; script-src 'unsafe-inline'; connect-src 'self'")
+	return ctx.HTML(http.StatusOK, resetPasswordForm)
+}
+
+func (h *authHandler) ResetPassword(ctx echo.Context) error {
+	var request dto.PasswordResetRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+
+	if err := h.usecase.ResetPassword(&request); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Password reset successfully",
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *authHandler) ChangePassword(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	var request dto.ChangePasswordRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+
+	if err := h.usecase.ChangePassword(claims, &request); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Password changed successfully",
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *authHandler) ChangeEmail(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	var request dto.ChangeEmailRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+
+	if err := h.usecase.ChangeEmail(claims, &request); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusAccepted,
+		Message:    "A confirmation link has been sent to the new email address",
+	})
+
+	return ctx.JSON(http.StatusAccepted, response)
+}
+
+func (h *authHandler) ConfirmEmailChange(ctx echo.Context) error {
+	user, err := h.usecase.ConfirmEmailChange(ctx.QueryParam("token"))
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Email changed successfully",
+		Data:       user,
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *authHandler) VerifyMFA(ctx echo.Context) error {
+	var request dto.MFALoginRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+
+	token, err := h.usecase.VerifyMFA(&request, clientInfo(ctx))
 	if err != nil {
 		return errorHandler.HandleError(ctx, err)
 	}
@@ -58,3 +333,128 @@ func (h *authHandler) Login(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, response)
 }
+
+func (h *authHandler) EnrollTOTP(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+
+	enrollment, err := h.usecase.EnrollTOTP(claims)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Scan the URI with an authenticator app and confirm with a code",
+		Data:       enrollment,
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *authHandler) ConfirmTOTP(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	var request dto.CodeRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+
+	codes, err := h.usecase.ConfirmTOTP(claims, &request)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Two-factor authentication enabled",
+		Data:       codes,
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *authHandler) DisableTOTP(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	var request dto.PasswordRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+
+	if err := h.usecase.DisableTOTP(claims, &request); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Two-factor authentication disabled",
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// oidcStateCookie binds a sign-in with an identity provider to the browser
+// that started it, so that a callback URL cannot be replayed in another one.
+const oidcStateCookie = "oidc_state"
+
+func (h *authHandler) BeginOIDCLogin(ctx echo.Context) error {
+	redirect, err := h.usecase.BeginOIDCLogin(ctx.Param("provider"))
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	ctx.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    redirect.State,
+		Path:     "/auth/oidc",
+		HttpOnly: true,
+		Secure:   ctx.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+	return ctx.Redirect(http.StatusFound, redirect.URL)
+}
+
+func (h *authHandler) CompleteOIDCLogin(ctx echo.Context) error {
+	if providerError := ctx.QueryParam("error"); providerError != "" {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: "Login Failed: the identity provider returned " + providerError})
+	}
+	state := ctx.QueryParam("state")
+	cookie, err := ctx.Cookie(oidcStateCookie)
+	if err != nil || state == "" || cookie.Value != state {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: "Login state does not match this browser"})
+	}
+	ctx.SetCookie(&http.Cookie{Name: oidcStateCookie, Path: "/auth/oidc", MaxAge: -1, HttpOnly: true})
+
+	token, err := h.usecase.CompleteOIDCLogin(ctx.Param("provider"), state, ctx.QueryParam("code"), clientInfo(ctx))
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+
+	message := "Login successfully"
+	if token.MFARequired {
+		message = "Two-factor authentication code required"
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    message,
+		Data:       token,
+	})
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// clientInfo describes the client of the request for the session a login
+// starts.
+func clientInfo(ctx echo.Context) dto.ClientInfo {
+	return dto.ClientInfo{IP: ctx.RealIP(), UserAgent: ctx.Request().UserAgent()}
+}
@@ -6,10 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"go-wishlist-api-2/dto"
-	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -20,16 +22,130 @@ type MockAuthUsecase struct {
 	mock.Mock
 }
 
-func (m *MockAuthUsecase) Register(user *dto.UserRequest) (*entities.User, error) {
+func (m *MockAuthUsecase) Register(user *dto.UserRequest) (*dto.UserResponse, error) {
 	args := m.Called(user)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*entities.User), nil
+	return args.Get(0).(*dto.UserResponse), nil
 }
 
-func (m *MockAuthUsecase) Login(user *dto.UserRequest) (*dto.LoginResponse, error) {
-	args := m.Called(user)
+func (m *MockAuthUsecase) Login(user *dto.UserRequest, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	args := m.Called(user, client)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LoginResponse), nil
+}
+
+func (m *MockAuthUsecase) Refresh(request *dto.RefreshRequest) (*dto.LoginResponse, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LoginResponse), nil
+}
+
+func (m *MockAuthUsecase) Logout(claims *helper.JWTClaims, request *dto.RefreshRequest) error {
+	args := m.Called(claims, request)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) LogoutAll(claims *helper.JWTClaims) error {
+	args := m.Called(claims)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) PurgeExpiredTokens() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuthUsecase) VerifyEmail(token string) (*dto.UserResponse, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), nil
+}
+
+func (m *MockAuthUsecase) ResendVerification(request *dto.EmailRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) ForgotPassword(request *dto.EmailRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) ResetPassword(request *dto.PasswordResetRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) ChangePassword(claims *helper.JWTClaims, request *dto.ChangePasswordRequest) error {
+	args := m.Called(claims, request)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) ChangeEmail(claims *helper.JWTClaims, request *dto.ChangeEmailRequest) error {
+	args := m.Called(claims, request)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) ConfirmEmailChange(token string) (*dto.UserResponse, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), nil
+}
+
+func (m *MockAuthUsecase) UnlockAccount(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) VerifyMFA(request *dto.MFALoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	args := m.Called(request, client)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LoginResponse), nil
+}
+
+func (m *MockAuthUsecase) EnrollTOTP(claims *helper.JWTClaims) (*dto.TOTPEnrollment, error) {
+	args := m.Called(claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TOTPEnrollment), nil
+}
+
+func (m *MockAuthUsecase) ConfirmTOTP(claims *helper.JWTClaims, request *dto.CodeRequest) (*dto.RecoveryCodesResponse, error) {
+	args := m.Called(claims, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RecoveryCodesResponse), nil
+}
+
+func (m *MockAuthUsecase) DisableTOTP(claims *helper.JWTClaims, request *dto.PasswordRequest) error {
+	args := m.Called(claims, request)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) BeginOIDCLogin(provider string) (*dto.OIDCLoginRedirect, error) {
+	args := m.Called(provider)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.OIDCLoginRedirect), nil
+}
+
+func (m *MockAuthUsecase) CompleteOIDCLogin(provider, state, code string, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	args := m.Called(provider, state, code, client)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -39,10 +155,9 @@ func (m *MockAuthUsecase) Login(user *dto.UserRequest) (*dto.LoginResponse, erro
 func TestAuthHandler_Register(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockUser := &dto.UserRequest{Email: "test@example.com", Password: "password"}
-		mockUserResponse := &entities.User{
-			Id:       1,
-			Email:    mockUser.Email,
-			Password: mockUser.Password,
+		mockUserResponse := &dto.UserResponse{
+			ID:    1,
+			Email: mockUser.Email,
 		}
 
 		mockUsecase := new(MockAuthUsecase)
@@ -111,7 +226,7 @@ func TestAuthHandler_Login(t *testing.T) {
 		mockToken := &dto.LoginResponse{Token: "alta2024"}
 
 		mockUsecase := new(MockAuthUsecase)
-		mockUsecase.On("Login", mock.Anything).Return(mockToken, nil)
+		mockUsecase.On("Login", mock.Anything, dto.ClientInfo{IP: "192.0.2.1", UserAgent: "wishlist-cli/1.0"}).Return(mockToken, nil)
 
 		handler := NewAuthHandler(mockUsecase)
 
@@ -119,6 +234,7 @@ func TestAuthHandler_Login(t *testing.T) {
 		reqBody, _ := json.Marshal(mockUser)
 		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(reqBody))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "wishlist-cli/1.0")
 		rec := httptest.NewRecorder()
 		rec.Code = http.StatusOK
 		c := e.NewContext(req, rec)
@@ -141,7 +257,7 @@ func TestAuthHandler_Login(t *testing.T) {
 		mockUser := &dto.UserRequest{Email: "test@example.com", Password: "password"}
 
 		mockUsecase := new(MockAuthUsecase)
-		mockUsecase.On("Login", mock.Anything).Return(nil, errors.New("Login failed"))
+		mockUsecase.On("Login", mock.Anything, mock.Anything).Return(nil, errors.New("Login failed"))
 
 		handler := NewAuthHandler(mockUsecase)
 
@@ -164,6 +280,519 @@ func TestAuthHandler_Login(t *testing.T) {
 		assert.Nil(t, response.Data)
 
 		mockUsecase.AssertExpectations(t)
-		mockUsecase.AssertCalled(t, "Login", mockUser)
+		mockUsecase.AssertCalled(t, "Login", mockUser, dto.ClientInfo{IP: "192.0.2.1"})
+	})
+}
+
+func TestAuthHandler_Login_LockedOut(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("Login", mock.Anything, mock.Anything).
+		Return(nil, &errorHandler.TooManyRequestsError{Message: "Too many failed login attempts, please try again later", RetryAfter: 90 * time.Second})
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"email":"admin@example.com","password":"guess"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handler.Login(c)
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "90", rec.Header().Get("Retry-After"))
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_Login_MFARequired(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("Login", mock.Anything, mock.Anything).
+		Return(&dto.LoginResponse{MFARequired: true, MFAToken: "challenge", ExpiresAt: time.Now().Add(5 * time.Minute)}, nil)
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"email":"admin@example.com","password":"admin123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.Login(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"mfa_token":"challenge"`)
+	assert.NotContains(t, rec.Body.String(), `"refresh_token"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_VerifyMFA(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("VerifyMFA", &dto.MFALoginRequest{MFAToken: "challenge", Code: "123456"}, dto.ClientInfo{IP: "192.0.2.1"}).
+		Return(&dto.LoginResponse{Token: "access", RefreshToken: "refresh"}, nil)
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/login/mfa", bytes.NewBufferString(`{"mfa_token":"challenge","code":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.VerifyMFA(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"token":"access"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		mockUsecase.On("Refresh", &dto.RefreshRequest{RefreshToken: "refresh"}).Return(&dto.LoginResponse{Token: "access", RefreshToken: "next"}, nil)
+
+		handler := NewAuthHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/refresh", bytes.NewBufferString(`{"refresh_token":"refresh"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.Refresh(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"refresh_token":"next"`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		mockUsecase.On("Refresh", mock.Anything).Return(nil, &errorHandler.UnAuthorizedError{Message: "Refresh token has been revoked"})
+
+		handler := NewAuthHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/refresh", bytes.NewBufferString(`{"refresh_token":"refresh"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler.Refresh(c)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestAuthHandler_Logout(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("Logout", mock.MatchedBy(func(claims *helper.JWTClaims) bool {
+		return claims.Id == 1
+	}), &dto.RefreshRequest{RefreshToken: "refresh"}).Return(nil)
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/logout", bytes.NewBufferString(`{"refresh_token":"refresh"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.Logout(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_LogoutAll(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("LogoutAll", mock.MatchedBy(func(claims *helper.JWTClaims) bool {
+		return claims.Id == 1
+	})).Return(nil)
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/logout-all", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.LogoutAll(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_JWKS(t *testing.T) {
+	handler := NewAuthHandler(new(MockAuthUsecase))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.JWKS(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"keys":[]}`, rec.Body.String())
+}
+
+func TestAuthHandler_VerifyEmail(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		mockUsecase.On("VerifyEmail", "abc").Return(&dto.UserResponse{ID: 1}, nil)
+
+		handler := NewAuthHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/verify-email?token=abc", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.VerifyEmail(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		mockUsecase.On("VerifyEmail", "").Return(nil, &errorHandler.BadRequestError{Message: "Invalid or expired verification token"})
+
+		handler := NewAuthHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/verify-email", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler.VerifyEmail(c)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestAuthHandler_ResendVerification(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("ResendVerification", &dto.EmailRequest{Email: "admin@example.com"}).Return(nil)
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/verify-email/resend", bytes.NewBufferString(`{"email":"admin@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.ResendVerification(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "If the account exists")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_ForgotPassword(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("ForgotPassword", &dto.EmailRequest{Email: "unknown@example.com"}).Return(nil)
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/password/forgot", bytes.NewBufferString(`{"email":"unknown@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.ForgotPassword(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "If the email is registered")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_ResetPasswordForm(t *testing.T) {
+	handler := NewAuthHandler(new(MockAuthUsecase))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/password/reset?token=abc", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.ResetPasswordForm(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMETextHTML)
+	assert.Equal(t, "no-store", rec.Header().Get(echo.HeaderCacheControl))
+	assert.Equal(t, "no-referrer", rec.Header().Get("Referrer-Policy"))
+	assert.Contains(t, rec.Body.String(), "<form")
+}
+
+func TestAuthHandler_ResetPassword(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		mockUsecase.On("ResetPassword", &dto.PasswordResetRequest{Token: "abc", Password: "new-password"}).Return(nil)
+
+		handler := NewAuthHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/password/reset", bytes.NewBufferString(`{"token":"abc","password":"new-password"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.ResetPassword(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		mockUsecase.On("ResetPassword", mock.Anything).Return(&errorHandler.BadRequestError{Message: "Invalid or expired reset token"})
+
+		handler := NewAuthHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/password/reset", bytes.NewBufferString(`{"token":"abc","password":"new-password"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler.ResetPassword(c)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestAuthHandler_ChangePassword(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		mockUsecase.On("ChangePassword", mock.MatchedBy(func(claims *helper.JWTClaims) bool {
+			return claims.Id == 1
+		}), &dto.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "new-password"}).Return(nil)
+
+		handler := NewAuthHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/me/password", bytes.NewBufferString(`{"current_password":"old","new_password":"new-password"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.ChangePassword(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Wrong current password", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		mockUsecase.On("ChangePassword", mock.Anything, mock.Anything).Return(&errorHandler.BadRequestError{Message: "Current password is wrong"})
+
+		handler := NewAuthHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/me/password", bytes.NewBufferString(`{"current_password":"wrong","new_password":"new-password"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		handler.ChangePassword(c)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestAuthHandler_ChangeEmail(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("ChangeEmail", mock.MatchedBy(func(claims *helper.JWTClaims) bool {
+		return claims.Id == 1
+	}), &dto.ChangeEmailRequest{Email: "new@example.com", Password: "password"}).Return(nil)
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/me/email", bytes.NewBufferString(`{"email":"new@example.com","password":"password"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.ChangeEmail(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_ConfirmEmailChange(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("ConfirmEmailChange", "abc").Return(&dto.UserResponse{ID: 1, Email: "new@example.com"}, nil)
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/email/confirm?token=abc", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.ConfirmEmailChange(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "new@example.com")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_EnrollTOTP(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("EnrollTOTP", mock.MatchedBy(func(claims *helper.JWTClaims) bool {
+		return claims.Id == 1
+	})).Return(&dto.TOTPEnrollment{Secret: "SECRET", URI: "otpauth://totp/Wishlist:test@example.com?secret=SECRET"}, nil)
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/me/2fa/totp", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.EnrollTOTP(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"secret":"SECRET"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_ConfirmTOTP(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("ConfirmTOTP", mock.Anything, &dto.CodeRequest{Code: "123456"}).
+		Return(&dto.RecoveryCodesResponse{RecoveryCodes: []string{"aaaa-bbbb-cccc-dddd"}}, nil)
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/me/2fa/totp/confirm", bytes.NewBufferString(`{"code":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.ConfirmTOTP(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "aaaa-bbbb-cccc-dddd")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_DisableTOTP(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("DisableTOTP", mock.Anything, &dto.PasswordRequest{Password: "wrong"}).
+		Return(&errorHandler.BadRequestError{Message: "Password is wrong"})
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/me/2fa/totp", bytes.NewBufferString(`{"password":"wrong"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	handler.DisableTOTP(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_BeginOIDCLogin(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	mockUsecase.On("BeginOIDCLogin", "google").
+		Return(&dto.OIDCLoginRedirect{URL: "https://accounts.example.com/authorize?state=abc", State: "abc"}, nil)
+
+	handler := NewAuthHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/auth/oidc/google", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("provider")
+	c.SetParamValues("google")
+
+	err := handler.BeginOIDCLogin(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://accounts.example.com/authorize?state=abc", rec.Header().Get(echo.HeaderLocation))
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, "abc", cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthHandler_CompleteOIDCLogin(t *testing.T) {
+	callback := func(handler *authHandler, query, cookie string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/auth/oidc/google/callback?"+query, nil)
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "oidc_state", Value: cookie})
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("provider")
+		c.SetParamValues("google")
+		handler.CompleteOIDCLogin(c)
+		return rec
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		mockUsecase.On("CompleteOIDCLogin", "google", "abc", "code", dto.ClientInfo{IP: "192.0.2.1"}).
+			Return(&dto.LoginResponse{Token: "token", RefreshToken: "refresh"}, nil)
+
+		rec := callback(NewAuthHandler(mockUsecase), "state=abc&code=code", "abc")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "refresh")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("State from another browser", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+
+		rec := callback(NewAuthHandler(mockUsecase), "state=abc&code=code", "other")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUsecase.AssertNotCalled(t, "CompleteOIDCLogin", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Denied at the provider", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+
+		rec := callback(NewAuthHandler(mockUsecase), "error=access_denied&state=abc", "abc")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "access_denied")
 	})
 }
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/usecases"
+	"net/http"
+)
+
+type listHandler struct {
+	usecase         usecases.ListUsecase
+	wishlistUsecase usecases.WishlistUsecase
+}
+
+func NewListHandler(uc usecases.ListUsecase, wuc usecases.WishlistUsecase) *listHandler {
+	return &listHandler{uc, wuc}
+}
+
+func (h *listHandler) GetAll(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	lists, err := h.usecase.GetAll(claims.Id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get all lists successfully",
+		Data:       lists,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *listHandler) GetByID(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	list, err := h.usecase.GetByID(claims.Id, id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get list successfully",
+		Data:       list,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *listHandler) Create(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	var list dto.ListRequest
+	if err := ctx.Bind(&list); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	newList, err := h.usecase.Create(claims.Id, &list)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusCreated,
+		Message:    "Create new list successfully",
+		Data:       newList,
+	})
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+func (h *listHandler) Update(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	var list dto.ListRequest
+	if err := ctx.Bind(&list); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	updated, err := h.usecase.Update(claims.Id, id, &list)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Update list successfully",
+		Data:       updated,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *listHandler) Delete(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	if err := h.usecase.Delete(claims.Id, id); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Delete list successfully",
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *listHandler) GetItems(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	query, err := parseWishlistQuery(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	query.ListID = &id
+	wishlists, meta, err := h.wishlistUsecase.GetAll(claims.Id, query)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get list items successfully",
+		Data:       wishlists,
+		Meta:       meta,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *listHandler) CreateItem(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	var wishlist dto.WishlistRequest
+	if err := ctx.Bind(&wishlist); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	wishlist.ListID = &id
+	newWishlist, err := h.wishlistUsecase.Create(claims.Id, &wishlist)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusCreated,
+		Message:    "Create new wishlist successfully",
+		Data:       newWishlist,
+	})
+	return ctx.JSON(http.StatusCreated, response)
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockListUsecase struct {
+	mock.Mock
+}
+
+func (m *MockListUsecase) GetAll(userID int) ([]*entities.List, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.List), nil
+}
+
+func (m *MockListUsecase) GetByID(userID int, id uint) (*entities.List, error) {
+	args := m.Called(userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.List), nil
+}
+
+func (m *MockListUsecase) Create(userID int, list *dto.ListRequest) (*entities.List, error) {
+	args := m.Called(userID, list)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.List), nil
+}
+
+func (m *MockListUsecase) Update(userID int, id uint, list *dto.ListRequest) (*entities.List, error) {
+	args := m.Called(userID, id, list)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.List), nil
+}
+
+func (m *MockListUsecase) Delete(userID int, id uint) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
+
+func TestListHandler_GetAll(t *testing.T) {
+	mockUsecase := new(MockListUsecase)
+	mockUsecase.On("GetAll", 1).Return([]*entities.List{{ID: 1, UserID: 1, Name: "Birthday"}}, nil)
+
+	handler := NewListHandler(mockUsecase, new(MockWishlistUsecase))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/lists", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.GetAll(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestListHandler_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockListUsecase)
+		mockUsecase.On("Create", 1, &dto.ListRequest{Name: "Birthday"}).Return(&entities.List{ID: 1, UserID: 1, Name: "Birthday"}, nil)
+
+		handler := NewListHandler(mockUsecase, new(MockWishlistUsecase))
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/lists", bytes.NewBufferString(`{"name":"Birthday"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.Create(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid name", func(t *testing.T) {
+		mockUsecase := new(MockListUsecase)
+		mockUsecase.On("Create", 1, mock.Anything).Return(nil, &errorHandler.BadRequestError{Message: "List name must be filled"})
+
+		handler := NewListHandler(mockUsecase, new(MockWishlistUsecase))
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/lists", bytes.NewBufferString(`{"name":""}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		handler.Create(c)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestListHandler_Delete(t *testing.T) {
+	mockUsecase := new(MockListUsecase)
+	mockUsecase.On("Delete", 1, uint(2)).Return(nil)
+
+	handler := NewListHandler(mockUsecase, new(MockWishlistUsecase))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/lists/2", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+	setClaims(c, 1)
+
+	err := handler.Delete(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestListHandler_Items(t *testing.T) {
+	t.Run("GetItems", func(t *testing.T) {
+		mockWishlistUsecase := new(MockWishlistUsecase)
+		mockWishlistUsecase.On("GetAll", 1, mock.MatchedBy(func(q *dto.WishlistQuery) bool {
+			return q.ListID != nil && *q.ListID == 2 && q.Limit == 5
+		})).Return([]*entities.Wishlist{{ID: 1, UserID: 1}}, &dto.PageMeta{Total: 1, Limit: 5}, nil)
+
+		handler := NewListHandler(new(MockListUsecase), mockWishlistUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/lists/2/items?limit=5", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("2")
+		setClaims(c, 1)
+
+		err := handler.GetItems(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockWishlistUsecase.AssertExpectations(t)
+	})
+
+	t.Run("CreateItem", func(t *testing.T) {
+		mockWishlistUsecase := new(MockWishlistUsecase)
+		mockWishlistUsecase.On("Create", 1, mock.MatchedBy(func(req *dto.WishlistRequest) bool {
+			return req.ListID != nil && *req.ListID == 2 && req.Title == "Passport"
+		})).Return(&entities.Wishlist{ID: 1, UserID: 1, Title: "Passport"}, nil)
+
+		handler := NewListHandler(new(MockListUsecase), mockWishlistUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/lists/2/items", bytes.NewBufferString(`{"title":"Passport","list_id":99}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("2")
+		setClaims(c, 1)
+
+		err := handler.CreateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockWishlistUsecase.AssertExpectations(t)
+	})
+}
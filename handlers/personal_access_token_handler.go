@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/usecases"
+	"net/http"
+)
+
+type personalAccessTokenHandler struct {
+	usecase usecases.PersonalAccessTokenUsecase
+}
+
+func NewPersonalAccessTokenHandler(uc usecases.PersonalAccessTokenUsecase) *personalAccessTokenHandler {
+	return &personalAccessTokenHandler{uc}
+}
+
+func (h *personalAccessTokenHandler) GetAll(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	tokens, err := h.usecase.GetAll(claims.Id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get access tokens successfully",
+		Data:       tokens,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *personalAccessTokenHandler) Create(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	var request dto.PersonalAccessTokenRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	token, err := h.usecase.Create(claims.Id, &request)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusCreated,
+		Message:    "Access token created, copy it now as it will not be shown again",
+		Data:       token,
+	})
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+func (h *personalAccessTokenHandler) Revoke(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	if err := h.usecase.Revoke(claims.Id, id); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Revoke access token successfully",
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPersonalAccessTokenUsecase struct {
+	mock.Mock
+}
+
+func (m *MockPersonalAccessTokenUsecase) Create(userID int, request *dto.PersonalAccessTokenRequest) (*dto.PersonalAccessTokenResponse, error) {
+	args := m.Called(userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PersonalAccessTokenResponse), nil
+}
+
+func (m *MockPersonalAccessTokenUsecase) GetAll(userID int) ([]*dto.PersonalAccessTokenResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.PersonalAccessTokenResponse), nil
+}
+
+func (m *MockPersonalAccessTokenUsecase) Revoke(userID int, id uint) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
+
+func (m *MockPersonalAccessTokenUsecase) Authenticate(token string) (*entities.PersonalAccessToken, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PersonalAccessToken), nil
+}
+
+func TestPersonalAccessTokenHandler_Create(t *testing.T) {
+	mockUsecase := new(MockPersonalAccessTokenUsecase)
+	mockUsecase.On("Create", 1, &dto.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{"wishlists:read"}}).
+		Return(&dto.PersonalAccessTokenResponse{ID: 3, Name: "ci", Scopes: []string{"wishlists:read"}, Token: "wlp_token"}, nil)
+
+	handler := NewPersonalAccessTokenHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/me/tokens", bytes.NewBufferString(`{"name":"ci","scopes":["wishlists:read"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.Create(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), "wlp_token")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestPersonalAccessTokenHandler_GetAll(t *testing.T) {
+	mockUsecase := new(MockPersonalAccessTokenUsecase)
+	mockUsecase.On("GetAll", 1).Return([]*dto.PersonalAccessTokenResponse{{ID: 3, Name: "ci", Scopes: []string{"wishlists:read"}}}, nil)
+
+	handler := NewPersonalAccessTokenHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/me/tokens", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.GetAll(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "token\"")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestPersonalAccessTokenHandler_Revoke(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockPersonalAccessTokenUsecase)
+		mockUsecase.On("Revoke", 1, uint(3)).Return(nil)
+
+		handler := NewPersonalAccessTokenHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/me/tokens/3", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("3")
+		setClaims(c, 1)
+
+		err := handler.Revoke(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockUsecase := new(MockPersonalAccessTokenUsecase)
+		mockUsecase.On("Revoke", 1, uint(3)).Return(&errorHandler.NotFoundError{Message: "Access token not found"})
+
+		handler := NewPersonalAccessTokenHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/me/tokens/3", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("3")
+		setClaims(c, 1)
+
+		err := handler.Revoke(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
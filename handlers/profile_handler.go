@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/usecases"
+	"net/http"
+)
+
+type profileHandler struct {
+	usecase usecases.ProfileUsecase
+}
+
+func NewProfileHandler(uc usecases.ProfileUsecase) *profileHandler {
+	return &profileHandler{uc}
+}
+
+func (h *profileHandler) Get(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	user, err := h.usecase.Get(claims.Id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get profile successfully",
+		Data:       user,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *profileHandler) Update(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	var request dto.ProfileRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	user, err := h.usecase.Update(claims.Id, &request)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Update profile successfully",
+		Data:       user,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProfileUsecase struct {
+	mock.Mock
+}
+
+func (m *MockProfileUsecase) Get(userID int) (*dto.UserResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), nil
+}
+
+func (m *MockProfileUsecase) Update(userID int, request *dto.ProfileRequest) (*dto.UserResponse, error) {
+	args := m.Called(userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), nil
+}
+
+func TestProfileHandler_Get(t *testing.T) {
+	mockUsecase := new(MockProfileUsecase)
+	mockUsecase.On("Get", 1).Return(&dto.UserResponse{ID: 1, Email: "admin@example.com", DisplayName: "Admin"}, nil)
+
+	handler := NewProfileHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.Get(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"display_name":"Admin"`)
+	assert.NotContains(t, rec.Body.String(), "password")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestProfileHandler_Update(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		name := "Admin"
+		mockUsecase := new(MockProfileUsecase)
+		mockUsecase.On("Update", 1, &dto.ProfileRequest{DisplayName: &name}).
+			Return(&dto.UserResponse{ID: 1, DisplayName: name}, nil)
+
+		handler := NewProfileHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPatch, "/me", bytes.NewBufferString(`{"display_name":"Admin"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.Update(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid field", func(t *testing.T) {
+		mockUsecase := new(MockProfileUsecase)
+		mockUsecase.On("Update", 1, mock.Anything).Return(nil, &errorHandler.BadRequestError{Message: "Timezone must be an IANA time zone such as Europe/Berlin"})
+
+		handler := NewProfileHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPatch, "/me", bytes.NewBufferString(`{"timezone":"Mars/Olympus"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.Update(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/usecases"
+	"net/http"
+)
+
+type sessionHandler struct {
+	usecase usecases.SessionUsecase
+}
+
+func NewSessionHandler(uc usecases.SessionUsecase) *sessionHandler {
+	return &sessionHandler{uc}
+}
+
+func (h *sessionHandler) GetAll(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	sessions, err := h.usecase.GetAll(claims)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get sessions successfully",
+		Data:       sessions,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *sessionHandler) Revoke(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	if err := h.usecase.Revoke(claims, id); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Revoke session successfully",
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSessionUsecase struct {
+	mock.Mock
+}
+
+func (m *MockSessionUsecase) GetAll(claims *helper.JWTClaims) ([]*dto.SessionResponse, error) {
+	args := m.Called(claims)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dto.SessionResponse), nil
+}
+
+func (m *MockSessionUsecase) Revoke(claims *helper.JWTClaims, id uint) error {
+	args := m.Called(claims, id)
+	return args.Error(0)
+}
+
+func TestSessionHandler_GetAll(t *testing.T) {
+	mockUsecase := new(MockSessionUsecase)
+	mockUsecase.On("GetAll", mock.MatchedBy(func(claims *helper.JWTClaims) bool { return claims.Id == 1 })).
+		Return([]*dto.SessionResponse{{ID: 3, UserAgent: "Firefox", IP: "10.0.0.1", Current: true}}, nil)
+
+	handler := NewSessionHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/me/sessions", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.GetAll(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"user_agent":"Firefox"`)
+	assert.Contains(t, rec.Body.String(), `"current":true`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestSessionHandler_Revoke(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockSessionUsecase)
+		mockUsecase.On("Revoke", mock.Anything, uint(3)).Return(nil)
+
+		handler := NewSessionHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/me/sessions/3", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("3")
+		setClaims(c, 1)
+
+		err := handler.Revoke(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockUsecase := new(MockSessionUsecase)
+		mockUsecase.On("Revoke", mock.Anything, uint(3)).Return(&errorHandler.NotFoundError{Message: "Session not found"})
+
+		handler := NewSessionHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/me/sessions/3", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("3")
+		setClaims(c, 1)
+
+		err := handler.Revoke(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
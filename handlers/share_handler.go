@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/usecases"
+	"net/http"
+)
+
+const (
+	// SharePasswordHeader carries the password of a password protected share
+	// link, so that it does not end up in URLs and access logs.
+	SharePasswordHeader = "X-Share-Password"
+	// ClaimTokenHeader carries the token of a gift claim to change or release
+	// it.
+	ClaimTokenHeader = "X-Claim-Token"
+)
+
+type shareHandler struct {
+	usecase usecases.ShareUsecase
+}
+
+func NewShareHandler(uc usecases.ShareUsecase) *shareHandler {
+	return &shareHandler{uc}
+}
+
+func (h *shareHandler) Get(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	share, err := h.usecase.Get(claims.Id, id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get share link successfully",
+		Data:       share,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *shareHandler) Create(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	var request dto.ListShareRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	share, err := h.usecase.Create(claims.Id, id, &request)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusCreated,
+		Message:    "Create share link successfully, the token is only shown once",
+		Data:       share,
+	})
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+func (h *shareHandler) Regenerate(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	share, err := h.usecase.Regenerate(claims.Id, id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Regenerate share link successfully, the token is only shown once",
+		Data:       share,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *shareHandler) UpdateSettings(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	var request dto.ShareSettingsRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	share, err := h.usecase.UpdateSettings(claims.Id, id, &request)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Update share link successfully",
+		Data:       share,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *shareHandler) Revoke(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	if err := h.usecase.Revoke(claims.Id, id); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Revoke share link successfully",
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// View serves a shared list to anonymous and signed in visitors alike. The
+// response must not be cached and pages linked from it must not learn the
+// token through the Referer header.
+func (h *shareHandler) View(ctx echo.Context) error {
+	query := &dto.SharedListQuery{ShareAccess: *shareAccess(ctx)}
+	err := echo.QueryParamsBinder(ctx).
+		Int("limit", &query.Limit).
+		Int("offset", &query.Offset).
+		BindError()
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: "limit and offset must be integers"})
+	}
+	setSharedHeaders(ctx)
+	list, meta, err := h.usecase.View(ctx.Param("token"), query)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get shared list successfully",
+		Data:       list,
+		Meta:       meta,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// Claim reserves or buys an item of a shared list. The response carries the
+// claim's token, which is only shown once.
+func (h *shareHandler) Claim(ctx echo.Context) error {
+	setSharedHeaders(ctx)
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	var request dto.ClaimRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	claim, err := h.usecase.Claim(ctx.Param("token"), id, shareAccess(ctx), &request)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusCreated,
+		Message:    "Claim gift successfully, the token is only shown once",
+		Data:       claim,
+	})
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+func (h *shareHandler) UpdateClaim(ctx echo.Context) error {
+	setSharedHeaders(ctx)
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	var request dto.ClaimUpdateRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	claimToken := ctx.Request().Header.Get(ClaimTokenHeader)
+	claim, err := h.usecase.UpdateClaim(ctx.Param("token"), id, claimToken, shareAccess(ctx), &request)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Update claim successfully",
+		Data:       claim,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *shareHandler) ReleaseClaim(ctx echo.Context) error {
+	setSharedHeaders(ctx)
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	claimToken := ctx.Request().Header.Get(ClaimTokenHeader)
+	if err := h.usecase.ReleaseClaim(ctx.Param("token"), id, claimToken, shareAccess(ctx)); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Release claim successfully",
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// shareAccess reads the share password, the client IP and, for signed in
+// visitors, who is visiting.
+func shareAccess(ctx echo.Context) *dto.ShareAccess {
+	access := &dto.ShareAccess{Password: ctx.Request().Header.Get(SharePasswordHeader), IP: ctx.RealIP()}
+	if claims, err := helper.GetClaims(ctx); err == nil {
+		access.ViewerID = claims.Id
+	}
+	return access
+}
+
+// setSharedHeaders keeps responses to share link visitors out of caches and
+// the link's token out of the Referer header of linked pages.
+func setSharedHeaders(ctx echo.Context) {
+	ctx.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	ctx.Response().Header().Set("Referrer-Policy", "no-referrer")
+}
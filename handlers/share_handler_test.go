@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"bytes"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockShareUsecase struct {
+	mock.Mock
+}
+
+func (m *MockShareUsecase) Get(userID int, listID uint) (*dto.ListShareResponse, error) {
+	args := m.Called(userID, listID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListShareResponse), nil
+}
+
+func (m *MockShareUsecase) Create(userID int, listID uint, request *dto.ListShareRequest) (*dto.ListShareResponse, error) {
+	args := m.Called(userID, listID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListShareResponse), nil
+}
+
+func (m *MockShareUsecase) Regenerate(userID int, listID uint) (*dto.ListShareResponse, error) {
+	args := m.Called(userID, listID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListShareResponse), nil
+}
+
+func (m *MockShareUsecase) UpdateSettings(userID int, listID uint, request *dto.ShareSettingsRequest) (*dto.ListShareResponse, error) {
+	args := m.Called(userID, listID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListShareResponse), nil
+}
+
+func (m *MockShareUsecase) Revoke(userID int, listID uint) error {
+	args := m.Called(userID, listID)
+	return args.Error(0)
+}
+
+func (m *MockShareUsecase) View(token string, query *dto.SharedListQuery) (*dto.SharedListResponse, *dto.PageMeta, error) {
+	args := m.Called(token, query)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*dto.SharedListResponse), args.Get(1).(*dto.PageMeta), nil
+}
+
+func (m *MockShareUsecase) Claim(token string, wishlistID uint, access *dto.ShareAccess, request *dto.ClaimRequest) (*dto.ClaimResponse, error) {
+	args := m.Called(token, wishlistID, access, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ClaimResponse), nil
+}
+
+func (m *MockShareUsecase) UpdateClaim(token string, claimID uint, claimToken string, access *dto.ShareAccess, request *dto.ClaimUpdateRequest) (*dto.ClaimResponse, error) {
+	args := m.Called(token, claimID, claimToken, access, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ClaimResponse), nil
+}
+
+func (m *MockShareUsecase) ReleaseClaim(token string, claimID uint, claimToken string, access *dto.ShareAccess) error {
+	args := m.Called(token, claimID, claimToken, access)
+	return args.Error(0)
+}
+
+func TestShareHandler_Create(t *testing.T) {
+	mockUsecase := new(MockShareUsecase)
+	mockUsecase.On("Create", 1, uint(2), &dto.ListShareRequest{Password: "family"}).
+		Return(&dto.ListShareResponse{ListID: 2, Token: "token", HasPassword: true}, nil)
+
+	handler := NewShareHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/lists/2/share", bytes.NewBufferString(`{"password":"family"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+	setClaims(c, 1)
+
+	err := handler.Create(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"token":"token"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestShareHandler_View(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockShareUsecase)
+		mockUsecase.On("View", "token", &dto.SharedListQuery{ShareAccess: dto.ShareAccess{Password: "family", IP: "192.0.2.1"}, Limit: 5}).
+			Return(&dto.SharedListResponse{Name: "Birthday"}, &dto.PageMeta{Total: 0, Limit: 5}, nil)
+
+		handler := NewShareHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/shared/token?limit=5", nil)
+		req.Header.Set(SharePasswordHeader, "family")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("token")
+		c.SetParamValues("token")
+
+		err := handler.View(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get(echo.HeaderCacheControl))
+		assert.Equal(t, "no-referrer", rec.Header().Get("Referrer-Policy"))
+		assert.Contains(t, rec.Body.String(), `"name":"Birthday"`)
+	})
+
+	t.Run("Signed in visitor", func(t *testing.T) {
+		mockUsecase := new(MockShareUsecase)
+		mockUsecase.On("View", "token", &dto.SharedListQuery{ShareAccess: dto.ShareAccess{ViewerID: 1, IP: "192.0.2.1"}}).
+			Return(&dto.SharedListResponse{Name: "Birthday", ClaimsHidden: true}, &dto.PageMeta{}, nil)
+
+		handler := NewShareHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/shared/token", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("token")
+		c.SetParamValues("token")
+		setClaims(c, 1)
+
+		err := handler.View(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"claims_hidden":true`)
+	})
+
+	t.Run("Password required", func(t *testing.T) {
+		mockUsecase := new(MockShareUsecase)
+		mockUsecase.On("View", "token", &dto.SharedListQuery{ShareAccess: dto.ShareAccess{IP: "192.0.2.1"}}).
+			Return(nil, nil, &errorHandler.UnAuthorizedError{Message: "This list is password protected"})
+
+		handler := NewShareHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/shared/token", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("token")
+		c.SetParamValues("token")
+
+		err := handler.View(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get(echo.HeaderCacheControl))
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		handler := NewShareHandler(new(MockShareUsecase))
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/shared/token?limit=abc", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.View(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestShareHandler_Claim(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockShareUsecase)
+		mockUsecase.On("Claim", "token", uint(5), &dto.ShareAccess{IP: "192.0.2.1"}, &dto.ClaimRequest{Name: "Grace", Status: "purchased"}).
+			Return(&dto.ClaimResponse{ID: 7, WishlistID: 5, Name: "Grace", Status: "purchased", Quantity: 1, Token: "claim-token"}, nil)
+
+		handler := NewShareHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/shared/token/items/5/claims", bytes.NewBufferString(`{"name":"Grace","status":"purchased"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("token", "id")
+		c.SetParamValues("token", "5")
+
+		err := handler.Claim(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get(echo.HeaderCacheControl))
+		assert.Contains(t, rec.Body.String(), `"token":"claim-token"`)
+	})
+
+	t.Run("Fully claimed", func(t *testing.T) {
+		mockUsecase := new(MockShareUsecase)
+		mockUsecase.On("Claim", "token", uint(5), &dto.ShareAccess{IP: "192.0.2.1"}, &dto.ClaimRequest{Name: "Grace"}).
+			Return(nil, &errorHandler.ConflictError{Message: "Item is already fully claimed"})
+
+		handler := NewShareHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/shared/token/items/5/claims", bytes.NewBufferString(`{"name":"Grace"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("token", "id")
+		c.SetParamValues("token", "5")
+
+		err := handler.Claim(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestShareHandler_ReleaseClaim(t *testing.T) {
+	mockUsecase := new(MockShareUsecase)
+	mockUsecase.On("ReleaseClaim", "token", uint(7), "claim-token", &dto.ShareAccess{Password: "family", IP: "192.0.2.1"}).Return(nil)
+
+	handler := NewShareHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/shared/token/claims/7", nil)
+	req.Header.Set(ClaimTokenHeader, "claim-token")
+	req.Header.Set(SharePasswordHeader, "family")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("token", "id")
+	c.SetParamValues("token", "7")
+
+	err := handler.ReleaseClaim(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/usecases"
+	"net/http"
+)
+
+type tagHandler struct {
+	usecase usecases.TagUsecase
+}
+
+func NewTagHandler(uc usecases.TagUsecase) *tagHandler {
+	return &tagHandler{uc}
+}
+
+func (h *tagHandler) Search(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	var limit int
+	if err := echo.QueryParamsBinder(ctx).Int("limit", &limit).BindError(); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: "limit must be an integer"})
+	}
+	tags, err := h.usecase.Search(claims.Id, ctx.QueryParam("prefix"), limit)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get tags successfully",
+		Data:       tags,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *tagHandler) Rename(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	var tag dto.TagRequest
+	if err := ctx.Bind(&tag); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	renamed, err := h.usecase.Rename(claims.Id, id, &tag)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Rename tag successfully",
+		Data:       renamed,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *tagHandler) Merge(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	var merge dto.TagMergeRequest
+	if err := ctx.Bind(&merge); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	if merge.TargetID == 0 {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: "target_id must be filled"})
+	}
+	target, err := h.usecase.Merge(claims.Id, id, merge.TargetID)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Merge tags successfully",
+		Data:       target,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
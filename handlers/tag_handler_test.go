@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTagUsecase struct {
+	mock.Mock
+}
+
+func (m *MockTagUsecase) Search(userID int, prefix string, limit int) ([]*entities.Tag, error) {
+	args := m.Called(userID, prefix, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Tag), nil
+}
+
+func (m *MockTagUsecase) Rename(userID int, id uint, tag *dto.TagRequest) (*entities.Tag, error) {
+	args := m.Called(userID, id, tag)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Tag), nil
+}
+
+func (m *MockTagUsecase) Merge(userID int, sourceID, targetID uint) (*entities.Tag, error) {
+	args := m.Called(userID, sourceID, targetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Tag), nil
+}
+
+func TestTagHandler_Search(t *testing.T) {
+	mockUsecase := new(MockTagUsecase)
+	mockUsecase.On("Search", 1, "te", 5).Return([]*entities.Tag{{ID: 1, UserID: 1, Name: "tech"}}, nil)
+
+	handler := NewTagHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/tags?prefix=te&limit=5", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.Search(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestTagHandler_Rename(t *testing.T) {
+	mockUsecase := new(MockTagUsecase)
+	mockUsecase.On("Rename", 1, uint(1), &dto.TagRequest{Name: "gadgets"}).Return(&entities.Tag{ID: 1, UserID: 1, Name: "gadgets"}, nil)
+
+	handler := NewTagHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPatch, "/tags/1", bytes.NewBufferString(`{"name":"gadgets"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	setClaims(c, 1)
+
+	err := handler.Rename(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestTagHandler_Merge(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockTagUsecase)
+		mockUsecase.On("Merge", 1, uint(1), uint(2)).Return(&entities.Tag{ID: 2, UserID: 1, Name: "gadgets"}, nil)
+
+		handler := NewTagHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/tags/1/merge", bytes.NewBufferString(`{"target_id":2}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		setClaims(c, 1)
+
+		err := handler.Merge(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Missing target", func(t *testing.T) {
+		mockUsecase := new(MockTagUsecase)
+		handler := NewTagHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/tags/1/merge", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		setClaims(c, 1)
+
+		handler.Merge(c)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUsecase.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
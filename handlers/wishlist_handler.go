@@ -7,6 +7,9 @@ import (
 	"go-wishlist-api-2/helper"
 	"go-wishlist-api-2/usecases"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type wishlistHandler struct {
@@ -18,9 +21,17 @@ func NewWishlistHandler(uc usecases.WishlistUsecase) *wishlistHandler {
 }
 
 func (h *wishlistHandler) GetAll(ctx echo.Context) error {
-	wishlists, err := h.usecase.GetAll()
+	claims, err := helper.GetClaims(ctx)
 	if err != nil {
-		return errorHandler.HandleError(ctx, &errorHandler.InternalServerError{Message: err.Error()})
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	query, err := parseWishlistQuery(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	wishlists, meta, err := h.usecase.GetAll(claims.Id, query)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
 	}
 	if len(wishlists) < 1 {
 		response := helper.Response(dto.ResponseParam{
@@ -28,6 +39,7 @@ func (h *wishlistHandler) GetAll(ctx echo.Context) error {
 			StatusCode: http.StatusOK,
 			Message:    "Wishlists are empty",
 			Data:       wishlists,
+			Meta:       meta,
 		})
 		return ctx.JSON(http.StatusOK, response)
 	}
@@ -36,16 +48,43 @@ func (h *wishlistHandler) GetAll(ctx echo.Context) error {
 		StatusCode: http.StatusOK,
 		Message:    "Get all wishlists succcessfully",
 		Data:       wishlists,
+		Meta:       meta,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *wishlistHandler) GetByID(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	wishlist, err := h.usecase.GetByID(claims.Id, id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get wishlist successfully",
+		Data:       wishlist,
 	})
 	return ctx.JSON(http.StatusOK, response)
 }
 
 func (h *wishlistHandler) Create(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
 	var wishlist dto.WishlistRequest
 	if err := ctx.Bind(&wishlist); err != nil {
 		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
 	}
-	newWishlist, err := h.usecase.Create(&wishlist)
+	newWishlist, err := h.usecase.Create(claims.Id, &wishlist)
 	if err != nil {
 		return errorHandler.HandleError(ctx, err)
 	}
@@ -57,3 +96,242 @@ func (h *wishlistHandler) Create(ctx echo.Context) error {
 	})
 	return ctx.JSON(http.StatusCreated, response)
 }
+
+func (h *wishlistHandler) Update(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	var wishlist dto.WishlistRequest
+	if err := ctx.Bind(&wishlist); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	updated, err := h.usecase.Update(claims.Id, id, &wishlist)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Update wishlist successfully",
+		Data:       updated,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *wishlistHandler) Patch(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	var wishlist dto.WishlistPatchRequest
+	if err := ctx.Bind(&wishlist); err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.BadRequestError{Message: err.Error()})
+	}
+	updated, err := h.usecase.Patch(claims.Id, id, &wishlist)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Update wishlist successfully",
+		Data:       updated,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *wishlistHandler) Delete(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	if err := h.usecase.Delete(claims.Id, id); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Delete wishlist successfully",
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *wishlistHandler) GetTrash(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	wishlists, err := h.usecase.GetTrash(claims.Id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get trash successfully",
+		Data:       wishlists,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *wishlistHandler) Restore(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	wishlist, err := h.usecase.Restore(claims.Id, id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Restore wishlist successfully",
+		Data:       wishlist,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *wishlistHandler) History(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	events, err := h.usecase.History(claims.Id, id)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Get wishlist history successfully",
+		Data:       events,
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *wishlistHandler) Purge(ctx echo.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+	}
+	id, err := parseID(ctx)
+	if err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	if err := h.usecase.Purge(claims.Id, id); err != nil {
+		return errorHandler.HandleError(ctx, err)
+	}
+	response := helper.Response(dto.ResponseParam{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Purge wishlist successfully",
+	})
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func parseID(ctx echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		return 0, &errorHandler.BadRequestError{Message: "Invalid id"}
+	}
+	return uint(id), nil
+}
+
+// parseWishlistQuery reads the pagination, filter and sort query parameters
+// of GET /wishlists. Dates accept either RFC 3339 or YYYY-MM-DD; a bare date
+// used as an upper bound covers the whole day.
+func parseWishlistQuery(ctx echo.Context) (*dto.WishlistQuery, error) {
+	query := &dto.WishlistQuery{
+		Cursor:  ctx.QueryParam("cursor"),
+		Sort:    ctx.QueryParam("sort"),
+		Title:   ctx.QueryParam("title"),
+		TagMode: ctx.QueryParam("tag_mode"),
+	}
+	// Tags may be repeated (?tag=a&tag=b) or comma separated (?tag=a,b).
+	for _, value := range ctx.QueryParams()["tag"] {
+		for _, tag := range strings.Split(value, ",") {
+			if strings.TrimSpace(tag) != "" {
+				query.Tags = append(query.Tags, tag)
+			}
+		}
+	}
+	err := echo.QueryParamsBinder(ctx).
+		Int("limit", &query.Limit).
+		Int("offset", &query.Offset).
+		BindError()
+	if err != nil {
+		return nil, &errorHandler.BadRequestError{Message: "limit and offset must be integers"}
+	}
+
+	if raw := ctx.QueryParam("list_id"); raw != "" {
+		listID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || listID == 0 {
+			return nil, &errorHandler.BadRequestError{Message: "list_id must be a positive integer"}
+		}
+		id := uint(listID)
+		query.ListID = &id
+	}
+
+	if raw := ctx.QueryParam("is_achieved"); raw != "" {
+		achieved, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, &errorHandler.BadRequestError{Message: "is_achieved must be a boolean"}
+		}
+		query.IsAchieved = &achieved
+	}
+
+	dates := map[string]**time.Time{
+		"created_from": &query.CreatedFrom,
+		"created_to":   &query.CreatedTo,
+		"updated_from": &query.UpdatedFrom,
+		"updated_to":   &query.UpdatedTo,
+	}
+	for name, dest := range dates {
+		raw := ctx.QueryParam(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := parseDate(raw, strings.HasSuffix(name, "_to"))
+		if err != nil {
+			return nil, &errorHandler.BadRequestError{Message: name + " must be a RFC 3339 timestamp or YYYY-MM-DD date"}
+		}
+		*dest = &parsed
+	}
+	return query, nil
+}
+
+func parseDate(raw string, endOfDay bool) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, raw); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		parsed = parsed.Add(24*time.Hour - time.Nanosecond)
+	}
+	return parsed, nil
+}
@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"go-wishlist-api-2/dto"
 	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -19,22 +22,89 @@ type MockWishlistUsecase struct {
 	mock.Mock
 }
 
-func (m *MockWishlistUsecase) GetAll() ([]*entities.Wishlist, error) {
-	args := m.Called()
+func (m *MockWishlistUsecase) GetAll(userID int, query *dto.WishlistQuery) ([]*entities.Wishlist, *dto.PageMeta, error) {
+	args := m.Called(userID, query)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*entities.Wishlist), args.Get(1).(*dto.PageMeta), nil
+}
+
+func (m *MockWishlistUsecase) GetByID(userID int, id uint) (*entities.Wishlist, error) {
+	args := m.Called(userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Wishlist), nil
+}
+
+func (m *MockWishlistUsecase) Create(userID int, wishlist *dto.WishlistRequest) (*entities.Wishlist, error) {
+	args := m.Called(userID, wishlist)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Wishlist), nil
+}
+
+func (m *MockWishlistUsecase) Update(userID int, id uint, wishlist *dto.WishlistRequest) (*entities.Wishlist, error) {
+	args := m.Called(userID, id, wishlist)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Wishlist), nil
+}
+
+func (m *MockWishlistUsecase) Patch(userID int, id uint, wishlist *dto.WishlistPatchRequest) (*entities.Wishlist, error) {
+	args := m.Called(userID, id, wishlist)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Wishlist), nil
+}
+
+func (m *MockWishlistUsecase) Delete(userID int, id uint) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
+
+func (m *MockWishlistUsecase) GetTrash(userID int) ([]*entities.Wishlist, error) {
+	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*entities.Wishlist), nil
 }
 
-func (m *MockWishlistUsecase) Create(wishlist *dto.WishlistRequest) (*entities.Wishlist, error) {
-	args := m.Called(wishlist)
+func (m *MockWishlistUsecase) Restore(userID int, id uint) (*entities.Wishlist, error) {
+	args := m.Called(userID, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*entities.Wishlist), nil
 }
 
+func (m *MockWishlistUsecase) Purge(userID int, id uint) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
+
+func (m *MockWishlistUsecase) PurgeExpired(retention time.Duration) (int64, error) {
+	args := m.Called(retention)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWishlistUsecase) History(userID int, id uint) ([]*entities.AchievementEvent, error) {
+	args := m.Called(userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.AchievementEvent), nil
+}
+
+func setClaims(c echo.Context, userID int) {
+	c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"Id": float64(userID), "Email": "test@example.com"}})
+}
+
 func TestWishlistHandler_GetAll(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockWishlists := []*entities.Wishlist{
@@ -43,7 +113,7 @@ func TestWishlistHandler_GetAll(t *testing.T) {
 		}
 
 		mockUsecase := new(MockWishlistUsecase)
-		mockUsecase.On("GetAll").Return(mockWishlists, nil)
+		mockUsecase.On("GetAll", 1, &dto.WishlistQuery{}).Return(mockWishlists, &dto.PageMeta{Total: 2, Limit: 20}, nil)
 
 		handler := NewWishlistHandler(mockUsecase)
 
@@ -51,6 +121,7 @@ func TestWishlistHandler_GetAll(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/wishlists", nil)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		setClaims(c, 1)
 
 		err := handler.GetAll(c)
 
@@ -68,7 +139,7 @@ func TestWishlistHandler_GetAll(t *testing.T) {
 
 	t.Run("Failed", func(t *testing.T) {
 		mockUsecase := new(MockWishlistUsecase)
-		mockUsecase.On("GetAll").Return(nil, fmt.Errorf("error"))
+		mockUsecase.On("GetAll", 1, mock.Anything).Return(nil, nil, fmt.Errorf("error"))
 
 		handler := NewWishlistHandler(mockUsecase)
 
@@ -76,6 +147,7 @@ func TestWishlistHandler_GetAll(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/wishlists", nil)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		setClaims(c, 1)
 
 		handler.GetAll(c)
 
@@ -91,6 +163,84 @@ func TestWishlistHandler_GetAll(t *testing.T) {
 	})
 }
 
+func TestWishlistHandler_GetAllQuery(t *testing.T) {
+	t.Run("Parses filters", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("GetAll", 1, mock.MatchedBy(func(q *dto.WishlistQuery) bool {
+			return q.Limit == 10 && q.Cursor == "abc" && q.Sort == "-created_at,title" &&
+				q.IsAchieved != nil && !*q.IsAchieved && q.Title == "book" &&
+				q.CreatedFrom.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) &&
+				q.CreatedTo.Equal(time.Date(2024, 1, 31, 23, 59, 59, 999999999, time.UTC))
+		})).Return([]*entities.Wishlist{}, &dto.PageMeta{Limit: 10}, nil)
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/wishlists?limit=10&cursor=abc&sort=-created_at,title&is_achieved=false&title=book&created_from=2024-01-01&created_to=2024-01-31", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.GetAll(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"Meta":{"total":0,"limit":10,"offset":0}`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Parses tags", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("GetAll", 1, mock.MatchedBy(func(q *dto.WishlistQuery) bool {
+			return assert.ObjectsAreEqual([]string{"tech", "books", "under-50"}, q.Tags) && q.TagMode == "or"
+		})).Return([]*entities.Wishlist{}, &dto.PageMeta{Limit: 20}, nil)
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/wishlists?tag=tech,books&tag=under-50&tag_mode=or", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.GetAll(c)
+
+		assert.NoError(t, err)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid boolean", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/wishlists?is_achieved=maybe", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		handler.GetAll(c)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUsecase.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid date", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/wishlists?updated_from=yesterday", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		handler.GetAll(c)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
 func TestWishlistHandler_Create(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockWishlistRequest := &dto.WishlistRequest{Title: "New Wishlist"}
@@ -101,7 +251,7 @@ func TestWishlistHandler_Create(t *testing.T) {
 		}
 
 		mockUsecase := new(MockWishlistUsecase)
-		mockUsecase.On("Create", mock.Anything).Return(mockWishlist, nil)
+		mockUsecase.On("Create", 1, mock.Anything).Return(mockWishlist, nil)
 
 		handler := NewWishlistHandler(mockUsecase)
 
@@ -111,6 +261,7 @@ func TestWishlistHandler_Create(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		setClaims(c, 1)
 
 		err := handler.Create(c)
 
@@ -126,11 +277,77 @@ func TestWishlistHandler_Create(t *testing.T) {
 		assert.NotNil(t, response.Data)
 	})
 
+	t.Run("Binds item details", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("Create", 1, mock.MatchedBy(func(req *dto.WishlistRequest) bool {
+			return *req.Price == 1999 && req.Currency == "USD" && req.URL == "https://example.com/p/1" &&
+				req.Priority == "high" && req.Quantity == 2 && req.Notes == "blue" &&
+				req.TargetDate.Equal(time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC))
+		})).Return(&entities.Wishlist{ID: 1, UserID: 1, Title: "Headphones"}, nil)
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		body := `{"title":"Headphones","price":1999,"currency":"USD","url":"https://example.com/p/1","priority":"high","quantity":2,"notes":"blue","target_date":"2024-12-25T00:00:00Z"}`
+		req := httptest.NewRequest(http.MethodPost, "/wishlists", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.Create(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Binds a plain target date", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("Create", 1, mock.MatchedBy(func(req *dto.WishlistRequest) bool {
+			return req.TargetDate.Equal(time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC))
+		})).Return(&entities.Wishlist{ID: 1, UserID: 1, Title: "Headphones"}, nil)
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/wishlists", bytes.NewBufferString(`{"title":"Headphones","target_date":"2024-12-25"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.Create(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid target date", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/wishlists", bytes.NewBufferString(`{"title":"Headphones","target_date":"25/12/2024"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setClaims(c, 1)
+
+		err := handler.Create(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "YYYY-MM-DD")
+		mockUsecase.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
 	t.Run("Failed", func(t *testing.T) {
 		mockWishlistRequest := &dto.WishlistRequest{Title: "New Wishlist"}
 
 		mockUsecase := new(MockWishlistUsecase)
-		mockUsecase.On("Create", mock.Anything).Return(nil, fmt.Errorf("error"))
+		mockUsecase.On("Create", 1, mock.Anything).Return(nil, fmt.Errorf("error"))
 
 		handler := NewWishlistHandler(mockUsecase)
 
@@ -140,6 +357,7 @@ func TestWishlistHandler_Create(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		setClaims(c, 1)
 
 		handler.Create(c)
 
@@ -154,3 +372,303 @@ func TestWishlistHandler_Create(t *testing.T) {
 		mockUsecase.AssertExpectations(t)
 	})
 }
+
+func TestWishlistHandler_GetByID(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockWishlist := &entities.Wishlist{ID: 1, UserID: 1, Title: "Wishlist 1"}
+
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("GetByID", 1, uint(1)).Return(mockWishlist, nil)
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/wishlists/1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		setClaims(c, 1)
+
+		err := handler.GetByID(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("GetByID", 2, uint(1)).Return(nil, &errorHandler.ForbiddenError{Message: "forbidden"})
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/wishlists/1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		setClaims(c, 2)
+
+		handler.GetByID(c)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/wishlists/abc", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("abc")
+		setClaims(c, 1)
+
+		handler.GetByID(c)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUsecase.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestWishlistHandler_MissingClaims(t *testing.T) {
+	mockUsecase := new(MockWishlistUsecase)
+	handler := NewWishlistHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/wishlists", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handler.GetAll(c)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockUsecase.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
+}
+
+func TestWishlistHandler_Update(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("Update", 1, uint(1), &dto.WishlistRequest{Title: "Updated", IsAchieved: true}).
+			Return(&entities.Wishlist{ID: 1, UserID: 1, Title: "Updated", IsAchieved: true}, nil)
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/wishlists/1", bytes.NewBufferString(`{"title":"Updated","is_achieved":true}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		setClaims(c, 1)
+
+		err := handler.Update(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("Update", 1, uint(9), mock.Anything).Return(nil, &errorHandler.NotFoundError{Message: "Wishlist not found"})
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/wishlists/9", bytes.NewBufferString(`{"title":"Updated"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("9")
+		setClaims(c, 1)
+
+		handler.Update(c)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestWishlistHandler_Patch(t *testing.T) {
+	mockUsecase := new(MockWishlistUsecase)
+	mockUsecase.On("Patch", 1, uint(1), mock.MatchedBy(func(req *dto.WishlistPatchRequest) bool {
+		return req.Title == nil && req.IsAchieved != nil && *req.IsAchieved
+	})).Return(&entities.Wishlist{ID: 1, UserID: 1, Title: "Wishlist 1", IsAchieved: true}, nil)
+
+	handler := NewWishlistHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPatch, "/wishlists/1", bytes.NewBufferString(`{"is_achieved":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	setClaims(c, 1)
+
+	err := handler.Patch(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestWishlistHandler_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("Delete", 1, uint(1)).Return(nil)
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/wishlists/1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		setClaims(c, 1)
+
+		err := handler.Delete(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response dto.ResponseParam
+		_ = json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.True(t, response.Status)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("Delete", 2, uint(1)).Return(&errorHandler.ForbiddenError{Message: "forbidden"})
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/wishlists/1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		setClaims(c, 2)
+
+		handler.Delete(c)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestWishlistHandler_GetTrash(t *testing.T) {
+	mockUsecase := new(MockWishlistUsecase)
+	mockUsecase.On("GetTrash", 1).Return([]*entities.Wishlist{{ID: 1, UserID: 1, Title: "Wishlist 1"}}, nil)
+
+	handler := NewWishlistHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/wishlists/trash", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setClaims(c, 1)
+
+	err := handler.GetTrash(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestWishlistHandler_Restore(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("Restore", 1, uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1}, nil)
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/wishlists/1/restore", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		setClaims(c, 1)
+
+		err := handler.Restore(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("Not in trash", func(t *testing.T) {
+		mockUsecase := new(MockWishlistUsecase)
+		mockUsecase.On("Restore", 1, uint(1)).Return(nil, &errorHandler.NotFoundError{Message: "Wishlist not found in trash"})
+
+		handler := NewWishlistHandler(mockUsecase)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/wishlists/1/restore", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		setClaims(c, 1)
+
+		handler.Restore(c)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestWishlistHandler_History(t *testing.T) {
+	mockUsecase := new(MockWishlistUsecase)
+	mockUsecase.On("History", 1, uint(1)).Return([]*entities.AchievementEvent{{ID: 1, WishlistID: 1, UserID: 1, Achieved: true}}, nil)
+
+	handler := NewWishlistHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/wishlists/1/history", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	setClaims(c, 1)
+
+	err := handler.History(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"Achieved":true`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestWishlistHandler_Purge(t *testing.T) {
+	mockUsecase := new(MockWishlistUsecase)
+	mockUsecase.On("Purge", 1, uint(1)).Return(nil)
+
+	handler := NewWishlistHandler(mockUsecase)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/wishlists/trash/1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	setClaims(c, 1)
+
+	err := handler.Purge(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
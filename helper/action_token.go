@@ -0,0 +1,83 @@
+package helper
+
+import (
+	"errors"
+	"github.com/golang-jwt/jwt"
+	"go-wishlist-api-2/entities"
+	"strconv"
+	"time"
+)
+
+// Purposes of action tokens. A token is only accepted for the purpose it was
+// issued for.
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeChangeEmail   = "change_email"
+	PurposeMFA           = "mfa"
+	PurposeDeleteAccount = "delete_account"
+)
+
+var ErrInvalidActionToken = errors.New("invalid or expired token")
+
+// ActionClaims are the claims of a signed, single purpose token sent to the
+// user by email. They deliberately carry no Id claim so that they can never
+// be used as access tokens.
+type ActionClaims struct {
+	Purpose string `json:"purpose"`
+	Email   string `json:"email"`
+	jwt.StandardClaims
+}
+
+// GenerateActionToken signs a token for purpose that is bound to the user's
+// current email address and expires after ttl.
+func GenerateActionToken(user *entities.User, purpose string, ttl time.Duration) (string, error) {
+	return generateActionToken(user.Id, user.Email, purpose, ttl)
+}
+
+// GenerateEmailChangeToken signs a PurposeChangeEmail token that confirms the
+// user owns the new address email.
+func GenerateEmailChangeToken(user *entities.User, email string, ttl time.Duration) (string, error) {
+	return generateActionToken(user.Id, email, PurposeChangeEmail, ttl)
+}
+
+func generateActionToken(userID int, email, purpose string, ttl time.Duration) (string, error) {
+	key := currentSigningKey()
+	now := time.Now()
+	claims := ActionClaims{
+		Purpose: purpose,
+		Email:   email,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   strconv.Itoa(userID),
+			ExpiresAt: now.Add(ttl).Unix(),
+			IssuedAt:  now.Unix(),
+		},
+	}
+	token := jwt.NewWithClaims(signingMethod(key.Algorithm), claims)
+	if key.KID != "" {
+		token.Header["kid"] = key.KID
+	}
+	return token.SignedString(key.PrivateKey)
+}
+
+// ParseActionToken verifies token and returns its claims if it was issued for
+// purpose and has not expired.
+func ParseActionToken(token, purpose string) (*ActionClaims, error) {
+	var claims ActionClaims
+	parsed, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return verificationKey(kid, token.Method.Alg())
+	})
+	if err != nil || !parsed.Valid || claims.Purpose != purpose {
+		return nil, ErrInvalidActionToken
+	}
+	return &claims, nil
+}
+
+// UserID returns the id of the user the token was issued to.
+func (c *ActionClaims) UserID() (int, error) {
+	id, err := strconv.Atoi(c.Subject)
+	if err != nil {
+		return 0, ErrInvalidActionToken
+	}
+	return id, nil
+}
@@ -0,0 +1,53 @@
+package helper
+
+import (
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/entities"
+	"testing"
+	"time"
+)
+
+func TestActionToken(t *testing.T) {
+	viper.Set("SECRET_TOKEN", "secret")
+	user := &entities.User{Id: 7, Email: "admin@example.com"}
+
+	t.Run("Success", func(t *testing.T) {
+		token, err := GenerateActionToken(user, PurposeVerifyEmail, time.Hour)
+		assert.NoError(t, err)
+		claims, err := ParseActionToken(token, PurposeVerifyEmail)
+		assert.NoError(t, err)
+		assert.Equal(t, "admin@example.com", claims.Email)
+		id, err := claims.UserID()
+		assert.NoError(t, err)
+		assert.Equal(t, 7, id)
+	})
+
+	t.Run("Email change", func(t *testing.T) {
+		token, err := GenerateEmailChangeToken(user, "new@example.com", time.Hour)
+		assert.NoError(t, err)
+		claims, err := ParseActionToken(token, PurposeChangeEmail)
+		assert.NoError(t, err)
+		assert.Equal(t, "new@example.com", claims.Email)
+		_, err = ParseActionToken(token, PurposeVerifyEmail)
+		assert.ErrorIs(t, err, ErrInvalidActionToken)
+	})
+
+	t.Run("Wrong purpose", func(t *testing.T) {
+		token, _ := GenerateActionToken(user, PurposeVerifyEmail, time.Hour)
+		_, err := ParseActionToken(token, "reset_password")
+		assert.ErrorIs(t, err, ErrInvalidActionToken)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		token, _ := GenerateActionToken(user, PurposeVerifyEmail, -time.Minute)
+		_, err := ParseActionToken(token, PurposeVerifyEmail)
+		assert.ErrorIs(t, err, ErrInvalidActionToken)
+	})
+
+	t.Run("Access token", func(t *testing.T) {
+		token, _ := GenerateToken(user, 0)
+		_, err := ParseActionToken(token.Token, PurposeVerifyEmail)
+		assert.ErrorIs(t, err, ErrInvalidActionToken)
+	})
+}
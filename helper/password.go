@@ -1,16 +1,177 @@
 package helper
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrPasswordMismatch      = errors.New("password does not match")
+	ErrUnknownPasswordFormat = errors.New("unknown password hash format")
+)
+
+// PasswordHasher hashes new passwords. Hashes are self-describing, so
+// VerifyPassword checks any supported format regardless of the configured
+// hasher.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// NeedsRehash reports whether encoded was made with another algorithm
+	// or other parameters than the hasher currently uses.
+	NeedsRehash(encoded string) bool
+}
+
+var (
+	hasherMu       sync.RWMutex
+	passwordHasher PasswordHasher = BcryptHasher{Cost: bcrypt.DefaultCost}
+)
+
+// UsePasswordHasher sets the hasher used for new passwords.
+func UsePasswordHasher(hasher PasswordHasher) {
+	hasherMu.Lock()
+	defer hasherMu.Unlock()
+	passwordHasher = hasher
+}
+
+func currentPasswordHasher() PasswordHasher {
+	hasherMu.RLock()
+	defer hasherMu.RUnlock()
+	return passwordHasher
+}
 
 func HashPassword(password string) (string, error) {
-	hashPass, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return currentPasswordHasher().Hash(password)
+}
+
+// PasswordNeedsRehash reports whether hashPassword should be replaced by a
+// hash of the configured hasher the next time the password is known.
+func PasswordNeedsRehash(hashPassword string) bool {
+	return currentPasswordHasher().NeedsRehash(hashPassword)
+}
+
+func VerifyPassword(reqPass, hashPassword string) error {
+	switch {
+	case strings.HasPrefix(hashPassword, argon2idPrefix):
+		return verifyArgon2id(reqPass, hashPassword)
+	case isBcryptHash(hashPassword):
+		if err := bcrypt.CompareHashAndPassword([]byte(hashPassword), []byte(reqPass)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return ErrPasswordMismatch
+			}
+			return err
+		}
+		return nil
+	default:
+		return ErrUnknownPasswordFormat
+	}
+}
+
+// BcryptHasher hashes passwords with bcrypt at Cost.
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hashPass, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
 	if err != nil {
 		return "", err
 	}
 	return string(hashPass), nil
 }
 
-func VerifyPassword(reqPass, hashPassword string) error {
-	err := bcrypt.CompareHashAndPassword([]byte(hashPassword), []byte(reqPass))
-	return err
+func (h BcryptHasher) NeedsRehash(encoded string) bool {
+	if !isBcryptHash(encoded) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.Cost
+}
+
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// Argon2idHasher hashes passwords with argon2id. Memory is in KiB. Hashes are
+// encoded in the PHC string format, e.g.
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>.
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+const argon2idPrefix = "$argon2id$"
+
+var DefaultArgon2idHasher = Argon2idHasher{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt, err := randomBytes(int(h.SaltLength))
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory != h.Memory || params.Iterations != h.Iterations || params.Parallelism != h.Parallelism ||
+		uint32(len(salt)) != h.SaltLength || uint32(len(key)) != h.KeyLength
+}
+
+func verifyArgon2id(password, encoded string) error {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+func decodeArgon2id(encoded string) (Argon2idHasher, []byte, []byte, error) {
+	var params Argon2idHasher
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownPasswordFormat
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownPasswordFormat
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrUnknownPasswordFormat
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownPasswordFormat
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnknownPasswordFormat
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
 }
@@ -1,29 +1,55 @@
 package helper
 
 import (
+	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
-func TestHashPassword(t *testing.T) {
-	password := "admin123"
-	hashPassword, err := HashPassword(password)
-	if err != nil {
-		t.Errorf("Error Hashing Password: %v", err)
+func TestPasswordHashers(t *testing.T) {
+	fastArgon2id := Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	hashers := map[string]PasswordHasher{
+		"bcrypt":   BcryptHasher{Cost: 4},
+		"argon2id": fastArgon2id,
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(hashPassword), []byte(password)); err != nil {
-		t.Errorf("Hashed Password not valid: %v", err)
+	for name, hasher := range hashers {
+		t.Run(name, func(t *testing.T) {
+			hash, err := hasher.Hash("s3cret password")
+			assert.NoError(t, err)
+			assert.NoError(t, VerifyPassword("s3cret password", hash))
+			assert.ErrorIs(t, VerifyPassword("wrong", hash), ErrPasswordMismatch)
+			assert.False(t, hasher.NeedsRehash(hash))
+		})
 	}
 
-}
+	t.Run("Argon2id encoding", func(t *testing.T) {
+		hash, _ := fastArgon2id.Hash("password")
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	})
 
-func TestVerifyPassword(t *testing.T) {
-	password := "admin123"
+	t.Run("Needs rehash", func(t *testing.T) {
+		bcryptHash, _ := BcryptHasher{Cost: 4}.Hash("password")
+		argonHash, _ := fastArgon2id.Hash("password")
 
-	hashPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		assert.True(t, BcryptHasher{Cost: 5}.NeedsRehash(bcryptHash))
+		assert.True(t, BcryptHasher{Cost: 4}.NeedsRehash(argonHash))
+		assert.True(t, fastArgon2id.NeedsRehash(bcryptHash))
+		stronger := fastArgon2id
+		stronger.Iterations = 2
+		assert.True(t, stronger.NeedsRehash(argonHash))
+	})
 
-	if err := VerifyPassword(password, string(hashPassword)); err != nil {
-		t.Errorf("Failed to verify password: %v", err)
-	}
+	t.Run("Unknown format", func(t *testing.T) {
+		assert.ErrorIs(t, VerifyPassword("password", "plain"), ErrUnknownPasswordFormat)
+		assert.ErrorIs(t, VerifyPassword("password", "$argon2id$v=19$broken"), ErrUnknownPasswordFormat)
+	})
+
+	t.Run("Configured hasher", func(t *testing.T) {
+		UsePasswordHasher(fastArgon2id)
+		defer UsePasswordHasher(BcryptHasher{Cost: 10})
+		hash, err := HashPassword("password")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, argon2idPrefix))
+		assert.False(t, PasswordNeedsRehash(hash))
+	})
 }
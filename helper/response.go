@@ -7,14 +7,19 @@ type ResponseWithData struct {
 	Code    int
 	Message string
 	Data    any
+	Meta    any `json:",omitempty"`
 }
 
 type ResponseWithError struct {
 	Status  bool
 	Code    int
 	Message string
+	Errors  []dto.FieldError `json:",omitempty"`
 }
 
+// Response builds the response body for param. Status is derived from
+// StatusCode for both shapes: a 2xx without data, e.g. after a delete,
+// reports success just like one with data.
 func Response(param dto.ResponseParam) any {
 	var status bool
 	var response any
@@ -31,12 +36,14 @@ func Response(param dto.ResponseParam) any {
 			Code:    param.StatusCode,
 			Message: param.Message,
 			Data:    param.Data,
+			Meta:    param.Meta,
 		}
 	} else {
 		response = ResponseWithError{
-			Status:  false,
+			Status:  status,
 			Code:    param.StatusCode,
 			Message: param.Message,
+			Errors:  param.Errors,
 		}
 	}
 	return response
@@ -1,9 +1,11 @@
 package helper
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"go-wishlist-api-2/dto"
 	"net/http"
+	"strconv"
 	"testing"
 )
 
@@ -36,4 +38,39 @@ func TestResponse(t *testing.T) {
 		assert.Equal(t, param.StatusCode, response.Code)
 		assert.Equal(t, param.Message, response.Message)
 	})
+	t.Run("Success without data", func(t *testing.T) {
+		for _, code := range []int{http.StatusOK, http.StatusAccepted} {
+			param := dto.ResponseParam{
+				StatusCode: code,
+				Message:    "Delete Data Success",
+			}
+			response := Response(param).(ResponseWithError)
+			assert.True(t, response.Status)
+			assert.Equal(t, param.StatusCode, response.Code)
+
+			body, err := json.Marshal(response)
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"Status":true,"Code":`+strconv.Itoa(code)+`,"Message":"Delete Data Success"}`, string(body))
+		}
+	})
+	t.Run("Failure without data ignores Status", func(t *testing.T) {
+		param := dto.ResponseParam{
+			Status:     true,
+			StatusCode: http.StatusNotFound,
+			Message:    "Data not found",
+		}
+		response := Response(param).(ResponseWithError)
+		assert.False(t, response.Status)
+	})
+}
+
+func TestResponseWithMeta(t *testing.T) {
+	param := dto.ResponseParam{
+		StatusCode: http.StatusOK,
+		Message:    "Get Data Success",
+		Data:       []string{"Data"},
+		Meta:       dto.PageMeta{Total: 1, Limit: 20},
+	}
+	response := Response(param).(ResponseWithData)
+	assert.Equal(t, param.Meta, response.Meta)
 }
@@ -0,0 +1,316 @@
+package helper
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+	"go-wishlist-api-2/dto"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	rsaKeyBits = 2048
+)
+
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+// SigningKey is one key of the key set. For HS256 the private and public key
+// are both the shared secret.
+type SigningKey struct {
+	KID        string
+	Algorithm  string
+	PrivateKey any
+	PublicKey  any
+	CreatedAt  time.Time
+}
+
+// KeyManager loads the signing keys from Dir and, when RotationInterval is
+// set, generates a new key once the newest one is older than that interval.
+// Every key file is named <kid>.pem and holds a PKCS#8 private key. Keys stay
+// valid for verification until they are deleted; rotation deletes keys that
+// were superseded for longer than MaxKeyAge.
+type KeyManager struct {
+	Algorithm        string
+	Dir              string
+	RotationInterval time.Duration
+	MaxKeyAge        time.Duration
+}
+
+var (
+	keysMu sync.RWMutex
+	// signingKeys is sorted newest first; nil means HS256 with SECRET_TOKEN.
+	signingKeys []*SigningKey
+)
+
+// Load reads the key set from disk and makes it the active one.
+func (m *KeyManager) Load() error {
+	if m.Algorithm == "" || m.Algorithm == AlgorithmHS256 {
+		UseSigningKeys(nil)
+		return nil
+	}
+	if m.Algorithm != AlgorithmRS256 && m.Algorithm != AlgorithmEdDSA {
+		return fmt.Errorf("unsupported JWT algorithm %q", m.Algorithm)
+	}
+	keys, err := readSigningKeys(m.Dir, m.Algorithm)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		if m.RotationInterval <= 0 {
+			return fmt.Errorf("no %s signing keys found in %s", m.Algorithm, m.Dir)
+		}
+		if _, err := m.generate(); err != nil {
+			return err
+		}
+		return m.Load()
+	}
+	UseSigningKeys(keys)
+	return nil
+}
+
+// Refresh reloads the keys from disk and rotates them if they are due.
+func (m *KeyManager) Refresh() error {
+	if err := m.Load(); err != nil {
+		return err
+	}
+	current := currentSigningKey()
+	if m.RotationInterval <= 0 || current.Algorithm == AlgorithmHS256 {
+		return nil
+	}
+	if time.Since(current.CreatedAt) < m.RotationInterval {
+		return nil
+	}
+	if _, err := m.generate(); err != nil {
+		return err
+	}
+	if err := m.Load(); err != nil {
+		return err
+	}
+	return m.prune()
+}
+
+// prune deletes keys that were replaced by a newer key more than MaxKeyAge
+// ago. Tokens signed with them have expired by then.
+func (m *KeyManager) prune() error {
+	if m.MaxKeyAge <= 0 {
+		return nil
+	}
+	keys := allSigningKeys()
+	for i := 1; i < len(keys); i++ {
+		if time.Since(keys[i-1].CreatedAt) > m.MaxKeyAge {
+			if err := os.Remove(filepath.Join(m.Dir, keys[i].KID+".pem")); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return m.Load()
+}
+
+func (m *KeyManager) generate() (string, error) {
+	var private any
+	switch m.Algorithm {
+	case AlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return "", err
+		}
+		private = key
+	case AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", err
+		}
+		private = key
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(m.Dir, 0o700); err != nil {
+		return "", err
+	}
+	kid := time.Now().UTC().Format("20060102T150405.000000000Z")
+	file := filepath.Join(m.Dir, kid+".pem")
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return "", err
+	}
+	return kid, nil
+}
+
+// UseSigningKeys replaces the active key set. Passing nil switches back to
+// HS256 with SECRET_TOKEN.
+func UseSigningKeys(keys []*SigningKey) {
+	sorted := append([]*SigningKey(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	if len(sorted) == 0 {
+		signingKeys = nil
+		return
+	}
+	signingKeys = sorted
+}
+
+// VerificationKey is a jwt/v5 Keyfunc that picks the key named by the
+// token's kid header and checks that the token uses the key's algorithm.
+func VerificationKey(token *jwtv5.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	return verificationKey(kid, token.Method.Alg())
+}
+
+func verificationKey(kid, algorithm string) (any, error) {
+	for _, key := range allSigningKeys() {
+		if key.KID != kid {
+			continue
+		}
+		if algorithm != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method %s", algorithm)
+		}
+		return key.PublicKey, nil
+	}
+	return nil, ErrUnknownSigningKey
+}
+
+// PublicJWKS returns the public keys of the key set as a JSON Web Key Set.
+// Shared HS256 secrets are never published.
+func PublicJWKS() *dto.JWKSet {
+	set := &dto.JWKSet{Keys: []dto.JWK{}}
+	for _, key := range allSigningKeys() {
+		switch public := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, dto.JWK{
+				Kty: "RSA",
+				Kid: key.KID,
+				Use: "sig",
+				Alg: key.Algorithm,
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, dto.JWK{
+				Kty: "OKP",
+				Kid: key.KID,
+				Use: "sig",
+				Alg: key.Algorithm,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	return set
+}
+
+func allSigningKeys() []*SigningKey {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	if signingKeys == nil {
+		return []*SigningKey{hmacSigningKey()}
+	}
+	return signingKeys
+}
+
+func currentSigningKey() *SigningKey {
+	return allSigningKeys()[0]
+}
+
+func hmacSigningKey() *SigningKey {
+	secret := []byte(viper.GetString("SECRET_TOKEN"))
+	return &SigningKey{Algorithm: AlgorithmHS256, PrivateKey: secret, PublicKey: secret}
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	switch algorithm {
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+func readSigningKeys(dir, algorithm string) ([]*SigningKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	var keys []*SigningKey
+	for _, file := range files {
+		key, err := readSigningKey(file, algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func readSigningKey(file, algorithm string) (*SigningKey, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	var private any
+	switch block.Type {
+	case "PRIVATE KEY":
+		if private, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	case "RSA PRIVATE KEY":
+		if private, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	key := &SigningKey{
+		KID:        strings.TrimSuffix(filepath.Base(file), ".pem"),
+		Algorithm:  algorithm,
+		PrivateKey: private,
+		CreatedAt:  info.ModTime(),
+	}
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != AlgorithmRS256 {
+			return nil, fmt.Errorf("RSA key cannot be used with %s", algorithm)
+		}
+		key.PublicKey = &private.PublicKey
+	case ed25519.PrivateKey:
+		if algorithm != AlgorithmEdDSA {
+			return nil, fmt.Errorf("Ed25519 key cannot be used with %s", algorithm)
+		}
+		key.PublicKey = private.Public()
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", private)
+	}
+	return key, nil
+}
@@ -0,0 +1,156 @@
+package helper
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/entities"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func parseToken(t *testing.T, token string) (*jwtv5.Token, error) {
+	t.Helper()
+	return jwtv5.Parse(token, VerificationKey)
+}
+
+func writeKey(t *testing.T, dir, kid string, key any, modTime time.Time) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	file := filepath.Join(dir, kid+".pem")
+	assert.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	assert.NoError(t, os.Chtimes(file, modTime, modTime))
+}
+
+func TestKeyManager_HS256(t *testing.T) {
+	defer UseSigningKeys(nil)
+	viper.Set("SECRET_TOKEN", "secret")
+	manager := &KeyManager{Algorithm: AlgorithmHS256}
+	assert.NoError(t, manager.Refresh())
+
+	token, err := GenerateToken(&entities.User{Id: 1}, 0)
+	assert.NoError(t, err)
+	parsed, err := parseToken(t, token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmHS256, parsed.Method.Alg())
+	assert.Empty(t, PublicJWKS().Keys)
+}
+
+func TestKeyManager_RS256(t *testing.T) {
+	defer UseSigningKeys(nil)
+	dir := t.TempDir()
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	writeKey(t, dir, "old", oldKey, time.Now().Add(-time.Hour))
+	writeKey(t, dir, "new", newKey, time.Now())
+
+	manager := &KeyManager{Algorithm: AlgorithmRS256, Dir: dir}
+	assert.NoError(t, manager.Load())
+
+	token, err := GenerateToken(&entities.User{Id: 1}, 0)
+	assert.NoError(t, err)
+	parsed, err := parseToken(t, token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", parsed.Header["kid"])
+	assert.Equal(t, AlgorithmRS256, parsed.Method.Alg())
+
+	jwks := PublicJWKS()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "new", jwks.Keys[0].Kid)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+
+	t.Run("Token signed with a retired key", func(t *testing.T) {
+		UseSigningKeys([]*SigningKey{{KID: "old", Algorithm: AlgorithmRS256, PrivateKey: oldKey, PublicKey: &oldKey.PublicKey}})
+		token, err := GenerateToken(&entities.User{Id: 1}, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, manager.Load())
+		_, err = parseToken(t, token.Token)
+		assert.NoError(t, err)
+
+		assert.NoError(t, os.Remove(filepath.Join(dir, "old.pem")))
+		assert.NoError(t, manager.Load())
+		_, err = parseToken(t, token.Token)
+		assert.ErrorIs(t, err, ErrUnknownSigningKey)
+	})
+
+	t.Run("HS256 token is rejected", func(t *testing.T) {
+		viper.Set("SECRET_TOKEN", "secret")
+		hmacToken, _ := jwtv5.NewWithClaims(jwtv5.SigningMethodHS256, jwtv5.MapClaims{"Id": 1}).SignedString([]byte("secret"))
+		_, err := parseToken(t, hmacToken)
+		assert.Error(t, err)
+	})
+}
+
+func TestKeyManager_EdDSA(t *testing.T) {
+	defer UseSigningKeys(nil)
+	dir := t.TempDir()
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	writeKey(t, dir, "ed", key, time.Now())
+
+	assert.NoError(t, (&KeyManager{Algorithm: AlgorithmEdDSA, Dir: dir}).Load())
+	token, err := GenerateToken(&entities.User{Id: 1}, 0)
+	assert.NoError(t, err)
+	parsed, err := parseToken(t, token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmEdDSA, parsed.Method.Alg())
+
+	jwks := PublicJWKS()
+	assert.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[0].Crv)
+
+	t.Run("Wrong key type", func(t *testing.T) {
+		assert.Error(t, (&KeyManager{Algorithm: AlgorithmRS256, Dir: dir}).Load())
+	})
+}
+
+func TestKeyManager_Rotation(t *testing.T) {
+	defer UseSigningKeys(nil)
+	dir := t.TempDir()
+	manager := &KeyManager{Algorithm: AlgorithmEdDSA, Dir: dir, RotationInterval: time.Hour, MaxKeyAge: time.Hour}
+
+	// An empty directory gets a first key.
+	assert.NoError(t, manager.Refresh())
+	files, _ := filepath.Glob(filepath.Join(dir, "*.pem"))
+	assert.Len(t, files, 1)
+
+	// A fresh key is not rotated.
+	assert.NoError(t, manager.Refresh())
+	files, _ = filepath.Glob(filepath.Join(dir, "*.pem"))
+	assert.Len(t, files, 1)
+
+	// A key older than the interval is rotated but kept for verification.
+	old := time.Now().Add(-90 * time.Minute)
+	assert.NoError(t, os.Chtimes(files[0], old, old))
+	assert.NoError(t, manager.Refresh())
+	files, _ = filepath.Glob(filepath.Join(dir, "*.pem"))
+	assert.Len(t, files, 2)
+	assert.Len(t, PublicJWKS().Keys, 2)
+
+	// Keys superseded for longer than MaxKeyAge are deleted on rotation.
+	older := time.Now().Add(-3 * time.Hour)
+	for _, file := range files {
+		assert.NoError(t, os.Chtimes(file, older, older))
+		older = older.Add(time.Hour)
+	}
+	assert.NoError(t, manager.Refresh())
+	files, _ = filepath.Glob(filepath.Join(dir, "*.pem"))
+	assert.Len(t, files, 2)
+	assert.Len(t, PublicJWKS().Keys, 2)
+}
+
+func TestKeyManager_MissingKeys(t *testing.T) {
+	defer UseSigningKeys(nil)
+	err := (&KeyManager{Algorithm: AlgorithmRS256, Dir: t.TempDir()}).Load()
+	assert.Error(t, err)
+	assert.Error(t, (&KeyManager{Algorithm: "none"}).Load())
+}
@@ -1,40 +1,179 @@
 package helper
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"github.com/golang-jwt/jwt"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
 	"github.com/spf13/viper"
 	"go-wishlist-api-2/entities"
+	"strings"
 	"time"
 )
 
+// JWTClaims are the claims of an access token. Role is the user's role when
+// the token was issued; tokens from before roles existed have none. SessionID
+// is the session the token was issued to, zero for tokens from before
+// sessions were tracked.
 type JWTClaims struct {
-	Id    int
-	Email string
+	Id        int
+	Email     string
+	Role      string `json:",omitempty"`
+	SessionID uint   `json:"sid,omitempty"`
 	jwt.StandardClaims
 }
 
+// AccessToken is a signed JWT together with the values needed to revoke it.
+type AccessToken struct {
+	Token     string
+	JTI       string
+	ExpiresAt time.Time
+}
+
+const (
+	// personalAccessTokenPrefix tells personal access tokens apart from JWTs
+	// in the Authorization header and makes leaked tokens easy to scan for.
+	personalAccessTokenPrefix = "wlp_"
+
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var ErrMissingClaims = errors.New("missing or invalid token claims")
+
 func init() {
 	viper.AutomaticEnv()
 }
 
-func GenerateToken(user *entities.User) (string, error) {
+func GenerateToken(user *entities.User, sessionID uint) (*AccessToken, error) {
 
-	secretToken := []byte(viper.GetString("SECRET_TOKEN"))
+	key := currentSigningKey()
+	jti, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	expiresAt := now.Add(AccessTokenTTL())
 	claims := JWTClaims{
-		Id:    user.Id,
-		Email: user.Email,
+		Id:        user.Id,
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: sessionID,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(20 * time.Hour).Unix(),
-			NotBefore: time.Now().Unix(),
-			IssuedAt:  time.Now().Unix(),
+			Id:        jti,
+			ExpiresAt: expiresAt.Unix(),
+			NotBefore: now.Unix(),
+			IssuedAt:  now.Unix(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedString, err := token.SignedString(secretToken)
+	token := jwt.NewWithClaims(signingMethod(key.Algorithm), claims)
+	if key.KID != "" {
+		token.Header["kid"] = key.KID
+	}
+	signedString, err := token.SignedString(key.PrivateKey)
+
+	if err != nil {
+		return nil, err
+	}
+	return &AccessToken{Token: signedString, JTI: jti, ExpiresAt: time.Unix(expiresAt.Unix(), 0)}, nil
+}
+
+// GenerateRefreshToken returns a new opaque refresh token.
+func GenerateRefreshToken() (string, error) {
+	return randomToken(32)
+}
+
+// GenerateResetToken returns a new opaque password reset token.
+func GenerateResetToken() (string, error) {
+	return randomToken(32)
+}
+
+// GenerateShareToken returns a new opaque token for a shared list link.
+func GenerateShareToken() (string, error) {
+	return randomToken(32)
+}
+
+// GenerateClaimToken returns a new opaque token that lets the visitor who
+// claimed a gift change or release the claim.
+func GenerateClaimToken() (string, error) {
+	return randomToken(32)
+}
+
+// GeneratePersonalAccessToken returns a new opaque personal access token.
+func GeneratePersonalAccessToken() (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return personalAccessTokenPrefix + token, nil
+}
+
+// IsPersonalAccessToken reports whether token looks like a personal access
+// token rather than a JWT.
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, personalAccessTokenPrefix)
+}
+
+// HashToken returns the hex encoded SHA-256 of an opaque token. Only hashes
+// are stored so that a database leak does not leak usable tokens.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func AccessTokenTTL() time.Duration {
+	if ttl := viper.GetDuration("ACCESS_TOKEN_TTL"); ttl > 0 {
+		return ttl
+	}
+	return defaultAccessTokenTTL
+}
 
+func RefreshTokenTTL() time.Duration {
+	if ttl := viper.GetDuration("REFRESH_TOKEN_TTL"); ttl > 0 {
+		return ttl
+	}
+	return defaultRefreshTokenTTL
+}
+
+func randomToken(size int) (string, error) {
+	b, err := randomBytes(size)
 	if err != nil {
 		return "", err
 	}
-	return signedString, nil
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func randomBytes(size int) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// GetClaims reads the claims that echojwt, or the personal access token
+// middleware, stored under the "user" key.
+func GetClaims(ctx echo.Context) (*JWTClaims, error) {
+	if claims, ok := ctx.Get("user").(*JWTClaims); ok && claims != nil && claims.Id != 0 {
+		return claims, nil
+	}
+	token, ok := ctx.Get("user").(*jwtv5.Token)
+	if !ok || token == nil {
+		return nil, ErrMissingClaims
+	}
+	raw, err := json.Marshal(token.Claims)
+	if err != nil {
+		return nil, ErrMissingClaims
+	}
+	var claims JWTClaims
+	if err := json.Unmarshal(raw, &claims); err != nil || claims.Id == 0 {
+		return nil, ErrMissingClaims
+	}
+	return &claims, nil
 }
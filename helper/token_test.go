@@ -2,9 +2,13 @@ package helper
 
 import (
 	"github.com/golang-jwt/jwt"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"go-wishlist-api-2/entities"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -15,17 +19,73 @@ func TestGenerateToken(t *testing.T) {
 		Id:    1,
 		Email: "admin@gmail.com",
 	}
-	token, err := GenerateToken(user)
+	token, err := GenerateToken(user, 4)
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, token.Token)
 
 	claims := JWTClaims{}
-	parsedToken, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+	parsedToken, err := jwt.ParseWithClaims(token.Token, &claims, func(token *jwt.Token) (interface{}, error) {
 		return []byte("secret"), nil
 	})
 	assert.NoError(t, err)
 	assert.True(t, parsedToken.Valid)
 	assert.Equal(t, user.Id, claims.Id)
 	assert.Equal(t, user.Email, claims.Email)
+	assert.Equal(t, uint(4), claims.SessionID)
 	assert.True(t, claims.ExpiresAt > time.Now().Unix())
+	assert.Equal(t, token.JTI, claims.StandardClaims.Id)
+	assert.Equal(t, token.ExpiresAt.Unix(), claims.ExpiresAt)
+	assert.True(t, claims.ExpiresAt <= time.Now().Add(defaultAccessTokenTTL).Unix())
+
+	other, err := GenerateToken(user, 0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token.JTI, other.JTI)
+}
+
+func TestGenerateRefreshToken(t *testing.T) {
+	token, err := GenerateRefreshToken()
+	assert.NoError(t, err)
+	assert.Len(t, token, 43)
+	assert.Len(t, HashToken(token), 64)
+	assert.Equal(t, HashToken(token), HashToken(token))
+	assert.NotEqual(t, token, HashToken(token))
+}
+
+func TestGeneratePersonalAccessToken(t *testing.T) {
+	token, err := GeneratePersonalAccessToken()
+	assert.NoError(t, err)
+	assert.True(t, IsPersonalAccessToken(token))
+	assert.Len(t, token, 47)
+
+	jwtToken, err := GenerateToken(&entities.User{Id: 1, Email: "admin@gmail.com"}, 0)
+	assert.NoError(t, err)
+	assert.False(t, IsPersonalAccessToken(jwtToken.Token))
+}
+
+func TestGetClaims(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		c.Set("user", &jwtv5.Token{Claims: jwtv5.MapClaims{"Id": float64(7), "Email": "admin@gmail.com", "jti": "abc", "exp": float64(1700000000)}})
+		claims, err := GetClaims(c)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, claims.Id)
+		assert.Equal(t, "admin@gmail.com", claims.Email)
+		assert.Equal(t, "abc", claims.StandardClaims.Id)
+		assert.Equal(t, int64(1700000000), claims.ExpiresAt)
+	})
+
+	t.Run("Personal access token", func(t *testing.T) {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		c.Set("user", &JWTClaims{Id: 7, Email: "admin@gmail.com"})
+		claims, err := GetClaims(c)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, claims.Id)
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		claims, err := GetClaims(c)
+		assert.ErrorIs(t, err, ErrMissingClaims)
+		assert.Nil(t, claims)
+	})
 }
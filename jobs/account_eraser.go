@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type AccountEraser interface {
+	EraseDueAccounts() (int64, error)
+}
+
+// StartAccountEraser erases the accounts whose deletion grace period has
+// ended once immediately and then on every interval until ctx is cancelled.
+// It does nothing when interval is not positive.
+func StartAccountEraser(ctx context.Context, eraser AccountEraser, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("account erasure disabled: interval is %s", interval)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		eraseAccounts(eraser)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func eraseAccounts(eraser AccountEraser) {
+	erased, err := eraser.EraseDueAccounts()
+	if err != nil {
+		log.Printf("account erasure failed: %v", err)
+		return
+	}
+	if erased > 0 {
+		log.Printf("account erasure removed %d accounts", erased)
+	}
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeEraser struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *fakeEraser) EraseDueAccounts() (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return 1, nil
+}
+
+func (f *fakeEraser) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestStartAccountEraser(t *testing.T) {
+	t.Run("Runs until cancelled", func(t *testing.T) {
+		eraser := &fakeEraser{}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			StartAccountEraser(ctx, eraser, time.Millisecond)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool { return eraser.Calls() >= 2 }, time.Second, time.Millisecond)
+		cancel()
+		<-done
+	})
+
+	t.Run("Disabled without an interval", func(t *testing.T) {
+		eraser := &fakeEraser{}
+		StartAccountEraser(context.Background(), eraser, 0)
+		assert.Zero(t, eraser.Calls())
+	})
+}
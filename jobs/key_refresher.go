@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type KeyRefresher interface {
+	Refresh() error
+}
+
+// StartKeyRefresher reloads the JWT signing keys, rotating them when due, on
+// every interval until ctx is cancelled. A zero or negative interval disables
+// reloading.
+func StartKeyRefresher(ctx context.Context, refresher KeyRefresher, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("signing key reload disabled: interval is %s", interval)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := refresher.Refresh(); err != nil {
+				log.Printf("signing key refresh failed: %v", err)
+			}
+		}
+	}
+}
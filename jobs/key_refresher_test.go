@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeRefresher struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *fakeRefresher) Refresh() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return nil
+}
+
+func (f *fakeRefresher) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestStartKeyRefresher(t *testing.T) {
+	t.Run("Runs until cancelled", func(t *testing.T) {
+		refresher := &fakeRefresher{}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			StartKeyRefresher(ctx, refresher, time.Millisecond)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool { return refresher.Calls() >= 2 }, time.Second, time.Millisecond)
+		cancel()
+		<-done
+	})
+
+	t.Run("Disabled without an interval", func(t *testing.T) {
+		refresher := &fakeRefresher{}
+		StartKeyRefresher(context.Background(), refresher, 0)
+		assert.Zero(t, refresher.Calls())
+	})
+}
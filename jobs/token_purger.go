@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type TokenPurger interface {
+	PurgeExpiredTokens() (int64, error)
+}
+
+// StartTokenPurger removes expired refresh tokens and denylist entries once
+// immediately and then on every interval until ctx is cancelled. A zero or
+// negative interval disables it.
+func StartTokenPurger(ctx context.Context, purger TokenPurger, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("token purge disabled: interval is %s", interval)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeTokens(purger)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeTokens(purger TokenPurger) {
+	purged, err := purger.PurgeExpiredTokens()
+	if err != nil {
+		log.Printf("token purge failed: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("token purge removed %d expired tokens", purged)
+	}
+}
@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStartTokenPurger(t *testing.T) {
+	t.Run("Runs until cancelled", func(t *testing.T) {
+		purger := &fakePurger{}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			StartTokenPurger(ctx, purger, time.Millisecond)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool { return purger.Calls() >= 2 }, time.Second, time.Millisecond)
+		cancel()
+		<-done
+	})
+
+	t.Run("Disabled without an interval", func(t *testing.T) {
+		purger := &fakePurger{}
+		StartTokenPurger(context.Background(), purger, 0)
+		assert.Zero(t, purger.Calls())
+	})
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type TrashPurger interface {
+	PurgeExpired(retention time.Duration) (int64, error)
+}
+
+// StartTrashPurger purges expired trash once immediately and then on every
+// interval until ctx is cancelled. A zero or negative interval disables it.
+func StartTrashPurger(ctx context.Context, purger TrashPurger, retention, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("trash purge disabled: interval is %s", interval)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeTrash(purger, retention)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeTrash(purger TrashPurger, retention time.Duration) {
+	purged, err := purger.PurgeExpired(retention)
+	if err != nil {
+		log.Printf("trash purge failed: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("trash purge removed %d wishlists", purged)
+	}
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakePurger struct {
+	mu        sync.Mutex
+	calls     int
+	retention time.Duration
+	err       error
+}
+
+func (f *fakePurger) PurgeExpired(retention time.Duration) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	f.retention = retention
+	return 1, f.err
+}
+
+func (f *fakePurger) PurgeExpiredTokens() (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return 1, f.err
+}
+
+func (f *fakePurger) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestStartTrashPurger(t *testing.T) {
+	t.Run("Runs until cancelled", func(t *testing.T) {
+		purger := &fakePurger{}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			StartTrashPurger(ctx, purger, 24*time.Hour, time.Millisecond)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool { return purger.Calls() >= 2 }, time.Second, time.Millisecond)
+		cancel()
+		<-done
+		assert.Equal(t, 24*time.Hour, purger.retention)
+	})
+
+	t.Run("Keeps running after an error", func(t *testing.T) {
+		purger := &fakePurger{err: errors.New("database is down")}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go StartTrashPurger(ctx, purger, time.Hour, time.Millisecond)
+
+		assert.Eventually(t, func() bool { return purger.Calls() >= 2 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("Disabled without an interval", func(t *testing.T) {
+		purger := &fakePurger{}
+		StartTrashPurger(context.Background(), purger, time.Hour, 0)
+		assert.Zero(t, purger.Calls())
+	})
+}
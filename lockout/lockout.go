@@ -0,0 +1,135 @@
+// Package lockout slows down and temporarily blocks keys, such as accounts or
+// client IPs, after repeated failed attempts.
+package lockout
+
+import "time"
+
+// Record is the failed attempt history of a key.
+type Record struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// Store keeps the records of all keys. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Reserve atomically passes the current record of key to allow and, if
+	// allow accepts it, counts a failure of key at the given time. Records
+	// without a failure for ttl start over. It returns the record allow was
+	// given and whether the failure was counted.
+	Reserve(key string, at time.Time, ttl time.Duration, allow func(Record) bool) (Record, bool, error)
+	// Release takes back one failure of key.
+	Release(key string) error
+	Reset(key string) error
+}
+
+// Policy decides how long a key has to wait after its failures.
+type Policy struct {
+	// FreeAttempts failures are allowed without any delay.
+	FreeAttempts int
+	// BaseDelay is the delay after the first failure past FreeAttempts. It
+	// doubles with every further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutThreshold failures lock the key for LockoutDuration.
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// ResetAfter is how long a key has to go without failures to start over.
+	ResetAfter time.Duration
+}
+
+var (
+	DefaultAccountPolicy = Policy{
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         time.Minute,
+		LockoutThreshold: 10,
+		LockoutDuration:  15 * time.Minute,
+		ResetAfter:       time.Hour,
+	}
+	DefaultIPPolicy = Policy{
+		FreeAttempts:     20,
+		BaseDelay:        time.Second,
+		MaxDelay:         time.Minute,
+		LockoutThreshold: 100,
+		LockoutDuration:  time.Hour,
+		ResetAfter:       2 * time.Hour,
+	}
+)
+
+// Delay returns how long a key has to wait after its last failure once it
+// failed the given number of times.
+func (p Policy) Delay(failures int) time.Duration {
+	if p.LockoutThreshold > 0 && failures >= p.LockoutThreshold {
+		return p.LockoutDuration
+	}
+	excess := failures - p.FreeAttempts
+	if excess <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 1; i < excess; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// Limiter applies a Policy to the records in a Store.
+type Limiter struct {
+	store  Store
+	policy Policy
+	now    func() time.Time
+}
+
+func NewLimiter(store Store, policy Policy) *Limiter {
+	if policy.ResetAfter < policy.LockoutDuration {
+		policy.ResetAfter = policy.LockoutDuration
+	}
+	return &Limiter{store: store, policy: policy, now: time.Now}
+}
+
+// Reserve returns how long key has to wait before its next attempt or, if it
+// may try right away, counts the attempt as a failure up front and returns
+// zero. Checking and counting in one step keeps parallel attempts from all
+// getting through before the first of them failed. Attempts that turn out
+// to succeed are taken back with Release or Reset.
+func (l *Limiter) Reserve(key string) (time.Duration, error) {
+	var wait time.Duration
+	_, _, err := l.store.Reserve(key, l.now(), l.policy.ResetAfter, func(record Record) bool {
+		wait = l.remaining(record)
+		return wait == 0
+	})
+	if err != nil {
+		return 0, err
+	}
+	return wait, nil
+}
+
+// Release takes back the failure Reserve counted for an attempt that
+// succeeded, keeping the earlier failures of key.
+func (l *Limiter) Release(key string) error {
+	return l.store.Release(key)
+}
+
+// Reset forgets the failures of key, e.g. after a successful attempt or when
+// an administrator unlocks it.
+func (l *Limiter) Reset(key string) error {
+	return l.store.Reset(key)
+}
+
+func (l *Limiter) remaining(record Record) time.Duration {
+	if record.Failures == 0 {
+		return 0
+	}
+	until := record.LastFailure.Add(l.policy.Delay(record.Failures))
+	if wait := until.Sub(l.now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
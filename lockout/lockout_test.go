@@ -0,0 +1,177 @@
+package lockout
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPolicy_Delay(t *testing.T) {
+	policy := Policy{
+		FreeAttempts:     2,
+		BaseDelay:        time.Second,
+		MaxDelay:         10 * time.Second,
+		LockoutThreshold: 8,
+		LockoutDuration:  time.Hour,
+	}
+	testCases := []struct {
+		failures int
+		delay    time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{6, 8 * time.Second},
+		{7, 10 * time.Second},
+		{8, time.Hour},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.delay, policy.Delay(tc.failures), "failures: %d", tc.failures)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	newLimiter := func() *Limiter {
+		limiter := NewLimiter(NewMemoryStore(), Policy{
+			FreeAttempts:     1,
+			BaseDelay:        time.Second,
+			MaxDelay:         time.Minute,
+			LockoutThreshold: 3,
+			LockoutDuration:  15 * time.Minute,
+		})
+		limiter.now = func() time.Time { return now }
+		return limiter
+	}
+	lockOut := func(limiter *Limiter, key string) {
+		limiter.Reserve(key)
+		limiter.Reserve(key)
+		limiter.now = func() time.Time { return now.Add(time.Second) }
+		limiter.Reserve(key)
+		limiter.now = func() time.Time { return now }
+	}
+
+	t.Run("Backoff and lockout", func(t *testing.T) {
+		limiter := newLimiter()
+		wait, err := limiter.Reserve("account:a")
+		assert.NoError(t, err)
+		assert.Zero(t, wait)
+
+		wait, _ = limiter.Reserve("account:a")
+		assert.Zero(t, wait)
+
+		wait, _ = limiter.Reserve("account:a")
+		assert.Equal(t, time.Second, wait)
+
+		limiter.now = func() time.Time { return now.Add(time.Second) }
+		wait, _ = limiter.Reserve("account:a")
+		assert.Zero(t, wait)
+
+		wait, _ = limiter.Reserve("account:a")
+		assert.Equal(t, 15*time.Minute, wait)
+		wait, _ = limiter.Reserve("account:b")
+		assert.Zero(t, wait)
+	})
+
+	t.Run("Lockout expires", func(t *testing.T) {
+		limiter := newLimiter()
+		lockOut(limiter, "ip:1.2.3.4")
+		limiter.now = func() time.Time { return now.Add(17 * time.Minute) }
+		wait, err := limiter.Reserve("ip:1.2.3.4")
+		assert.NoError(t, err)
+		assert.Zero(t, wait)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		limiter := newLimiter()
+		lockOut(limiter, "account:a")
+		assert.NoError(t, limiter.Reset("account:a"))
+		wait, _ := limiter.Reserve("account:a")
+		assert.Zero(t, wait)
+	})
+}
+
+func TestLimiter_Reserve(t *testing.T) {
+	policy := Policy{FreeAttempts: 2, BaseDelay: time.Minute, LockoutThreshold: 5, LockoutDuration: time.Hour}
+
+	t.Run("Counts attempts up front", func(t *testing.T) {
+		limiter := NewLimiter(NewMemoryStore(), policy)
+		for i := 0; i < 3; i++ {
+			wait, err := limiter.Reserve("account:a")
+			assert.NoError(t, err)
+			assert.Zero(t, wait)
+		}
+		wait, _ := limiter.Reserve("account:a")
+		assert.Equal(t, time.Minute, wait.Round(time.Minute))
+		// Rejected attempts are not counted.
+		assert.Equal(t, 3, failures(limiter, "account:a"))
+	})
+
+	t.Run("Release takes back one attempt", func(t *testing.T) {
+		limiter := NewLimiter(NewMemoryStore(), policy)
+		limiter.Reserve("account:a")
+		limiter.Reserve("account:a")
+		assert.NoError(t, limiter.Release("account:a"))
+		assert.Equal(t, 1, failures(limiter, "account:a"))
+	})
+
+	t.Run("Parallel attempts", func(t *testing.T) {
+		limiter := NewLimiter(NewMemoryStore(), policy)
+		var wg sync.WaitGroup
+		var allowed atomic.Int32
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if wait, err := limiter.Reserve("account:a"); err == nil && wait == 0 {
+					allowed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		// Only the free attempts and the first delayed one get through.
+		assert.Equal(t, int32(policy.FreeAttempts+1), allowed.Load())
+	})
+}
+
+func TestMemoryStore_Reserve(t *testing.T) {
+	store := NewMemoryStore()
+	at := time.Now()
+	allow := func(Record) bool { return true }
+
+	record, counted, err := store.Reserve("key", at, time.Minute, allow)
+	assert.NoError(t, err)
+	assert.True(t, counted)
+	assert.Equal(t, 0, record.Failures)
+
+	record, _, _ = store.Reserve("key", at.Add(30*time.Second), time.Minute, allow)
+	assert.Equal(t, 1, record.Failures)
+	assert.Equal(t, 2, store.records["key"].Failures)
+
+	// Rejected attempts are not counted.
+	_, counted, _ = store.Reserve("key", at.Add(40*time.Second), time.Minute, func(Record) bool { return false })
+	assert.False(t, counted)
+	assert.Equal(t, 2, store.records["key"].Failures)
+
+	// A failure after the ttl starts over.
+	record, _, _ = store.Reserve("key", at.Add(2*time.Minute), time.Minute, allow)
+	assert.Equal(t, 0, record.Failures)
+	assert.Equal(t, 1, store.records["key"].Failures)
+
+	// Expired records of other keys are swept.
+	store.Reserve("other", at.Add(10*time.Minute), time.Minute, allow)
+	assert.NotContains(t, store.records, "key")
+}
+
+// failures returns the failures the memory store of limiter counted for key.
+func failures(limiter *Limiter, key string) int {
+	record, ok := limiter.store.(*MemoryStore).records[key]
+	if !ok {
+		return 0
+	}
+	return record.Failures
+}
@@ -0,0 +1,80 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps records in process memory. Records are lost on restart
+// and not shared between instances.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]*memoryRecord
+	lastSweep time.Time
+}
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+// sweepInterval is how often Reserve drops expired records so that
+// unknown keys cannot grow the map without bound.
+const sweepInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]*memoryRecord{}}
+}
+
+func (s *MemoryStore) Reserve(key string, at time.Time, ttl time.Duration, allow func(Record) bool) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var current Record
+	if record, ok := s.records[key]; ok && !at.After(record.expiresAt) {
+		current = record.Record
+	}
+	if !allow(current) {
+		return current, false, nil
+	}
+	s.addFailure(key, at, ttl)
+	return current, true, nil
+}
+
+func (s *MemoryStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok && record.Failures > 0 {
+		record.Failures--
+	}
+	return nil
+}
+
+func (s *MemoryStore) addFailure(key string, at time.Time, ttl time.Duration) {
+	if at.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(at)
+	}
+	record, ok := s.records[key]
+	if !ok || at.After(record.expiresAt) {
+		record = &memoryRecord{}
+		s.records[key] = record
+	}
+	record.Failures++
+	record.LastFailure = at
+	record.expiresAt = at.Add(ttl)
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, record := range s.records {
+		if now.After(record.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+	s.lastSweep = now
+}
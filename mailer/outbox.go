@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Outbox keeps every message in memory instead of delivering it. If Dir is
+// set each message is also written to a file there, which is handy when
+// running the API locally without an SMTP server.
+type Outbox struct {
+	Dir string
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewOutbox(dir string) *Outbox {
+	return &Outbox{Dir: dir}
+}
+
+func (o *Outbox) Send(message Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, message)
+	if o.Dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(o.Dir, 0o700); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%03d.txt", time.Now().UTC().Format("20060102T150405.000000000Z"), len(o.messages))
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", message.To, message.Subject, message.Body)
+	return os.WriteFile(filepath.Join(o.Dir, name), []byte(content), 0o600)
+}
+
+// Messages returns the messages sent so far, oldest first.
+func (o *Outbox) Messages() []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Message(nil), o.messages...)
+}
+
+// Last returns the most recent message sent to the given address.
+func (o *Outbox) Last(to string) (Message, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if o.messages[i].To == to {
+			return o.messages[i], true
+		}
+	}
+	return Message{}, false
+}
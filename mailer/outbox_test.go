@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutbox(t *testing.T) {
+	dir := t.TempDir()
+	outbox := NewOutbox(dir)
+
+	assert.NoError(t, outbox.Send(Message{To: "a@example.com", Subject: "first", Body: "hello"}))
+	assert.NoError(t, outbox.Send(Message{To: "b@example.com", Subject: "second", Body: "hello"}))
+	assert.NoError(t, outbox.Send(Message{To: "a@example.com", Subject: "third", Body: "hello"}))
+
+	assert.Len(t, outbox.Messages(), 3)
+	last, ok := outbox.Last("a@example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "third", last.Subject)
+	_, ok = outbox.Last("c@example.com")
+	assert.False(t, ok)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.txt"))
+	assert.Len(t, files, 3)
+	content, _ := os.ReadFile(files[0])
+	assert.Contains(t, string(content), "Subject: first")
+}
+
+func TestSMTPMailer_Format(t *testing.T) {
+	m := NewSMTPMailer("localhost", "25", "", "", "noreply@example.com")
+	raw := string(m.format(Message{To: "a@example.com", Subject: "Hi", Body: "line 1\nline 2"}))
+	assert.Contains(t, raw, "From: noreply@example.com\r\n")
+	assert.Contains(t, raw, "Subject: Hi\r\n")
+	assert.Contains(t, raw, "\r\n\r\nline 1\r\nline 2")
+
+	raw = string(m.format(Message{To: "a@example.com", Subject: "Hi\r\nBcc: b@example.com"}))
+	assert.Contains(t, raw, "Subject: HiBcc: b@example.com\r\n")
+}
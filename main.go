@@ -1,20 +1,92 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"github.com/labstack/echo/v4"
 	"go-wishlist-api-2/config"
+	"go-wishlist-api-2/jobs"
+	"go-wishlist-api-2/repositories"
 	"go-wishlist-api-2/routes"
+	"go-wishlist-api-2/usecases"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 )
 
+// shutdownTimeout is how long running requests may take to finish once the
+// server is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	config.LoadConfig()
 	config.InitDatabase()
+	if err := repositories.MigrateDefaultLists(config.DB); err != nil {
+		log.Fatal(err)
+	}
+	config.InitSigningKeys()
+	config.InitMailer()
+	config.InitPasswordHasher()
+	config.InitOIDCProviders()
 
 	e := echo.New()
+	// Only trust X-Forwarded-For from proxies on private networks so that
+	// clients cannot spoof the IP used for login throttling.
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
 
 	auth := e.Group("")
-	routes.AuthRouter(auth)
+	authUsecase := routes.AuthRouter(auth)
+	admin := e.Group("/admin")
+	routes.AdminRouter(admin, authUsecase)
+	me := e.Group("/me")
+	routes.ProfileRouter(me)
+	account := e.Group("/account")
+	routes.AccountRouter(account)
+	tokens := e.Group("/me/tokens")
+	routes.PersonalAccessTokenRouter(tokens)
+	sessions := e.Group("/me/sessions")
+	routes.SessionRouter(sessions)
 	wishlists := e.Group("/wishlists")
 	routes.WishlistRouter(wishlists)
-	e.Logger.Fatal(e.Start(":1323"))
+	lists := e.Group("/lists")
+	routes.ListRouter(lists)
+	tags := e.Group("/tags")
+	routes.TagRouter(tags)
+	shared := e.Group("/shared")
+	routes.SharedRouter(shared)
+
+	startJobs(ctx, authUsecase)
+	go func() {
+		if err := e.Start(":1323"); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Fatal(err)
+		}
+	}()
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		e.Logger.Fatal(err)
+	}
+}
+
+// startJobs runs the background jobs until ctx is cancelled.
+func startJobs(ctx context.Context, auth usecases.AuthUsecase) {
+	go jobs.StartTokenPurger(ctx, auth, config.ENV.TOKEN_PURGE_INTERVAL)
+	go jobs.StartKeyRefresher(ctx, config.SigningKeys, config.ENV.JWT_KEY_RELOAD_INTERVAL)
+	wishlists := usecases.NewWishlistUsecase(
+		repositories.NewWishlistRepository(config.DB),
+		repositories.NewListRepository(config.DB),
+		repositories.NewTagRepository(config.DB),
+	)
+	go jobs.StartTrashPurger(ctx, wishlists, config.ENV.TRASH_RETENTION, config.ENV.TRASH_PURGE_INTERVAL)
+	accounts := usecases.NewAccountUsecase(repositories.NewUserRepository(config.DB), config.Mailer,
+		config.ENV.ACCOUNT_DELETION_GRACE, config.ENV.APP_URL)
+	go jobs.StartAccountEraser(ctx, accounts, config.ENV.ACCOUNT_ERASURE_INTERVAL)
 }
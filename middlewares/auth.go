@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+)
+
+type TokenDenylist interface {
+	IsAccessTokenDenied(jti string) (bool, error)
+	IsSessionRevoked(sessionID uint) (bool, error)
+}
+
+// JWT validates the bearer token against the active signing keys and
+// additionally rejects tokens without a jti, tokens that were revoked through
+// logout and tokens of revoked sessions.
+func JWT(denylist TokenDenylist) echo.MiddlewareFunc {
+	validate := echojwt.WithConfig(echojwt.Config{KeyFunc: helper.VerificationKey})
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return validate(func(ctx echo.Context) error {
+			if err := checkToken(ctx, denylist); err != nil {
+				return errorHandler.HandleError(ctx, err)
+			}
+			return next(ctx)
+		})
+	}
+}
+
+// OptionalJWT validates the bearer token like JWT, but lets requests without
+// a valid token through anonymously instead of rejecting them, so that
+// handlers can tell signed in users apart without requiring a sign in. An
+// expired token must not lock a visitor out of a public page.
+func OptionalJWT(denylist TokenDenylist) echo.MiddlewareFunc {
+	validate := echojwt.WithConfig(echojwt.Config{
+		KeyFunc:                helper.VerificationKey,
+		ContinueOnIgnoredError: true,
+		ErrorHandler: func(ctx echo.Context, err error) error {
+			return nil
+		},
+	})
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return validate(func(ctx echo.Context) error {
+			if ctx.Get("user") == nil {
+				return next(ctx)
+			}
+			if err := checkToken(ctx, denylist); err != nil {
+				if _, ok := err.(*errorHandler.UnAuthorizedError); !ok {
+					return errorHandler.HandleError(ctx, err)
+				}
+				ctx.Set("user", nil)
+			}
+			return next(ctx)
+		})
+	}
+}
+
+// checkToken rejects validated tokens without a jti, revoked tokens and
+// tokens of revoked sessions.
+func checkToken(ctx echo.Context, denylist TokenDenylist) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil || claims.StandardClaims.Id == "" {
+		return &errorHandler.UnAuthorizedError{Message: helper.ErrMissingClaims.Error()}
+	}
+	denied, err := denylist.IsAccessTokenDenied(claims.StandardClaims.Id)
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if denied {
+		return &errorHandler.UnAuthorizedError{Message: "Token has been revoked"}
+	}
+	if claims.SessionID != 0 {
+		revoked, err := denylist.IsSessionRevoked(claims.SessionID)
+		if err != nil {
+			return &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		if revoked {
+			return &errorHandler.UnAuthorizedError{Message: "Session has been revoked"}
+		}
+	}
+	return nil
+}
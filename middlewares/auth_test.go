@@ -0,0 +1,118 @@
+package middlewares
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/helper"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// fakeDenylist holds denied jtis and revoked sessions as "session:<id>".
+type fakeDenylist map[string]bool
+
+func (d fakeDenylist) IsAccessTokenDenied(jti string) (bool, error) {
+	return d[jti], nil
+}
+
+func (d fakeDenylist) IsSessionRevoked(sessionID uint) (bool, error) {
+	return d["session:"+strconv.FormatUint(uint64(sessionID), 10)], nil
+}
+
+func TestJWT(t *testing.T) {
+	viper.Set("SECRET_TOKEN", "secret")
+	token, err := helper.GenerateToken(&entities.User{Id: 1, Email: "admin@example.com"}, 0)
+	assert.NoError(t, err)
+
+	serve := func(denylist fakeDenylist, authorization string) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.GET("/", func(ctx echo.Context) error {
+			return ctx.NoContent(http.StatusOK)
+		}, JWT(denylist))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Valid token", func(t *testing.T) {
+		rec := serve(fakeDenylist{}, "Bearer "+token.Token)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Revoked token", func(t *testing.T) {
+		rec := serve(fakeDenylist{token.JTI: true}, "Bearer "+token.Token)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Token has been revoked")
+	})
+
+	t.Run("Revoked session", func(t *testing.T) {
+		sessionToken, err := helper.GenerateToken(&entities.User{Id: 1, Email: "admin@example.com"}, 4)
+		assert.NoError(t, err)
+		rec := serve(fakeDenylist{"session:4": true}, "Bearer "+sessionToken.Token)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Session has been revoked")
+
+		rec = serve(fakeDenylist{"session:5": true}, "Bearer "+sessionToken.Token)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		rec := serve(fakeDenylist{}, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestOptionalJWT(t *testing.T) {
+	viper.Set("SECRET_TOKEN", "secret")
+	token, err := helper.GenerateToken(&entities.User{Id: 1, Email: "admin@example.com"}, 0)
+	assert.NoError(t, err)
+
+	serve := func(denylist fakeDenylist, authorization string) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.GET("/", func(ctx echo.Context) error {
+			if claims, err := helper.GetClaims(ctx); err == nil {
+				return ctx.String(http.StatusOK, strconv.Itoa(claims.Id))
+			}
+			return ctx.String(http.StatusOK, "anonymous")
+		}, OptionalJWT(denylist))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Anonymous", func(t *testing.T) {
+		rec := serve(fakeDenylist{}, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "anonymous", rec.Body.String())
+	})
+
+	t.Run("Valid token", func(t *testing.T) {
+		rec := serve(fakeDenylist{}, "Bearer "+token.Token)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1", rec.Body.String())
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		rec := serve(fakeDenylist{}, "Bearer invalid")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "anonymous", rec.Body.String())
+	})
+
+	t.Run("Revoked token", func(t *testing.T) {
+		rec := serve(fakeDenylist{token.JTI: true}, "Bearer "+token.Token)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "anonymous", rec.Body.String())
+	})
+}
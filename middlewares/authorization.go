@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/rbac"
+)
+
+// RequirePermission only lets requests through whose role grants permission.
+// It must run after JWT, which puts the claims into the context. The role is
+// taken from the token; changing a role revokes the user's tokens.
+func RequirePermission(permission rbac.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			claims, err := helper.GetClaims(ctx)
+			if err != nil {
+				return errorHandler.HandleError(ctx, &errorHandler.UnAuthorizedError{Message: err.Error()})
+			}
+			if !rbac.Can(claims.Role, permission) {
+				return errorHandler.HandleError(ctx, &errorHandler.ForbiddenError{Message: "You don't have permission to perform this action"})
+			}
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/rbac"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequirePermission(t *testing.T) {
+	viper.Set("SECRET_TOKEN", "secret")
+
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		e := echo.New()
+		g := e.Group("", JWT(fakeDenylist{}), RequirePermission(rbac.ManageUsers))
+		g.GET("/", func(ctx echo.Context) error {
+			return ctx.NoContent(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	tokenFor := func(role string) string {
+		token, err := helper.GenerateToken(&entities.User{Id: 1, Email: "admin@example.com", Role: role}, 0)
+		assert.NoError(t, err)
+		return "Bearer " + token.Token
+	}
+
+	t.Run("Admin", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(tokenFor(entities.RoleAdmin)).Code)
+	})
+
+	t.Run("Moderator lacks permission", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(tokenFor(entities.RoleModerator)).Code)
+	})
+
+	t.Run("Token without role", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(tokenFor("")).Code)
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("").Code)
+	})
+}
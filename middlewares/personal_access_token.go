@@ -0,0 +1,81 @@
+package middlewares
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"net/http"
+	"strings"
+)
+
+// personalAccessTokenKey is the context key of the personal access token a
+// request was authenticated with.
+const personalAccessTokenKey = "personal_access_token"
+
+type PersonalAccessTokenAuthenticator interface {
+	Authenticate(token string) (*entities.PersonalAccessToken, error)
+}
+
+// JWTOrPersonalAccessToken authenticates requests carrying a personal access
+// token as bearer token and hands every other request to JWT. Combine it with
+// RequireScopes to limit what the tokens can do.
+func JWTOrPersonalAccessToken(denylist TokenDenylist, tokens PersonalAccessTokenAuthenticator) echo.MiddlewareFunc {
+	validateJWT := JWT(denylist)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withJWT := validateJWT(next)
+		return func(ctx echo.Context) error {
+			plain, ok := strings.CutPrefix(ctx.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok || !helper.IsPersonalAccessToken(plain) {
+				return withJWT(ctx)
+			}
+			token, err := tokens.Authenticate(plain)
+			if err != nil {
+				return errorHandler.HandleError(ctx, err)
+			}
+			// The claims carry no role: personal access tokens are limited to
+			// their scopes and never act with the user's admin permissions.
+			claims := &helper.JWTClaims{Id: token.UserID}
+			if token.User != nil {
+				claims.Email = token.User.Email
+			}
+			ctx.Set("user", claims)
+			ctx.Set(personalAccessTokenKey, token)
+			return next(ctx)
+		}
+	}
+}
+
+// RequireScopes rejects requests made with a personal access token that was
+// not granted readScope for safe methods or writeScope for all others.
+// Requests authenticated with a JWT are not restricted.
+func RequireScopes(readScope, writeScope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			token, ok := ctx.Get(personalAccessTokenKey).(*entities.PersonalAccessToken)
+			if !ok {
+				return next(ctx)
+			}
+			scope := writeScope
+			switch ctx.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				scope = readScope
+			}
+			if !grantsScope(token, scope) {
+				return errorHandler.HandleError(ctx, &errorHandler.ForbiddenError{Message: "Access token lacks the " + scope + " scope"})
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// grantsScope reports whether token was granted scope. Write access to
+// wishlists includes read access.
+func grantsScope(token *entities.PersonalAccessToken, scope string) bool {
+	for _, granted := range strings.Fields(token.Scopes) {
+		if granted == scope || granted == entities.ScopeWishlistsWrite && scope == entities.ScopeWishlistsRead {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+type fakeAuthenticator map[string]*entities.PersonalAccessToken
+
+func (a fakeAuthenticator) Authenticate(token string) (*entities.PersonalAccessToken, error) {
+	if pat, ok := a[token]; ok {
+		return pat, nil
+	}
+	return nil, &errorHandler.UnAuthorizedError{Message: "Invalid access token"}
+}
+
+func TestJWTOrPersonalAccessToken(t *testing.T) {
+	viper.Set("SECRET_TOKEN", "secret")
+	jwtToken, err := helper.GenerateToken(&entities.User{Id: 1, Email: "admin@example.com"}, 0)
+	assert.NoError(t, err)
+	tokens := fakeAuthenticator{
+		"wlp_read":  {ID: 1, UserID: 2, Scopes: entities.ScopeWishlistsRead, User: &entities.User{Id: 2, Email: "bot@example.com"}},
+		"wlp_write": {ID: 2, UserID: 2, Scopes: entities.ScopeWishlistsWrite, User: &entities.User{Id: 2, Email: "bot@example.com"}},
+	}
+
+	serve := func(method, authorization string) *httptest.ResponseRecorder {
+		e := echo.New()
+		g := e.Group("", JWTOrPersonalAccessToken(fakeDenylist{}, tokens), RequireScopes(entities.ScopeWishlistsRead, entities.ScopeWishlistsWrite))
+		handler := func(ctx echo.Context) error {
+			claims, err := helper.GetClaims(ctx)
+			if err != nil {
+				return err
+			}
+			return ctx.String(http.StatusOK, strconv.Itoa(claims.Id))
+		}
+		g.GET("/", handler)
+		g.POST("/", handler)
+		req := httptest.NewRequest(method, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("JWT", func(t *testing.T) {
+		rec := serve(http.MethodPost, "Bearer "+jwtToken.Token)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1", rec.Body.String())
+	})
+
+	t.Run("Read token reads", func(t *testing.T) {
+		rec := serve(http.MethodGet, "Bearer wlp_read")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Body.String())
+	})
+
+	t.Run("Read token cannot write", func(t *testing.T) {
+		rec := serve(http.MethodPost, "Bearer wlp_read")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "wishlists:write")
+	})
+
+	t.Run("Write token reads and writes", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(http.MethodGet, "Bearer wlp_write").Code)
+		assert.Equal(t, http.StatusOK, serve(http.MethodPost, "Bearer wlp_write").Code)
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		rec := serve(http.MethodGet, "Bearer wlp_unknown")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
@@ -0,0 +1,160 @@
+package oidc
+
+import (
+	"context"
+	"go-wishlist-api-2/oidc/oidctest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURL = "http://localhost:1323/auth/oidc/test/callback"
+
+// signIn runs the authorization code flow against fake up to the callback
+// and returns the code and state it carries.
+func signIn(t *testing.T, provider *Provider, fake *oidctest.Provider, request *AuthRequest) (string, string) {
+	authURL, err := provider.AuthCodeURL(context.Background(), request)
+	assert.NoError(t, err)
+	callback, err := fake.Authorize(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, redirectURL, callback.Scheme+"://"+callback.Host+callback.Path)
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+func newTestProvider(fake *oidctest.Provider) *Provider {
+	return NewProvider("test", Config{
+		Issuer:       fake.Issuer(),
+		ClientID:     fake.ClientID,
+		ClientSecret: fake.ClientSecret,
+		RedirectURL:  redirectURL,
+	}, nil)
+}
+
+func TestProvider(t *testing.T) {
+	fake := oidctest.NewProvider("client", "secret")
+	defer fake.Close()
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		provider := newTestProvider(fake)
+		request, err := NewAuthRequest()
+		assert.NoError(t, err)
+		code, state := signIn(t, provider, fake, request)
+		assert.Equal(t, request.State, state)
+
+		idToken, err := provider.Exchange(ctx, code, request.CodeVerifier)
+		assert.NoError(t, err)
+		identity, err := provider.Verify(ctx, idToken, request.Nonce)
+		assert.NoError(t, err)
+		assert.Equal(t, &Identity{Subject: "1234567890", Email: "user@example.com", EmailVerified: true, Name: "Test User"}, identity)
+	})
+
+	t.Run("Wrong code verifier", func(t *testing.T) {
+		provider := newTestProvider(fake)
+		request, _ := NewAuthRequest()
+		code, _ := signIn(t, provider, fake, request)
+		_, err := provider.Exchange(ctx, code, "wrong")
+		assert.ErrorIs(t, err, ErrTokenExchange)
+	})
+
+	t.Run("Code is single use", func(t *testing.T) {
+		provider := newTestProvider(fake)
+		request, _ := NewAuthRequest()
+		code, _ := signIn(t, provider, fake, request)
+		_, err := provider.Exchange(ctx, code, request.CodeVerifier)
+		assert.NoError(t, err)
+		_, err = provider.Exchange(ctx, code, request.CodeVerifier)
+		assert.ErrorIs(t, err, ErrTokenExchange)
+	})
+
+	t.Run("Wrong client secret", func(t *testing.T) {
+		provider := NewProvider("test", Config{Issuer: fake.Issuer(), ClientID: "client", ClientSecret: "wrong", RedirectURL: redirectURL}, nil)
+		request, _ := NewAuthRequest()
+		code, _ := signIn(t, provider, fake, request)
+		_, err := provider.Exchange(ctx, code, request.CodeVerifier)
+		assert.ErrorIs(t, err, ErrTokenExchange)
+	})
+
+	t.Run("Nonce mismatch", func(t *testing.T) {
+		provider := newTestProvider(fake)
+		request, _ := NewAuthRequest()
+		code, _ := signIn(t, provider, fake, request)
+		idToken, err := provider.Exchange(ctx, code, request.CodeVerifier)
+		assert.NoError(t, err)
+		_, err = provider.Verify(ctx, idToken, "other")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	for name, modify := range map[string]func(jwt.MapClaims){
+		"Wrong audience": func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+		"Wrong issuer":   func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+		"Expired":        func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"Missing expiry": func(claims jwt.MapClaims) { delete(claims, "exp") },
+		"Missing azp with several audiences": func(claims jwt.MapClaims) {
+			claims["aud"] = []string{"client", "other-client"}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fake.ModifyClaims = modify
+			defer func() { fake.ModifyClaims = nil }()
+			provider := newTestProvider(fake)
+			request, _ := NewAuthRequest()
+			code, _ := signIn(t, provider, fake, request)
+			idToken, err := provider.Exchange(ctx, code, request.CodeVerifier)
+			assert.NoError(t, err)
+			_, err = provider.Verify(ctx, idToken, request.Nonce)
+			assert.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+
+	t.Run("Email verified as string", func(t *testing.T) {
+		fake.ModifyClaims = func(claims jwt.MapClaims) { claims["email_verified"] = "true" }
+		defer func() { fake.ModifyClaims = nil }()
+		provider := newTestProvider(fake)
+		request, _ := NewAuthRequest()
+		code, _ := signIn(t, provider, fake, request)
+		idToken, _ := provider.Exchange(ctx, code, request.CodeVerifier)
+		identity, err := provider.Verify(ctx, idToken, request.Nonce)
+		assert.NoError(t, err)
+		assert.True(t, identity.EmailVerified)
+	})
+
+	t.Run("Concurrent verifications fetch the keys once", func(t *testing.T) {
+		provider := newTestProvider(fake)
+		const n = 5
+		idTokens := make([]string, n)
+		requests := make([]*AuthRequest, n)
+		for i := range idTokens {
+			requests[i], _ = NewAuthRequest()
+			code, _ := signIn(t, provider, fake, requests[i])
+			idTokens[i], _ = provider.Exchange(ctx, code, requests[i].CodeVerifier)
+		}
+		before := fake.JWKSRequests()
+
+		var wg sync.WaitGroup
+		for i := range idTokens {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := provider.Verify(ctx, idTokens[i], requests[i].Nonce)
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, 1, fake.JWKSRequests()-before)
+	})
+
+	t.Run("Issuer mismatch in discovery", func(t *testing.T) {
+		provider := NewProvider("test", Config{Issuer: fake.Issuer() + "/other", ClientID: "client", RedirectURL: redirectURL}, nil)
+		_, err := provider.AuthCodeURL(ctx, &AuthRequest{})
+		assert.ErrorIs(t, err, ErrDiscovery)
+	})
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// Example from RFC 7636 Appendix B.
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
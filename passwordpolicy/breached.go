@@ -0,0 +1,62 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BreachedPasswords tells whether a password is known from data breaches.
+type BreachedPasswords interface {
+	IsBreached(password string) (bool, error)
+}
+
+// HashPrefixDir looks passwords up in a local copy of a breached password
+// corpus split by hash prefix, the layout of the Have I Been Pwned range API.
+// The SHA-1 of a password is upper case hex; its first five characters name
+// the file and every line of the file is "<remaining 35 characters>:<count>".
+// Only one small file is read per lookup.
+type HashPrefixDir struct {
+	Dir string
+}
+
+const hashPrefixLength = 5
+
+func (d HashPrefixDir) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+
+	file, err := d.open(prefix)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// open opens the file of prefix, which may be stored with or without a .txt
+// extension.
+func (d HashPrefixDir) open(prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(d.Dir, prefix))
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.Open(filepath.Join(d.Dir, prefix+".txt"))
+	}
+	return file, err
+}
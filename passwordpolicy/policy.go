@@ -0,0 +1,86 @@
+// Package passwordpolicy decides whether a password is acceptable for an
+// account.
+package passwordpolicy
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Policy lists the requirements a new password has to meet.
+type Policy struct {
+	MinLength int
+	// MaxLength is measured in bytes since bcrypt only uses the first 72.
+	MaxLength int
+	// MinCharacterClasses is how many of lowercase letters, uppercase
+	// letters, digits and symbols the password has to contain.
+	MinCharacterClasses int
+	// DisallowEmail rejects the account's email address or its local part as
+	// the password.
+	DisallowEmail bool
+	// Breached, if set, rejects passwords known from data breaches.
+	Breached BreachedPasswords
+}
+
+var DefaultPolicy = Policy{
+	MinLength:           8,
+	MaxLength:           72,
+	MinCharacterClasses: 2,
+	DisallowEmail:       true,
+}
+
+// Validate returns a message for every requirement password does not meet.
+// It only returns an error if the breached password check failed.
+func (p Policy) Validate(password, email string) ([]string, error) {
+	var problems []string
+	if utf8.RuneCountInString(password) < p.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		problems = append(problems, fmt.Sprintf("must be at most %d bytes long", p.MaxLength))
+	}
+	if characterClasses(password) < p.MinCharacterClasses {
+		problems = append(problems, fmt.Sprintf("must contain at least %d of: lowercase letters, uppercase letters, digits, symbols", p.MinCharacterClasses))
+	}
+	if p.DisallowEmail && email != "" && isEmail(password, email) {
+		problems = append(problems, "must not be your email address")
+	}
+	if len(problems) > 0 || p.Breached == nil {
+		return problems, nil
+	}
+
+	breached, err := p.Breached.IsBreached(password)
+	if err != nil {
+		return nil, err
+	}
+	if breached {
+		problems = append(problems, "has appeared in a data breach, please choose a different one")
+	}
+	return problems, nil
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+func isEmail(password, email string) bool {
+	password = strings.ToLower(strings.TrimSpace(password))
+	email = strings.ToLower(email)
+	local, _, _ := strings.Cut(email, "@")
+	return password == email || password == local
+}
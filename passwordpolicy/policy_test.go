@@ -0,0 +1,53 @@
+package passwordpolicy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		password string
+		problems int
+	}{
+		{name: "Valid", password: "correct horse 42"},
+		{name: "Too short", password: "ab1", problems: 1},
+		{name: "Too long", password: string(make([]byte, 73)) + "a1", problems: 1},
+		{name: "One character class", password: "abcdefghij", problems: 1},
+		{name: "Email", password: "Admin.User1@Example.com", problems: 1},
+		{name: "Email local part", password: "admin.user1", problems: 1},
+		{name: "Short and one class", password: "abc", problems: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			problems, err := DefaultPolicy.Validate(tc.password, "admin.user1@example.com")
+			assert.NoError(t, err)
+			assert.Len(t, problems, tc.problems)
+		})
+	}
+}
+
+func TestHashPrefixDir(t *testing.T) {
+	dir := t.TempDir()
+	// SHA-1 of "P@ssw0rd" is 21BD12DC183F740EE76F27B78EB39C8AD972A757.
+	err := os.WriteFile(filepath.Join(dir, "21BD1"), []byte("0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n2DC183F740EE76F27B78EB39C8AD972A757:52579\r\n"), 0o644)
+	assert.NoError(t, err)
+	breached := HashPrefixDir{Dir: dir}
+
+	found, err := breached.IsBreached("P@ssw0rd")
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	found, err = breached.IsBreached("P@ssw0rd!")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	policy := DefaultPolicy
+	policy.Breached = breached
+	problems, err := policy.Validate("P@ssw0rd", "admin@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"has appeared in a data breach, please choose a different one"}, problems)
+}
@@ -0,0 +1,42 @@
+// Package rbac defines what each user role is allowed to do.
+package rbac
+
+import "go-wishlist-api-2/entities"
+
+type Permission string
+
+const (
+	// ListUsers allows listing and looking up any account.
+	ListUsers Permission = "users:list"
+	// ManageUsers allows disabling, enabling and unlocking accounts.
+	ManageUsers Permission = "users:manage"
+	// ManageRoles allows changing the role of an account.
+	ManageRoles Permission = "users:roles"
+	// ReadAnyWishlist allows reading wishlists of other users.
+	ReadAnyWishlist Permission = "wishlists:read_any"
+	// ReadAuditLog allows reading the audit records of account deletions.
+	ReadAuditLog Permission = "audit:read"
+)
+
+var rolePermissions = map[string][]Permission{
+	entities.RoleUser:      {},
+	entities.RoleModerator: {ListUsers, ReadAnyWishlist},
+	entities.RoleAdmin:     {ListUsers, ManageUsers, ManageRoles, ReadAnyWishlist, ReadAuditLog},
+}
+
+// Can reports whether role grants permission. Unknown roles, including the
+// empty role of tokens issued before roles existed, grant nothing.
+func Can(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidRole reports whether role is one of the defined roles.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
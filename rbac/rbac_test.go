@@ -0,0 +1,24 @@
+package rbac
+
+import (
+	"go-wishlist-api-2/entities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCan(t *testing.T) {
+	assert.False(t, Can(entities.RoleUser, ListUsers))
+	assert.True(t, Can(entities.RoleModerator, ReadAnyWishlist))
+	assert.False(t, Can(entities.RoleModerator, ManageUsers))
+	assert.True(t, Can(entities.RoleAdmin, ManageRoles))
+	assert.False(t, Can(entities.RoleModerator, ReadAuditLog))
+	assert.False(t, Can("", ListUsers))
+	assert.False(t, Can("root", ListUsers))
+}
+
+func TestValidRole(t *testing.T) {
+	assert.True(t, ValidRole(entities.RoleModerator))
+	assert.False(t, ValidRole(""))
+	assert.False(t, ValidRole("root"))
+}
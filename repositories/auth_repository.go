@@ -1,14 +1,58 @@
 package repositories
 
 import (
+	"errors"
 	"go-wishlist-api-2/entities"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrResetTokenUsed is returned when a password reset token was used in the
+// meantime, e.g. by a concurrent request.
+var ErrResetTokenUsed = errors.New("password reset token already used")
+
+// ErrEmailChangeStale is returned when the pending email change being
+// confirmed was replaced or confirmed in the meantime.
+var ErrEmailChangeStale = errors.New("pending email change no longer matches")
+
+// ErrTOTPAlreadyConfirmed is returned when a TOTP credential was confirmed by
+// a concurrent request.
+var ErrTOTPAlreadyConfirmed = errors.New("totp credential already confirmed")
+
+// ErrTOTPCodeReused is returned when a TOTP code of an already used time step
+// is presented again.
+var ErrTOTPCodeReused = errors.New("totp code already used")
+
+// ErrRecoveryCodeInvalid is returned when a recovery code does not exist or
+// was already used.
+var ErrRecoveryCodeInvalid = errors.New("recovery code invalid or already used")
+
 type AuthRepository interface {
 	FindByEmail(email string) (*entities.User, error)
+	FindByID(id int) (*entities.User, error)
 	CreateUser(user *entities.User) (*entities.User, error)
+	UpdateUser(user *entities.User) (*entities.User, error)
+	CreatePasswordResetToken(token *entities.PasswordResetToken) (*entities.PasswordResetToken, error)
+	FindPasswordResetTokenByHash(hash string) (*entities.PasswordResetToken, error)
+	CountPasswordResetTokensSince(userID int, since time.Time) (int64, error)
+	ResetPassword(token *entities.PasswordResetToken, user *entities.User) error
+	UpdatePassword(user *entities.User, keepAccessJTI string, revokePersonalAccessTokens bool) error
+	UpdatePasswordHash(userID int, oldHash, newHash string) error
+	UpdateEmail(userID int, email string) error
+	FindTOTPCredential(userID int) (*entities.TOTPCredential, error)
+	SaveTOTPCredential(credential *entities.TOTPCredential) error
+	ConfirmTOTPCredential(credential *entities.TOTPCredential, codes []*entities.RecoveryCode) error
+	DeleteTOTPCredential(userID int) error
+	UseTOTPStep(userID int, step int64) error
+	UseRecoveryCode(userID int, hash string) error
+	FindExternalIdentity(provider, subject string) (*entities.ExternalIdentity, error)
+	CreateExternalIdentity(identity *entities.ExternalIdentity) error
+	CreateUserWithIdentity(user *entities.User, identity *entities.ExternalIdentity) (*entities.User, error)
+	CreateOIDCLoginState(state *entities.OIDCLoginState) error
+	ConsumeOIDCLoginState(stateHash string) (*entities.OIDCLoginState, error)
+	CancelAccountDeletion(userID int) error
 }
 
 type authRepository struct {
@@ -27,6 +71,14 @@ func (r *authRepository) FindByEmail(email string) (*entities.User, error) {
 	return user, nil
 }
 
+func (r *authRepository) FindByID(id int) (*entities.User, error) {
+	var user *entities.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 func (r *authRepository) CreateUser(user *entities.User) (*entities.User, error) {
 
 	if err := r.db.Create(&user).Error; err != nil {
@@ -35,3 +87,261 @@ func (r *authRepository) CreateUser(user *entities.User) (*entities.User, error)
 	return user, nil
 
 }
+
+func (r *authRepository) UpdateUser(user *entities.User) (*entities.User, error) {
+	if err := r.db.Save(&user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *authRepository) CreatePasswordResetToken(token *entities.PasswordResetToken) (*entities.PasswordResetToken, error) {
+	if err := r.db.Create(&token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *authRepository) FindPasswordResetTokenByHash(hash string) (*entities.PasswordResetToken, error) {
+	var token *entities.PasswordResetToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *authRepository) CountPasswordResetTokensSince(userID int, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&entities.PasswordResetToken{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ResetPassword consumes token, saves the user's new password, invalidates
+// the user's other reset tokens, signs the user out everywhere and deletes the
+// user's personal access tokens, all in one transaction.
+func (r *authRepository) ResetPassword(token *entities.PasswordResetToken, user *entities.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&entities.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrResetTokenUsed
+		}
+		err := tx.Model(&entities.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.Id).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		if err := deletePersonalAccessTokens(tx, user.Id); err != nil {
+			return err
+		}
+		return revokeUserTokens(tx, user.Id, "")
+	})
+}
+
+// UpdatePassword saves the user's new password hash and signs out every other
+// session of the user. The session of keepAccessJTI stays signed in. The
+// user's personal access tokens are only deleted when
+// revokePersonalAccessTokens is set.
+func (r *authRepository) UpdatePassword(user *entities.User, keepAccessJTI string, revokePersonalAccessTokens bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entities.User{}).
+			Where("id = ?", user.Id).
+			Update("password", user.Password).Error
+		if err != nil {
+			return err
+		}
+		if revokePersonalAccessTokens {
+			if err := deletePersonalAccessTokens(tx, user.Id); err != nil {
+				return err
+			}
+		}
+		return revokeUserTokens(tx, user.Id, keepAccessJTI)
+	})
+}
+
+// UpdatePasswordHash replaces the stored hash of an unchanged password, e.g.
+// to upgrade it to new hashing parameters. Nothing is updated if the password
+// was changed since oldHash was read.
+func (r *authRepository) UpdatePasswordHash(userID int, oldHash, newHash string) error {
+	return r.db.Model(&entities.User{}).
+		Where("id = ? AND password = ?", userID, oldHash).
+		UpdateColumn("password", newHash).Error
+}
+
+// UpdateEmail replaces the user's address with the pending address email and
+// marks it verified. It fails with ErrEmailChangeStale unless email is still
+// the user's pending address.
+func (r *authRepository) UpdateEmail(userID int, email string) error {
+	result := r.db.Model(&entities.User{}).
+		Where("id = ? AND pending_email = ?", userID, email).
+		Updates(map[string]any{
+			"email":             email,
+			"pending_email":     nil,
+			"email_verified_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEmailChangeStale
+	}
+	return nil
+}
+
+// CancelAccountDeletion keeps an account that was scheduled for deletion and
+// marks its pending deletion as cancelled.
+func (r *authRepository) CancelAccountDeletion(userID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entities.User{}).
+			Where("id = ?", userID).
+			UpdateColumn("deletion_scheduled_at", nil).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&entities.AccountDeletion{}).
+			Where("user_id = ? AND cancelled_at IS NULL AND erased_at IS NULL", userID).
+			UpdateColumn("cancelled_at", time.Now()).Error
+	})
+}
+
+func (r *authRepository) FindTOTPCredential(userID int) (*entities.TOTPCredential, error) {
+	var credential *entities.TOTPCredential
+	if err := r.db.Where("user_id = ?", userID).First(&credential).Error; err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// SaveTOTPCredential creates or replaces the user's TOTP credential.
+func (r *authRepository) SaveTOTPCredential(credential *entities.TOTPCredential) error {
+	return r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "confirmed_at", "last_used_step", "updated_at"}),
+	}).Create(credential).Error
+}
+
+// ConfirmTOTPCredential enables the pending credential and replaces the
+// user's recovery codes with codes. It fails with ErrTOTPAlreadyConfirmed if
+// the credential was confirmed in the meantime.
+func (r *authRepository) ConfirmTOTPCredential(credential *entities.TOTPCredential, codes []*entities.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.TOTPCredential{}).
+			Where("user_id = ? AND confirmed_at IS NULL", credential.UserID).
+			Updates(map[string]any{
+				"confirmed_at":   credential.ConfirmedAt,
+				"last_used_step": credential.LastUsedStep,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTOTPAlreadyConfirmed
+		}
+		if err := tx.Where("user_id = ?", credential.UserID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// DeleteTOTPCredential turns two-factor authentication off for the user.
+func (r *authRepository) DeleteTOTPCredential(userID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&entities.TOTPCredential{}).Error
+	})
+}
+
+// UseTOTPStep records step as the user's last used TOTP time step. It fails
+// with ErrTOTPCodeReused unless step is newer than the last used one.
+func (r *authRepository) UseTOTPStep(userID int, step int64) error {
+	result := r.db.Model(&entities.TOTPCredential{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTOTPCodeReused
+	}
+	return nil
+}
+
+// UseRecoveryCode marks the user's recovery code with the given hash as used.
+func (r *authRepository) UseRecoveryCode(userID int, hash string) error {
+	result := r.db.Model(&entities.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecoveryCodeInvalid
+	}
+	return nil
+}
+
+func (r *authRepository) FindExternalIdentity(provider, subject string) (*entities.ExternalIdentity, error) {
+	var identity *entities.ExternalIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (r *authRepository) CreateExternalIdentity(identity *entities.ExternalIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// CreateUserWithIdentity creates a user signing in with an external identity
+// for the first time together with the link to that identity.
+func (r *authRepository) CreateUserWithIdentity(user *entities.User, identity *entities.ExternalIdentity) (*entities.User, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.Id
+		return tx.Create(identity).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *authRepository) CreateOIDCLoginState(state *entities.OIDCLoginState) error {
+	return r.db.Create(state).Error
+}
+
+// ConsumeOIDCLoginState returns and deletes the login state with the given
+// hash. Of several concurrent calls with the same hash only one succeeds, the
+// others get gorm.ErrRecordNotFound.
+func (r *authRepository) ConsumeOIDCLoginState(stateHash string) (*entities.OIDCLoginState, error) {
+	var state *entities.OIDCLoginState
+	if err := r.db.Where("state_hash = ?", stateHash).First(&state).Error; err != nil {
+		return nil, err
+	}
+	result := r.db.Where("state_hash = ?", stateHash).Delete(&entities.OIDCLoginState{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return state, nil
+}
@@ -64,6 +64,24 @@ func TestAuthRepository(t *testing.T) {
 				assert.Nil(t, user)
 			},
 		},
+		{
+			name: "FindByID - success",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				rows := sqlmock.
+					NewRows([]string{"id", "email", "password"}).
+					AddRow(1, "admin@example.com", "admin123")
+
+				query := "SELECT * FROM `users` WHERE `users`.`id` = ? ORDER BY `users`.`id` LIMIT ?"
+				mock.ExpectQuery(query).
+					WithArgs(1, 1).
+					WillReturnRows(rows)
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+				assert.NotNil(t, user)
+				assert.Equal(t, user.Email, "admin@example.com")
+			},
+		},
 		{
 			name: "Create - success",
 			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
@@ -77,9 +95,9 @@ func TestAuthRepository(t *testing.T) {
 
 				mock.ExpectBegin()
 
-				query := "INSERT INTO `users` (`email`,`password`,`created_at`,`updated_at`,`id`) VALUES (?,?,?,?,?)"
+				query := "INSERT INTO `users` (`email`,`password`,`email_verified_at`,`verification_sent_at`,`pending_email`,`role`,`disabled_at`,`display_name`,`avatar_url`,`birthday`,`locale`,`timezone`,`default_currency`,`deletion_scheduled_at`,`created_at`,`updated_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
 				mock.ExpectExec(query).
-					WithArgs(user.Email, user.Password, user.CreatedAt, user.UpdatedAt, user.Id).
+					WithArgs(user.Email, user.Password, nil, nil, nil, "user", nil, "", "", nil, "", "", "", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), user.Id).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit()
@@ -90,6 +108,23 @@ func TestAuthRepository(t *testing.T) {
 				assert.Equal(t, user.Email, "admin@example.com")
 			},
 		},
+		{
+			name: "Update - success",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+
+				query := "UPDATE `users` SET `email`=?,`password`=?,`email_verified_at`=?,`verification_sent_at`=?,`pending_email`=?,`role`=?,`disabled_at`=?,`display_name`=?,`avatar_url`=?,`birthday`=?,`locale`=?,`timezone`=?,`default_currency`=?,`deletion_scheduled_at`=?,`created_at`=?,`updated_at`=? WHERE `id` = ?"
+				mock.ExpectExec(query).
+					WithArgs("admin@example.com", "admin123", sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg(), nil, "", "", nil, "", "", "", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+				assert.NotNil(t, user.EmailVerifiedAt)
+			},
+		},
 		{
 			name: "Create - error",
 			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
@@ -103,9 +138,9 @@ func TestAuthRepository(t *testing.T) {
 
 				mock.ExpectBegin()
 
-				query := "INSERT INTO `users` (`email`,`password`,`created_at`,`updated_at`,`id`) VALUES (?,?,?,?,?)"
+				query := "INSERT INTO `users` (`email`,`password`,`email_verified_at`,`verification_sent_at`,`pending_email`,`role`,`disabled_at`,`display_name`,`avatar_url`,`birthday`,`locale`,`timezone`,`default_currency`,`deletion_scheduled_at`,`created_at`,`updated_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
 				mock.ExpectExec(query).
-					WithArgs(user.Email, user.Password, user.CreatedAt, user.UpdatedAt, user.Id).
+					WithArgs(user.Email, user.Password, nil, nil, nil, "user", nil, "", "", nil, "", "", "", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), user.Id).
 					WillReturnError(fmt.Errorf("Failed create user"))
 
 				mock.ExpectRollback()
@@ -115,6 +150,243 @@ func TestAuthRepository(t *testing.T) {
 				assert.Nil(t, user)
 			},
 		},
+		{
+			name: "ResetPassword - success",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `password_reset_tokens` SET `used_at`=? WHERE id = ? AND used_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `password_reset_tokens` SET `used_at`=? WHERE user_id = ? AND used_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `users` SET `email`=?,`password`=?,`email_verified_at`=?,`verification_sent_at`=?,`pending_email`=?,`role`=?,`disabled_at`=?,`display_name`=?,`avatar_url`=?,`birthday`=?,`locale`=?,`timezone`=?,`default_currency`=?,`deletion_scheduled_at`=?,`created_at`=?,`updated_at`=? WHERE `id` = ?").
+					WithArgs("admin@example.com", "new-hash", sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg(), nil, "", "", nil, "", "", "", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `personal_access_tokens` WHERE user_id = ?").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectQuery("SELECT * FROM `refresh_tokens` WHERE user_id = ? AND revoked_at IS NULL").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "access_jti", "access_expires_at"}).
+						AddRow(3, 1, "jti-3", time.Now().Add(time.Minute)))
+				mock.ExpectExec("INSERT INTO `revoked_tokens` (`jti`,`expires_at`,`created_at`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `jti`=`jti`").
+					WithArgs("jti-3", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `refresh_tokens` SET `revoked_at`=? WHERE user_id = ? AND revoked_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "ResetPassword - token already used",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `password_reset_tokens` SET `used_at`=? WHERE id = ? AND used_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 7).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.ErrorIs(t, err, ErrResetTokenUsed)
+			},
+		},
+		{
+			name: "UpdatePassword - success",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET `password`=?,`updated_at`=? WHERE id = ?").
+					WithArgs("new-hash", sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT * FROM `refresh_tokens` WHERE (user_id = ? AND revoked_at IS NULL) AND access_jti <> ?").
+					WithArgs(1, "current-jti").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec("UPDATE `refresh_tokens` SET `revoked_at`=? WHERE (user_id = ? AND revoked_at IS NULL) AND access_jti <> ?").
+					WithArgs(sqlmock.AnyArg(), 1, "current-jti").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "UpdatePassword - revoke personal access tokens",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET `password`=?,`updated_at`=? WHERE id = ?").
+					WithArgs("new-hash", sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `personal_access_tokens` WHERE user_id = ?").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT * FROM `refresh_tokens` WHERE (user_id = ? AND revoked_at IS NULL) AND access_jti <> ?").
+					WithArgs(1, "current-jti").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec("UPDATE `refresh_tokens` SET `revoked_at`=? WHERE (user_id = ? AND revoked_at IS NULL) AND access_jti <> ?").
+					WithArgs(sqlmock.AnyArg(), 1, "current-jti").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "UpdatePasswordHash - success",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET `password`=? WHERE id = ? AND password = ?").
+					WithArgs("new-hash", 1, "old-hash").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "UpdateEmail - success",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET `email`=?,`email_verified_at`=?,`pending_email`=?,`updated_at`=? WHERE id = ? AND pending_email = ?").
+					WithArgs("new@example.com", sqlmock.AnyArg(), nil, sqlmock.AnyArg(), 1, "new@example.com").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "UpdateEmail - stale",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET `email`=?,`email_verified_at`=?,`pending_email`=?,`updated_at`=? WHERE id = ? AND pending_email = ?").
+					WithArgs("new@example.com", sqlmock.AnyArg(), nil, sqlmock.AnyArg(), 1, "new@example.com").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.ErrorIs(t, err, ErrEmailChangeStale)
+			},
+		},
+		{
+			name: "UseTOTPStep - reused",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `totp_credentials` SET `last_used_step`=?,`updated_at`=? WHERE user_id = ? AND last_used_step < ?").
+					WithArgs(int64(100), sqlmock.AnyArg(), 1, int64(100)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.ErrorIs(t, err, ErrTOTPCodeReused)
+			},
+		},
+		{
+			name: "UseRecoveryCode - success",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `recovery_codes` SET `used_at`=? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 1, "hash").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "ConfirmTOTPCredential - success",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `totp_credentials` SET `confirmed_at`=?,`last_used_step`=?,`updated_at`=? WHERE user_id = ? AND confirmed_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), int64(100), sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `recovery_codes` WHERE user_id = ?").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 10))
+				mock.ExpectExec("INSERT INTO `recovery_codes` (`user_id`,`code_hash`,`used_at`,`created_at`) VALUES (?,?,?,?),(?,?,?,?)").
+					WithArgs(1, "hash-1", nil, sqlmock.AnyArg(), 1, "hash-2", nil, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "CreateUserWithIdentity - success",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `users` (`email`,`password`,`email_verified_at`,`verification_sent_at`,`pending_email`,`role`,`disabled_at`,`display_name`,`avatar_url`,`birthday`,`locale`,`timezone`,`default_currency`,`deletion_scheduled_at`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)").
+					WithArgs("user@example.com", "hash", sqlmock.AnyArg(), nil, nil, "user", nil, "", "", nil, "", "", "", nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(5, 1))
+				mock.ExpectExec("INSERT INTO `external_identities` (`user_id`,`provider`,`subject`,`email`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?)").
+					WithArgs(5, "google", "sub-1", "user@example.com", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+				assert.Equal(t, 5, user.Id)
+			},
+		},
+		{
+			name: "ConsumeOIDCLoginState - success",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectQuery("SELECT * FROM `oidc_login_states` WHERE state_hash = ? ORDER BY `oidc_login_states`.`state_hash` LIMIT ?").
+					WithArgs("hash", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"state_hash", "provider", "nonce", "code_verifier"}).
+						AddRow("hash", "google", "nonce", "verifier"))
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `oidc_login_states` WHERE state_hash = ?").
+					WithArgs("hash").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "ConsumeOIDCLoginState - consumed concurrently",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectQuery("SELECT * FROM `oidc_login_states` WHERE state_hash = ? ORDER BY `oidc_login_states`.`state_hash` LIMIT ?").
+					WithArgs("hash", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"state_hash", "provider", "nonce", "code_verifier"}).
+						AddRow("hash", "google", "nonce", "verifier"))
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `oidc_login_states` WHERE state_hash = ?").
+					WithArgs("hash").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "CancelAccountDeletion - success",
+			setup: func(mock sqlmock.Sqlmock, repo authRepository) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET `deletion_scheduled_at`=? WHERE id = ?").
+					WithArgs(nil, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `account_deletions` SET `cancelled_at`=? WHERE user_id = ? AND cancelled_at IS NULL AND erased_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, user *entities.User) {
+				assert.NoError(t, err)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -134,6 +406,9 @@ func TestAuthRepository(t *testing.T) {
 			} else if tc.name == "FindByEmail - error" {
 				_, err := repo.FindByEmail("admin@example.com")
 				tc.assertion(t, err, nil)
+			} else if tc.name == "FindByID - success" {
+				user, err := repo.FindByID(1)
+				tc.assertion(t, err, user)
 			} else if tc.name == "Create - success" {
 				user := &entities.User{
 					Id:        1,
@@ -144,6 +419,17 @@ func TestAuthRepository(t *testing.T) {
 				}
 				got, err := repo.CreateUser(user)
 				tc.assertion(t, err, got)
+			} else if tc.name == "Update - success" {
+				verifiedAt := time.Now()
+				user := &entities.User{
+					Id:              1,
+					Email:           "admin@example.com",
+					Password:        "admin123",
+					EmailVerifiedAt: &verifiedAt,
+					CreatedAt:       time.Now(),
+				}
+				got, err := repo.UpdateUser(user)
+				tc.assertion(t, err, got)
 			} else if tc.name == "Create - error" {
 				user := &entities.User{
 					Id:        1,
@@ -154,6 +440,53 @@ func TestAuthRepository(t *testing.T) {
 				}
 				_, err := repo.CreateUser(user)
 				tc.assertion(t, err, nil)
+			} else if tc.name == "ResetPassword - success" || tc.name == "ResetPassword - token already used" {
+				verifiedAt := time.Now()
+				user := &entities.User{
+					Id:              1,
+					Email:           "admin@example.com",
+					Password:        "new-hash",
+					EmailVerifiedAt: &verifiedAt,
+					CreatedAt:       time.Now(),
+				}
+				err := repo.ResetPassword(&entities.PasswordResetToken{ID: 7, UserID: 1}, user)
+				tc.assertion(t, err, nil)
+			} else if tc.name == "UpdatePassword - success" {
+				err := repo.UpdatePassword(&entities.User{Id: 1, Password: "new-hash"}, "current-jti", false)
+				tc.assertion(t, err, nil)
+			} else if tc.name == "UpdatePassword - revoke personal access tokens" {
+				err := repo.UpdatePassword(&entities.User{Id: 1, Password: "new-hash"}, "current-jti", true)
+				tc.assertion(t, err, nil)
+			} else if tc.name == "UpdatePasswordHash - success" {
+				err := repo.UpdatePasswordHash(1, "old-hash", "new-hash")
+				tc.assertion(t, err, nil)
+			} else if tc.name == "UpdateEmail - success" || tc.name == "UpdateEmail - stale" {
+				err := repo.UpdateEmail(1, "new@example.com")
+				tc.assertion(t, err, nil)
+			} else if tc.name == "UseTOTPStep - reused" {
+				err := repo.UseTOTPStep(1, 100)
+				tc.assertion(t, err, nil)
+			} else if tc.name == "UseRecoveryCode - success" {
+				err := repo.UseRecoveryCode(1, "hash")
+				tc.assertion(t, err, nil)
+			} else if tc.name == "ConfirmTOTPCredential - success" {
+				confirmedAt := time.Now()
+				credential := &entities.TOTPCredential{UserID: 1, ConfirmedAt: &confirmedAt, LastUsedStep: 100}
+				codes := []*entities.RecoveryCode{{UserID: 1, CodeHash: "hash-1"}, {UserID: 1, CodeHash: "hash-2"}}
+				err := repo.ConfirmTOTPCredential(credential, codes)
+				tc.assertion(t, err, nil)
+			} else if tc.name == "CreateUserWithIdentity - success" {
+				verifiedAt := time.Now()
+				user, err := repo.CreateUserWithIdentity(
+					&entities.User{Email: "user@example.com", Password: "hash", EmailVerifiedAt: &verifiedAt},
+					&entities.ExternalIdentity{Provider: "google", Subject: "sub-1", Email: "user@example.com"},
+				)
+				tc.assertion(t, err, user)
+			} else if tc.name == "ConsumeOIDCLoginState - success" || tc.name == "ConsumeOIDCLoginState - consumed concurrently" {
+				_, err := repo.ConsumeOIDCLoginState("hash")
+				tc.assertion(t, err, nil)
+			} else if tc.name == "CancelAccountDeletion - success" {
+				tc.assertion(t, repo.CancelAccountDeletion(1), nil)
 			}
 		})
 	}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"errors"
+	"go-wishlist-api-2/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrClaimLimitReached is returned when a claim would claim more units of a
+// wishlist than its quantity.
+var ErrClaimLimitReached = errors.New("wishlist is already fully claimed")
+
+type ClaimRepository interface {
+	FindByID(id uint) (*entities.GiftClaim, error)
+	FindByWishlistIDs(wishlistIDs []uint) ([]*entities.GiftClaim, error)
+	CreateClaim(claim *entities.GiftClaim) (*entities.GiftClaim, error)
+	UpdateStatus(id uint, status string) error
+	DeleteClaim(id uint) error
+}
+
+type claimRepository struct {
+	db *gorm.DB
+}
+
+func NewClaimRepository(db *gorm.DB) *claimRepository {
+	return &claimRepository{db}
+}
+
+// FindByID returns the claim together with its wishlist, which is nil if
+// the wishlist is in the trash.
+func (r *claimRepository) FindByID(id uint) (*entities.GiftClaim, error) {
+	var claim *entities.GiftClaim
+	if err := r.db.Preload("Wishlist").First(&claim, id).Error; err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+func (r *claimRepository) FindByWishlistIDs(wishlistIDs []uint) ([]*entities.GiftClaim, error) {
+	var claims []*entities.GiftClaim
+	if len(wishlistIDs) == 0 {
+		return claims, nil
+	}
+	if err := r.db.Where("wishlist_id IN ?", wishlistIDs).Order("id").Find(&claims).Error; err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// CreateClaim stores claim unless the wishlist's existing claims and claim
+// together exceed its quantity, in which case it returns
+// ErrClaimLimitReached. The wishlist row stays locked until the claim is
+// stored so that concurrent claims of the same wishlist are checked one after
+// another. It returns gorm.ErrRecordNotFound if the wishlist does not exist.
+func (r *claimRepository) CreateClaim(claim *entities.GiftClaim) (*entities.GiftClaim, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var wishlist entities.Wishlist
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "quantity").
+			First(&wishlist, claim.WishlistID).Error
+		if err != nil {
+			return err
+		}
+		var claimed int64
+		err = tx.Model(&entities.GiftClaim{}).
+			Select("COALESCE(SUM(quantity), 0)").
+			Where("wishlist_id = ?", claim.WishlistID).
+			Scan(&claimed).Error
+		if err != nil {
+			return err
+		}
+		if claimed+int64(claim.Quantity) > int64(wishlist.Quantity) {
+			return ErrClaimLimitReached
+		}
+		return tx.Create(claim).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// UpdateStatus changes the status of the claim. It returns
+// gorm.ErrRecordNotFound if the claim does not exist.
+func (r *claimRepository) UpdateStatus(id uint, status string) error {
+	result := r.db.Model(&entities.GiftClaim{}).Where("id = ?", id).Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteClaim releases the claim. It returns gorm.ErrRecordNotFound if the
+// claim does not exist.
+func (r *claimRepository) DeleteClaim(id uint) error {
+	result := r.db.Delete(&entities.GiftClaim{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
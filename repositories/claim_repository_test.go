@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/entities"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestClaimRepository(t *testing.T) {
+	lockWishlist := "SELECT `id`,`quantity` FROM `wishlists` WHERE `wishlists`.`id` = ? AND `wishlists`.`deleted_at` IS NULL ORDER BY `wishlists`.`id` LIMIT ? FOR UPDATE"
+	sumClaims := "SELECT COALESCE(SUM(quantity), 0) FROM `gift_claims` WHERE wishlist_id = ?"
+
+	testCases := []struct {
+		name      string
+		setup     func(mock sqlmock.Sqlmock)
+		run       func(repo ClaimRepository) ([]*entities.GiftClaim, error)
+		assertion func(t *testing.T, err error, claims []*entities.GiftClaim)
+	}{
+		{
+			name: "FindByWishlistIDs - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.
+					NewRows([]string{"id", "wishlist_id", "name", "status", "quantity"}).
+					AddRow(1, 5, "Grace", entities.ClaimReserved, 1).
+					AddRow(2, 6, "Alan", entities.ClaimPurchased, 2)
+				mock.ExpectQuery("SELECT * FROM `gift_claims` WHERE wishlist_id IN (?,?) ORDER BY id").
+					WithArgs(5, 6).
+					WillReturnRows(rows)
+			},
+			run: func(repo ClaimRepository) ([]*entities.GiftClaim, error) {
+				return repo.FindByWishlistIDs([]uint{5, 6})
+			},
+			assertion: func(t *testing.T, err error, claims []*entities.GiftClaim) {
+				assert.NoError(t, err)
+				assert.Len(t, claims, 2)
+			},
+		},
+		{
+			name:  "FindByWishlistIDs - no wishlists",
+			setup: func(mock sqlmock.Sqlmock) {},
+			run: func(repo ClaimRepository) ([]*entities.GiftClaim, error) {
+				return repo.FindByWishlistIDs(nil)
+			},
+			assertion: func(t *testing.T, err error, claims []*entities.GiftClaim) {
+				assert.NoError(t, err)
+				assert.Empty(t, claims)
+			},
+		},
+		{
+			name: "CreateClaim - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWishlist).
+					WithArgs(5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "quantity"}).AddRow(5, 3))
+				mock.ExpectQuery(sumClaims).
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(1))
+				mock.ExpectExec("INSERT INTO `gift_claims` (`wishlist_id`,`name`,`status`,`quantity`,`token_hash`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?)").
+					WithArgs(5, "Grace", entities.ClaimReserved, 2, "hash", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectCommit()
+			},
+			run: func(repo ClaimRepository) ([]*entities.GiftClaim, error) {
+				claim, err := repo.CreateClaim(&entities.GiftClaim{
+					WishlistID: 5, Name: "Grace", Status: entities.ClaimReserved, Quantity: 2, TokenHash: "hash",
+				})
+				if err != nil {
+					return nil, err
+				}
+				return []*entities.GiftClaim{claim}, nil
+			},
+			assertion: func(t *testing.T, err error, claims []*entities.GiftClaim) {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(7), claims[0].ID)
+			},
+		},
+		{
+			name: "CreateClaim - fully claimed",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWishlist).
+					WithArgs(5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "quantity"}).AddRow(5, 2))
+				mock.ExpectQuery(sumClaims).
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(2))
+				mock.ExpectRollback()
+			},
+			run: func(repo ClaimRepository) ([]*entities.GiftClaim, error) {
+				_, err := repo.CreateClaim(&entities.GiftClaim{WishlistID: 5, Quantity: 1})
+				return nil, err
+			},
+			assertion: func(t *testing.T, err error, claims []*entities.GiftClaim) {
+				assert.ErrorIs(t, err, ErrClaimLimitReached)
+			},
+		},
+		{
+			name: "CreateClaim - wishlist not found",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockWishlist).
+					WithArgs(5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "quantity"}))
+				mock.ExpectRollback()
+			},
+			run: func(repo ClaimRepository) ([]*entities.GiftClaim, error) {
+				_, err := repo.CreateClaim(&entities.GiftClaim{WishlistID: 5, Quantity: 1})
+				return nil, err
+			},
+			assertion: func(t *testing.T, err error, claims []*entities.GiftClaim) {
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "UpdateStatus - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `gift_claims` SET `status`=?,`updated_at`=? WHERE id = ?").
+					WithArgs(entities.ClaimPurchased, sqlmock.AnyArg(), 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(repo ClaimRepository) ([]*entities.GiftClaim, error) {
+				return nil, repo.UpdateStatus(7, entities.ClaimPurchased)
+			},
+			assertion: func(t *testing.T, err error, claims []*entities.GiftClaim) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "DeleteClaim - not found",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `gift_claims` WHERE `gift_claims`.`id` = ?").
+					WithArgs(7).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			run: func(repo ClaimRepository) ([]*entities.GiftClaim, error) {
+				return nil, repo.DeleteClaim(7)
+			},
+			assertion: func(t *testing.T, err error, claims []*entities.GiftClaim) {
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewClaimRepository(CreateGormDB(db))
+			tc.setup(mock)
+
+			claims, err := tc.run(repo)
+			tc.assertion(t, err, claims)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
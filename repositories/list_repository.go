@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"errors"
+	"go-wishlist-api-2/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const DefaultListName = "My Wishlist"
+
+type ListRepository interface {
+	GetAll(userID int) ([]*entities.List, error)
+	FindByID(id uint) (*entities.List, error)
+	FindOrCreateDefault(userID int) (*entities.List, error)
+	CreateList(list *entities.List) (*entities.List, error)
+	UpdateList(list *entities.List) (*entities.List, error)
+	DeleteList(id uint) error
+	FindShare(listID uint) (*entities.ListShare, error)
+	FindShareByTokenHash(hash string) (*entities.ListShare, error)
+	SaveShare(share *entities.ListShare) (*entities.ListShare, error)
+	UpdateShareToken(listID uint, hash string) error
+	UpdateShareRevealClaims(listID uint, reveal bool) error
+	DeleteShare(listID uint) error
+}
+
+type listRepository struct {
+	db *gorm.DB
+}
+
+func NewListRepository(db *gorm.DB) *listRepository {
+	return &listRepository{db}
+}
+
+func (r *listRepository) GetAll(userID int) ([]*entities.List, error) {
+	var lists []*entities.List
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&lists).Error; err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+func (r *listRepository) FindByID(id uint) (*entities.List, error) {
+	var list *entities.List
+	if err := r.db.First(&list, id).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// FindOrCreateDefault returns the user's default list and creates it if the
+// user has none yet. The unique index on DefaultOwner turns concurrent
+// creations into one; the losers read the winner's list.
+func (r *listRepository) FindOrCreateDefault(userID int) (*entities.List, error) {
+	list, err := r.findDefault(userID)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return list, err
+	}
+	err = r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entities.List{UserID: userID, Name: DefaultListName, IsDefault: true}).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.findDefault(userID)
+}
+
+func (r *listRepository) findDefault(userID int) (*entities.List, error) {
+	var list *entities.List
+	if err := r.db.Where("user_id = ? AND is_default = ?", userID, true).First(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *listRepository) CreateList(list *entities.List) (*entities.List, error) {
+	if err := r.db.Create(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *listRepository) UpdateList(list *entities.List) (*entities.List, error) {
+	if err := r.db.Save(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DeleteList moves the list and every item in it to the trash.
+func (r *listRepository) DeleteList(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("list_id = ?", id).Delete(&entities.Wishlist{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.List{}, id).Error
+	})
+}
+
+func (r *listRepository) FindShare(listID uint) (*entities.ListShare, error) {
+	var share *entities.ListShare
+	if err := r.db.Where("list_id = ?", listID).First(&share).Error; err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// FindShareByTokenHash returns the share with the given token hash together
+// with its list and the list's owner. List is nil if the list is in the
+// trash.
+func (r *listRepository) FindShareByTokenHash(hash string) (*entities.ListShare, error) {
+	var share *entities.ListShare
+	if err := r.db.Preload("List.User").Where("token_hash = ?", hash).First(&share).Error; err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// SaveShare stores share as the only share of its list, replacing the
+// previous one and with it the previous link.
+func (r *listRepository) SaveShare(share *entities.ListShare) (*entities.ListShare, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("list_id = ?", share.ListID).Delete(&entities.ListShare{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(share).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// UpdateShareToken replaces the token of the list's share, keeping its
+// password and expiry. It returns gorm.ErrRecordNotFound if the list is not
+// shared.
+func (r *listRepository) UpdateShareToken(listID uint, hash string) error {
+	result := r.db.Model(&entities.ListShare{}).Where("list_id = ?", listID).Update("token_hash", hash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateShareRevealClaims turns surprise mode of the list's share off when
+// reveal is set and back on otherwise.
+func (r *listRepository) UpdateShareRevealClaims(listID uint, reveal bool) error {
+	return r.db.Model(&entities.ListShare{}).Where("list_id = ?", listID).Update("reveal_claims", reveal).Error
+}
+
+// DeleteShare revokes the list's share. It returns gorm.ErrRecordNotFound if
+// the list is not shared.
+func (r *listRepository) DeleteShare(listID uint) error {
+	result := r.db.Where("list_id = ?", listID).Delete(&entities.ListShare{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MigrateDefaultLists moves wishlists created before lists existed into a
+// default list of their owner. It runs once at startup.
+func MigrateDefaultLists(db *gorm.DB) error {
+	var userIDs []int
+	err := db.Unscoped().Model(&entities.Wishlist{}).
+		Where("list_id IS NULL AND user_id IS NOT NULL").
+		Distinct().
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			list, err := NewListRepository(tx).FindOrCreateDefault(userID)
+			if err != nil {
+				return err
+			}
+			return tx.Unscoped().Model(&entities.Wishlist{}).
+				Where("user_id = ? AND list_id IS NULL", userID).
+				Update("list_id", list.ID).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/entities"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestListRepository(t *testing.T) {
+	testCases := []struct {
+		name      string
+		setup     func(mock sqlmock.Sqlmock)
+		run       func(repo ListRepository) ([]*entities.List, error)
+		assertion func(t *testing.T, err error, lists []*entities.List)
+	}{
+		{
+			name: "GetAll - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.
+					NewRows([]string{"id", "user_id", "name", "is_default"}).
+					AddRow(1, 1, DefaultListName, true).
+					AddRow(2, 1, "Birthday", false)
+				mock.ExpectQuery("SELECT * FROM `lists` WHERE user_id = ? AND `lists`.`deleted_at` IS NULL ORDER BY id").
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+			run: func(repo ListRepository) ([]*entities.List, error) {
+				return repo.GetAll(1)
+			},
+			assertion: func(t *testing.T, err error, lists []*entities.List) {
+				assert.NoError(t, err)
+				assert.Len(t, lists, 2)
+				assert.True(t, lists[0].IsDefault)
+			},
+		},
+		{
+			name: "FindOrCreateDefault - existing",
+			setup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.
+					NewRows([]string{"id", "user_id", "name", "is_default"}).
+					AddRow(1, 1, DefaultListName, true)
+				mock.ExpectQuery("SELECT * FROM `lists` WHERE (user_id = ? AND is_default = ?) AND `lists`.`deleted_at` IS NULL ORDER BY `lists`.`id` LIMIT ?").
+					WithArgs(1, true, 1).
+					WillReturnRows(rows)
+			},
+			run: func(repo ListRepository) ([]*entities.List, error) {
+				list, err := repo.FindOrCreateDefault(1)
+				return []*entities.List{list}, err
+			},
+			assertion: func(t *testing.T, err error, lists []*entities.List) {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), lists[0].ID)
+			},
+		},
+		{
+			name: "FindOrCreateDefault - created",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT * FROM `lists` WHERE (user_id = ? AND is_default = ?) AND `lists`.`deleted_at` IS NULL ORDER BY `lists`.`id` LIMIT ?").
+					WithArgs(1, true, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `lists` (`user_id`,`name`,`is_default`,`created_at`,`updated_at`,`deleted_at`) VALUES (?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `id`=`id`").
+					WithArgs(1, DefaultListName, true, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+					WillReturnResult(sqlmock.NewResult(3, 1))
+				mock.ExpectCommit()
+				mock.ExpectQuery("SELECT * FROM `lists` WHERE (user_id = ? AND is_default = ?) AND `lists`.`deleted_at` IS NULL ORDER BY `lists`.`id` LIMIT ?").
+					WithArgs(1, true, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "is_default"}).AddRow(3, 1, DefaultListName, true))
+			},
+			run: func(repo ListRepository) ([]*entities.List, error) {
+				list, err := repo.FindOrCreateDefault(1)
+				return []*entities.List{list}, err
+			},
+			assertion: func(t *testing.T, err error, lists []*entities.List) {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(3), lists[0].ID)
+				assert.True(t, lists[0].IsDefault)
+			},
+		},
+		{
+			name: "FindOrCreateDefault - created concurrently",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT * FROM `lists` WHERE (user_id = ? AND is_default = ?) AND `lists`.`deleted_at` IS NULL ORDER BY `lists`.`id` LIMIT ?").
+					WithArgs(1, true, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `lists` (`user_id`,`name`,`is_default`,`created_at`,`updated_at`,`deleted_at`) VALUES (?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `id`=`id`").
+					WithArgs(1, DefaultListName, true, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				mock.ExpectQuery("SELECT * FROM `lists` WHERE (user_id = ? AND is_default = ?) AND `lists`.`deleted_at` IS NULL ORDER BY `lists`.`id` LIMIT ?").
+					WithArgs(1, true, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "is_default"}).AddRow(2, 1, DefaultListName, true))
+			},
+			run: func(repo ListRepository) ([]*entities.List, error) {
+				list, err := repo.FindOrCreateDefault(1)
+				return []*entities.List{list}, err
+			},
+			assertion: func(t *testing.T, err error, lists []*entities.List) {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(2), lists[0].ID)
+			},
+		},
+		{
+			name: "FindOrCreateDefault - error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT * FROM `lists` WHERE (user_id = ? AND is_default = ?) AND `lists`.`deleted_at` IS NULL ORDER BY `lists`.`id` LIMIT ?").
+					WithArgs(1, true, 1).
+					WillReturnError(fmt.Errorf("Failed to find default list"))
+			},
+			run: func(repo ListRepository) ([]*entities.List, error) {
+				_, err := repo.FindOrCreateDefault(1)
+				return nil, err
+			},
+			assertion: func(t *testing.T, err error, lists []*entities.List) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "DeleteList - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `wishlists` SET `deleted_at`=? WHERE list_id = ? AND `wishlists`.`deleted_at` IS NULL").
+					WithArgs(sqlmock.AnyArg(), 2).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("UPDATE `lists` SET `deleted_at`=? WHERE `lists`.`id` = ? AND `lists`.`deleted_at` IS NULL").
+					WithArgs(sqlmock.AnyArg(), 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(repo ListRepository) ([]*entities.List, error) {
+				return nil, repo.DeleteList(2)
+			},
+			assertion: func(t *testing.T, err error, lists []*entities.List) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "DeleteList - error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `wishlists` SET `deleted_at`=? WHERE list_id = ? AND `wishlists`.`deleted_at` IS NULL").
+					WithArgs(sqlmock.AnyArg(), 2).
+					WillReturnError(fmt.Errorf("Failed to delete items"))
+				mock.ExpectRollback()
+			},
+			run: func(repo ListRepository) ([]*entities.List, error) {
+				return nil, repo.DeleteList(2)
+			},
+			assertion: func(t *testing.T, err error, lists []*entities.List) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "SaveShare - replaces previous share",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `list_shares` WHERE list_id = ?").
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `list_shares` (`list_id`,`token_hash`,`password_hash`,`expires_at`,`reveal_claims`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?)").
+					WithArgs(2, "hash", "", nil, false, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(4, 1))
+				mock.ExpectCommit()
+			},
+			run: func(repo ListRepository) ([]*entities.List, error) {
+				share, err := repo.SaveShare(&entities.ListShare{ListID: 2, TokenHash: "hash"})
+				if err == nil && share.ID != 4 {
+					return nil, fmt.Errorf("unexpected share id %d", share.ID)
+				}
+				return nil, err
+			},
+			assertion: func(t *testing.T, err error, lists []*entities.List) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "FindShareByTokenHash - loads list and owner",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT * FROM `list_shares` WHERE token_hash = ? ORDER BY `list_shares`.`id` LIMIT ?").
+					WithArgs("hash", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "list_id", "token_hash"}).AddRow(4, 2, "hash"))
+				mock.ExpectQuery("SELECT * FROM `lists` WHERE `lists`.`id` = ? AND `lists`.`deleted_at` IS NULL").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name"}).AddRow(2, 1, "Birthday"))
+				mock.ExpectQuery("SELECT * FROM `users` WHERE `users`.`id` = ?").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "display_name"}).AddRow(1, "Ada"))
+			},
+			run: func(repo ListRepository) ([]*entities.List, error) {
+				share, err := repo.FindShareByTokenHash("hash")
+				if err != nil {
+					return nil, err
+				}
+				return []*entities.List{share.List}, nil
+			},
+			assertion: func(t *testing.T, err error, lists []*entities.List) {
+				assert.NoError(t, err)
+				assert.Equal(t, "Ada", lists[0].User.DisplayName)
+			},
+		},
+		{
+			name: "UpdateShareToken - list not shared",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `list_shares` SET `token_hash`=?,`updated_at`=? WHERE list_id = ?").
+					WithArgs("hash", sqlmock.AnyArg(), 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			run: func(repo ListRepository) ([]*entities.List, error) {
+				return nil, repo.UpdateShareToken(2, "hash")
+			},
+			assertion: func(t *testing.T, err error, lists []*entities.List) {
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "DeleteShare - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `list_shares` WHERE list_id = ?").
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(repo ListRepository) ([]*entities.List, error) {
+				return nil, repo.DeleteShare(2)
+			},
+			assertion: func(t *testing.T, err error, lists []*entities.List) {
+				assert.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewListRepository(CreateGormDB(db))
+			tc.setup(mock)
+
+			lists, err := tc.run(repo)
+			tc.assertion(t, err, lists)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"go-wishlist-api-2/entities"
+	"gorm.io/gorm"
+	"time"
+)
+
+type PersonalAccessTokenRepository interface {
+	CreateToken(token *entities.PersonalAccessToken) (*entities.PersonalAccessToken, error)
+	FindByHash(hash string) (*entities.PersonalAccessToken, error)
+	FindByUser(userID int) ([]*entities.PersonalAccessToken, error)
+	CountByUser(userID int) (int64, error)
+	DeleteToken(userID int, id uint) error
+	TouchToken(id uint, usedAt time.Time) error
+}
+
+type personalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) *personalAccessTokenRepository {
+	return &personalAccessTokenRepository{db}
+}
+
+func (r *personalAccessTokenRepository) CreateToken(token *entities.PersonalAccessToken) (*entities.PersonalAccessToken, error) {
+	if err := r.db.Create(&token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// FindByHash returns the token with the given hash together with its owner.
+func (r *personalAccessTokenRepository) FindByHash(hash string) (*entities.PersonalAccessToken, error) {
+	var token *entities.PersonalAccessToken
+	if err := r.db.Preload("User").Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *personalAccessTokenRepository) FindByUser(userID int) ([]*entities.PersonalAccessToken, error) {
+	var tokens []*entities.PersonalAccessToken
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *personalAccessTokenRepository) CountByUser(userID int) (int64, error) {
+	var count int64
+	if err := r.db.Model(&entities.PersonalAccessToken{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// DeleteToken deletes a token of the user. It returns gorm.ErrRecordNotFound
+// if the user has no token with that id.
+func (r *personalAccessTokenRepository) DeleteToken(userID int, id uint) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&entities.PersonalAccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *personalAccessTokenRepository) TouchToken(id uint, usedAt time.Time) error {
+	return r.db.Model(&entities.PersonalAccessToken{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", usedAt).Error
+}
+
+// deletePersonalAccessTokens deletes every personal access token of the user
+// within tx.
+func deletePersonalAccessTokens(tx *gorm.DB, userID int) error {
+	return tx.Where("user_id = ?", userID).Delete(&entities.PersonalAccessToken{}).Error
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestPersonalAccessTokenRepository(t *testing.T) {
+	testCases := []struct {
+		name      string
+		setup     func(mock sqlmock.Sqlmock)
+		run       func(t *testing.T, repo PersonalAccessTokenRepository) error
+		assertion func(t *testing.T, err error)
+	}{
+		{
+			name: "FindByHash - loads owner",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT * FROM `personal_access_tokens` WHERE token_hash = ? ORDER BY `personal_access_tokens`.`id` LIMIT ?").
+					WithArgs("hash", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "token_hash", "scopes"}).
+						AddRow(1, 7, "ci", "hash", "wishlists:read"))
+				mock.ExpectQuery("SELECT * FROM `users` WHERE `users`.`id` = ?").
+					WithArgs(7).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(7, "admin@example.com"))
+			},
+			run: func(t *testing.T, repo PersonalAccessTokenRepository) error {
+				token, err := repo.FindByHash("hash")
+				if err == nil {
+					assert.Equal(t, "admin@example.com", token.User.Email)
+				}
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "DeleteToken - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `personal_access_tokens` WHERE id = ? AND user_id = ?").
+					WithArgs(3, 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo PersonalAccessTokenRepository) error {
+				return repo.DeleteToken(7, 3)
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "DeleteToken - token of another user",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `personal_access_tokens` WHERE id = ? AND user_id = ?").
+					WithArgs(3, 8).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo PersonalAccessTokenRepository) error {
+				return repo.DeleteToken(8, 3)
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "TouchToken - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `personal_access_tokens` SET `last_used_at`=? WHERE id = ?").
+					WithArgs(sqlmock.AnyArg(), 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo PersonalAccessTokenRepository) error {
+				return repo.TouchToken(3, time.Now())
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewPersonalAccessTokenRepository(CreateGormDB(db))
+			tc.setup(mock)
+
+			tc.assertion(t, tc.run(t, repo))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
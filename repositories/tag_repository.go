@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"errors"
+	"go-wishlist-api-2/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository interface {
+	Search(userID int, prefix string, limit int) ([]*entities.Tag, error)
+	FindByID(id uint) (*entities.Tag, error)
+	FindByName(userID int, name string) (*entities.Tag, error)
+	FindOrCreate(userID int, names []string) ([]entities.Tag, error)
+	UpdateTag(tag *entities.Tag) (*entities.Tag, error)
+	MergeTags(sourceID, targetID uint) error
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *tagRepository {
+	return &tagRepository{db}
+}
+
+func (r *tagRepository) Search(userID int, prefix string, limit int) ([]*entities.Tag, error) {
+	var tags []*entities.Tag
+	err := r.db.Where("user_id = ? AND name LIKE ?", userID, escapeLike(prefix)+"%").
+		Order("name").
+		Limit(limit).
+		Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) FindByID(id uint) (*entities.Tag, error) {
+	var tag *entities.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (r *tagRepository) FindByName(userID int, name string) (*entities.Tag, error) {
+	var tag *entities.Tag
+	if err := r.db.Where("user_id = ? AND name = ?", userID, name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// FindOrCreate returns the user's tags with the given names and creates the
+// missing ones. A tag that a concurrent request created in the meantime is
+// read back instead of failing on the unique index.
+func (r *tagRepository) FindOrCreate(userID int, names []string) ([]entities.Tag, error) {
+	tags := make([]entities.Tag, 0, len(names))
+	for _, name := range names {
+		var tag entities.Tag
+		where := entities.Tag{UserID: userID, Name: name}
+		err := r.db.Where(where).First(&tag).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entities.Tag{UserID: userID, Name: name}).Error
+			if err == nil {
+				err = r.db.Where(where).First(&tag).Error
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) UpdateTag(tag *entities.Tag) (*entities.Tag, error) {
+	if err := r.db.Save(&tag).Error; err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// MergeTags moves every association of the source tag to the target tag and
+// deletes the source tag.
+func (r *tagRepository) MergeTags(sourceID, targetID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("INSERT IGNORE INTO wishlist_tags (wishlist_id, tag_id) SELECT wishlist_id, ? FROM wishlist_tags WHERE tag_id = ?", targetID, sourceID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM wishlist_tags WHERE tag_id = ?", sourceID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.Tag{}, sourceID).Error
+	})
+}
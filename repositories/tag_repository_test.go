@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/entities"
+	"testing"
+)
+
+func TestTagRepository(t *testing.T) {
+	testCases := []struct {
+		name      string
+		setup     func(mock sqlmock.Sqlmock)
+		run       func(repo TagRepository) ([]*entities.Tag, error)
+		assertion func(t *testing.T, err error, tags []*entities.Tag)
+	}{
+		{
+			name: "Search - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.
+					NewRows([]string{"id", "user_id", "name"}).
+					AddRow(1, 1, "tech").
+					AddRow(2, 1, "technic")
+				mock.ExpectQuery("SELECT * FROM `tags` WHERE user_id = ? AND name LIKE ? ORDER BY name LIMIT ?").
+					WithArgs(1, "tec%", 10).
+					WillReturnRows(rows)
+			},
+			run: func(repo TagRepository) ([]*entities.Tag, error) {
+				return repo.Search(1, "tec", 10)
+			},
+			assertion: func(t *testing.T, err error, tags []*entities.Tag) {
+				assert.NoError(t, err)
+				assert.Len(t, tags, 2)
+			},
+		},
+		{
+			name: "FindOrCreate - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT * FROM `tags` WHERE `tags`.`user_id` = ? AND `tags`.`name` = ? ORDER BY `tags`.`id` LIMIT ?").
+					WithArgs(1, "tech", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name"}).AddRow(1, 1, "tech"))
+				mock.ExpectQuery("SELECT * FROM `tags` WHERE `tags`.`user_id` = ? AND `tags`.`name` = ? ORDER BY `tags`.`id` LIMIT ?").
+					WithArgs(1, "books", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `tags` (`user_id`,`name`,`created_at`,`updated_at`) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE `id`=`id`").
+					WithArgs(1, "books", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+				mock.ExpectQuery("SELECT * FROM `tags` WHERE `tags`.`user_id` = ? AND `tags`.`name` = ? ORDER BY `tags`.`id` LIMIT ?").
+					WithArgs(1, "books", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name"}).AddRow(2, 1, "books"))
+			},
+			run: func(repo TagRepository) ([]*entities.Tag, error) {
+				tags, err := repo.FindOrCreate(1, []string{"tech", "books"})
+				var result []*entities.Tag
+				for i := range tags {
+					result = append(result, &tags[i])
+				}
+				return result, err
+			},
+			assertion: func(t *testing.T, err error, tags []*entities.Tag) {
+				assert.NoError(t, err)
+				assert.Len(t, tags, 2)
+				assert.Equal(t, uint(1), tags[0].ID)
+				assert.Equal(t, uint(2), tags[1].ID)
+				assert.Equal(t, "books", tags[1].Name)
+			},
+		},
+		{
+			name: "FindOrCreate - created concurrently",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT * FROM `tags` WHERE `tags`.`user_id` = ? AND `tags`.`name` = ? ORDER BY `tags`.`id` LIMIT ?").
+					WithArgs(1, "books", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `tags` (`user_id`,`name`,`created_at`,`updated_at`) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE `id`=`id`").
+					WithArgs(1, "books", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				mock.ExpectQuery("SELECT * FROM `tags` WHERE `tags`.`user_id` = ? AND `tags`.`name` = ? ORDER BY `tags`.`id` LIMIT ?").
+					WithArgs(1, "books", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name"}).AddRow(7, 1, "books"))
+			},
+			run: func(repo TagRepository) ([]*entities.Tag, error) {
+				tags, err := repo.FindOrCreate(1, []string{"books"})
+				var result []*entities.Tag
+				for i := range tags {
+					result = append(result, &tags[i])
+				}
+				return result, err
+			},
+			assertion: func(t *testing.T, err error, tags []*entities.Tag) {
+				assert.NoError(t, err)
+				assert.Len(t, tags, 1)
+				assert.Equal(t, uint(7), tags[0].ID)
+			},
+		},
+		{
+			name: "MergeTags - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT IGNORE INTO wishlist_tags (wishlist_id, tag_id) SELECT wishlist_id, ? FROM wishlist_tags WHERE tag_id = ?").
+					WithArgs(2, 1).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("DELETE FROM wishlist_tags WHERE tag_id = ?").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("DELETE FROM `tags` WHERE `tags`.`id` = ?").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(repo TagRepository) ([]*entities.Tag, error) {
+				return nil, repo.MergeTags(1, 2)
+			},
+			assertion: func(t *testing.T, err error, tags []*entities.Tag) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "MergeTags - error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT IGNORE INTO wishlist_tags (wishlist_id, tag_id) SELECT wishlist_id, ? FROM wishlist_tags WHERE tag_id = ?").
+					WithArgs(2, 1).
+					WillReturnError(fmt.Errorf("Failed to merge tags"))
+				mock.ExpectRollback()
+			},
+			run: func(repo TagRepository) ([]*entities.Tag, error) {
+				return nil, repo.MergeTags(1, 2)
+			},
+			assertion: func(t *testing.T, err error, tags []*entities.Tag) {
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewTagRepository(CreateGormDB(db))
+			tc.setup(mock)
+
+			tags, err := tc.run(repo)
+			tc.assertion(t, err, tags)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package repositories
+
+import (
+	"errors"
+	"go-wishlist-api-2/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// ErrRefreshTokenRevoked is returned when rotating a refresh token that was
+// revoked in the meantime, e.g. by a concurrent refresh with the same token.
+var ErrRefreshTokenRevoked = errors.New("refresh token already revoked")
+
+type TokenRepository interface {
+	CreateRefreshToken(token *entities.RefreshToken) (*entities.RefreshToken, error)
+	FindRefreshTokenByHash(hash string) (*entities.RefreshToken, error)
+	RotateRefreshToken(old *entities.RefreshToken, next *entities.RefreshToken) (*entities.RefreshToken, error)
+	RevokeRefreshToken(token *entities.RefreshToken) error
+	RevokeAllForUser(userID int) error
+	DenyAccessToken(jti string, expiresAt time.Time) error
+	IsAccessTokenDenied(jti string) (bool, error)
+	PurgeExpiredTokens(before time.Time) (int64, error)
+	CreateSession(session *entities.Session, issue func(session *entities.Session) (*entities.RefreshToken, error)) error
+	FindActiveSessions(userID int) ([]*entities.Session, error)
+	RevokeSession(userID int, id uint) error
+	IsSessionRevoked(id uint) (bool, error)
+}
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *tokenRepository {
+	return &tokenRepository{db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *entities.RefreshToken) (*entities.RefreshToken, error) {
+	if err := r.db.Create(&token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *tokenRepository) FindRefreshTokenByHash(hash string) (*entities.RefreshToken, error) {
+	var token *entities.RefreshToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// RotateRefreshToken revokes old and stores next in one transaction. Only one
+// of several concurrent rotations of the same token can succeed; the others
+// get ErrRefreshTokenRevoked.
+func (r *tokenRepository) RotateRefreshToken(old *entities.RefreshToken, next *entities.RefreshToken) (*entities.RefreshToken, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", old.ID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenRevoked
+		}
+		if err := tx.Create(&next).Error; err != nil {
+			return err
+		}
+		if next.SessionID == 0 {
+			return nil
+		}
+		return tx.Model(&entities.Session{}).
+			Where("id = ?", next.SessionID).
+			Updates(map[string]any{"last_seen_at": time.Now(), "expires_at": next.ExpiresAt}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// RevokeRefreshToken revokes token and its session and denylists the access
+// token issued with it.
+func (r *tokenRepository) RevokeRefreshToken(token *entities.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := denyAccessTokens(tx, []*entities.RefreshToken{token}); err != nil {
+			return err
+		}
+		err := tx.Model(&entities.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", token.ID).
+			Update("revoked_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return revokeSessions(tx, []*entities.RefreshToken{token})
+	})
+}
+
+// RevokeAllForUser revokes every active refresh token of the user together
+// with the access tokens issued alongside them.
+func (r *tokenRepository) RevokeAllForUser(userID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return revokeUserTokens(tx, userID, "")
+	})
+}
+
+func (r *tokenRepository) DenyAccessToken(jti string, expiresAt time.Time) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entities.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+func (r *tokenRepository) IsAccessTokenDenied(jti string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&entities.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// PurgeExpiredTokens deletes denylist entries, refresh tokens, password reset
+// tokens, unfinished OpenID Connect logins and sessions that expired before
+// the given time; none can be used anymore.
+func (r *tokenRepository) PurgeExpiredTokens(before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		expirable := []any{
+			&entities.RevokedToken{},
+			&entities.RefreshToken{},
+			&entities.PasswordResetToken{},
+			&entities.OIDCLoginState{},
+			&entities.Session{},
+		}
+		for _, model := range expirable {
+			result := tx.Where("expires_at < ?", before).Delete(model)
+			if result.Error != nil {
+				return result.Error
+			}
+			purged += result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// CreateSession stores session and the first refresh token of it in one
+// transaction, so that a failure cannot leave a session behind that no token
+// belongs to. issue is called with the stored session, once it has its id, to
+// create the refresh token.
+func (r *tokenRepository) CreateSession(session *entities.Session, issue func(session *entities.Session) (*entities.RefreshToken, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		token, err := issue(session)
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// FindActiveSessions returns the sessions of the user that are neither
+// revoked nor expired, most recently used first.
+func (r *tokenRepository) FindActiveSessions(userID int) ([]*entities.Session, error) {
+	var sessions []*entities.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of the user together with its refresh and
+// access tokens. It returns gorm.ErrRecordNotFound if the user has no active
+// session with that id.
+func (r *tokenRepository) RevokeSession(userID int, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.Session{}).
+			Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		active := func(db *gorm.DB) *gorm.DB {
+			return db.Where("session_id = ? AND revoked_at IS NULL", id)
+		}
+		var tokens []*entities.RefreshToken
+		if err := tx.Scopes(active).Find(&tokens).Error; err != nil {
+			return err
+		}
+		if err := denyAccessTokens(tx, tokens); err != nil {
+			return err
+		}
+		return tx.Model(&entities.RefreshToken{}).
+			Scopes(active).
+			Update("revoked_at", time.Now()).Error
+	})
+}
+
+// IsSessionRevoked reports whether the session was revoked. Sessions that no
+// longer exist count as revoked.
+func (r *tokenRepository) IsSessionRevoked(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&entities.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+// revokeUserTokens revokes every active refresh token of the user together
+// with the access tokens issued alongside them and their sessions. If
+// keepAccessJTI is set, the session of that access token stays signed in.
+func revokeUserTokens(tx *gorm.DB, userID int, keepAccessJTI string) error {
+	active := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("user_id = ? AND revoked_at IS NULL", userID)
+		if keepAccessJTI != "" {
+			db = db.Where("access_jti <> ?", keepAccessJTI)
+		}
+		return db
+	}
+	var tokens []*entities.RefreshToken
+	if err := tx.Scopes(active).Find(&tokens).Error; err != nil {
+		return err
+	}
+	if err := denyAccessTokens(tx, tokens); err != nil {
+		return err
+	}
+	err := tx.Model(&entities.RefreshToken{}).
+		Scopes(active).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+	return revokeSessions(tx, tokens)
+}
+
+// revokeSessions revokes the sessions the given refresh tokens belong to.
+func revokeSessions(tx *gorm.DB, tokens []*entities.RefreshToken) error {
+	var ids []uint
+	for _, token := range tokens {
+		if token.SessionID != 0 {
+			ids = append(ids, token.SessionID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Model(&entities.Session{}).
+		Where("id IN ? AND revoked_at IS NULL", ids).
+		Update("revoked_at", time.Now()).Error
+}
+
+// denyAccessTokens denylists the still valid access tokens issued together
+// with the given refresh tokens.
+func denyAccessTokens(tx *gorm.DB, tokens []*entities.RefreshToken) error {
+	var revoked []*entities.RevokedToken
+	now := time.Now()
+	for _, token := range tokens {
+		if token.AccessJTI != "" && token.AccessExpiresAt.After(now) {
+			revoked = append(revoked, &entities.RevokedToken{JTI: token.AccessJTI, ExpiresAt: token.AccessExpiresAt})
+		}
+	}
+	if len(revoked) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&revoked).Error
+}
@@ -0,0 +1,251 @@
+package repositories
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/entities"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestTokenRepository(t *testing.T) {
+	testCases := []struct {
+		name      string
+		setup     func(mock sqlmock.Sqlmock)
+		run       func(t *testing.T, repo TokenRepository) error
+		assertion func(t *testing.T, err error)
+	}{
+		{
+			name: "RotateRefreshToken - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `refresh_tokens` SET `revoked_at`=? WHERE id = ? AND revoked_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `refresh_tokens` (`user_id`,`session_id`,`token_hash`,`access_jti`,`access_expires_at`,`expires_at`,`revoked_at`,`created_at`) VALUES (?,?,?,?,?,?,?,?)").
+					WithArgs(1, 4, "hash", "jti", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("UPDATE `sessions` SET `expires_at`=?,`last_seen_at`=? WHERE id = ?").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 4).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo TokenRepository) error {
+				_, err := repo.RotateRefreshToken(
+					&entities.RefreshToken{ID: 1, UserID: 1, SessionID: 4},
+					&entities.RefreshToken{UserID: 1, SessionID: 4, TokenHash: "hash", AccessJTI: "jti"},
+				)
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "RotateRefreshToken - already revoked",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `refresh_tokens` SET `revoked_at`=? WHERE id = ? AND revoked_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			run: func(t *testing.T, repo TokenRepository) error {
+				_, err := repo.RotateRefreshToken(&entities.RefreshToken{ID: 1, UserID: 1}, &entities.RefreshToken{UserID: 1})
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrRefreshTokenRevoked)
+			},
+		},
+		{
+			name: "CreateSession - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `sessions` (`user_id`,`user_agent`,`ip`,`last_seen_at`,`expires_at`,`revoked_at`,`created_at`) VALUES (?,?,?,?,?,?,?)").
+					WithArgs(1, "curl", "127.0.0.1", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(4, 1))
+				mock.ExpectExec("INSERT INTO `refresh_tokens` (`user_id`,`session_id`,`token_hash`,`access_jti`,`access_expires_at`,`expires_at`,`revoked_at`,`created_at`) VALUES (?,?,?,?,?,?,?,?)").
+					WithArgs(1, 4, "hash", "jti", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo TokenRepository) error {
+				session := &entities.Session{UserID: 1, UserAgent: "curl", IP: "127.0.0.1"}
+				return repo.CreateSession(session, func(session *entities.Session) (*entities.RefreshToken, error) {
+					return &entities.RefreshToken{UserID: 1, SessionID: session.ID, TokenHash: "hash", AccessJTI: "jti"}, nil
+				})
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "CreateSession - issuing fails",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `sessions` (`user_id`,`user_agent`,`ip`,`last_seen_at`,`expires_at`,`revoked_at`,`created_at`) VALUES (?,?,?,?,?,?,?)").
+					WithArgs(1, "", "", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(4, 1))
+				mock.ExpectRollback()
+			},
+			run: func(t *testing.T, repo TokenRepository) error {
+				return repo.CreateSession(&entities.Session{UserID: 1}, func(session *entities.Session) (*entities.RefreshToken, error) {
+					return nil, errors.New("no signing key")
+				})
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "no signing key")
+			},
+		},
+		{
+			name: "RevokeAllForUser - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.
+					NewRows([]string{"id", "user_id", "session_id", "access_jti", "access_expires_at"}).
+					AddRow(1, 1, 4, "active", time.Now().Add(time.Minute)).
+					AddRow(2, 1, 0, "expired", time.Now().Add(-time.Minute))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT * FROM `refresh_tokens` WHERE user_id = ? AND revoked_at IS NULL").
+					WithArgs(1).
+					WillReturnRows(rows)
+				mock.ExpectExec("INSERT INTO `revoked_tokens` (`jti`,`expires_at`,`created_at`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `jti`=`jti`").
+					WithArgs("active", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `refresh_tokens` SET `revoked_at`=? WHERE user_id = ? AND revoked_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("UPDATE `sessions` SET `revoked_at`=? WHERE id IN (?) AND revoked_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 4).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo TokenRepository) error {
+				return repo.RevokeAllForUser(1)
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "IsAccessTokenDenied - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count(*) FROM `revoked_tokens` WHERE jti = ?").
+					WithArgs("jti").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			},
+			run: func(t *testing.T, repo TokenRepository) error {
+				denied, err := repo.IsAccessTokenDenied("jti")
+				assert.True(t, denied)
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "RevokeSession - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `sessions` SET `revoked_at`=? WHERE id = ? AND user_id = ? AND revoked_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 4, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT * FROM `refresh_tokens` WHERE session_id = ? AND revoked_at IS NULL").
+					WithArgs(4).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "session_id", "access_jti", "access_expires_at"}).
+						AddRow(1, 1, 4, "active", time.Now().Add(time.Minute)))
+				mock.ExpectExec("INSERT INTO `revoked_tokens` (`jti`,`expires_at`,`created_at`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `jti`=`jti`").
+					WithArgs("active", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `refresh_tokens` SET `revoked_at`=? WHERE session_id = ? AND revoked_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 4).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo TokenRepository) error {
+				return repo.RevokeSession(1, 4)
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "RevokeSession - session of another user",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `sessions` SET `revoked_at`=? WHERE id = ? AND user_id = ? AND revoked_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 4, 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			run: func(t *testing.T, repo TokenRepository) error {
+				return repo.RevokeSession(2, 4)
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "IsSessionRevoked - revoked",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count(*) FROM `sessions` WHERE id = ? AND revoked_at IS NULL").
+					WithArgs(4).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			},
+			run: func(t *testing.T, repo TokenRepository) error {
+				revoked, err := repo.IsSessionRevoked(4)
+				assert.True(t, revoked)
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "PurgeExpiredTokens - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `revoked_tokens` WHERE expires_at < ?").
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM `refresh_tokens` WHERE expires_at < ?").
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("DELETE FROM `password_reset_tokens` WHERE expires_at < ?").
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `oidc_login_states` WHERE expires_at < ?").
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM `sessions` WHERE expires_at < ?").
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo TokenRepository) error {
+				purged, err := repo.PurgeExpiredTokens(time.Now())
+				assert.Equal(t, int64(9), purged)
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewTokenRepository(CreateGormDB(db))
+			tc.setup(mock)
+
+			tc.assertion(t, tc.run(t, repo))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
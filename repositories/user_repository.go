@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"errors"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ErrDeletionScheduled is returned when scheduling the deletion of an
+// account that is already waiting to be erased.
+var ErrDeletionScheduled = errors.New("account deletion already scheduled")
+
+type UserRepository interface {
+	FindUsers(filter *dto.UserFilter) ([]*entities.User, int64, error)
+	FindByID(id int) (*entities.User, error)
+	SetDisabled(id int, disabledAt *time.Time) error
+	UpdateRole(id int, role string) error
+	UpdateProfile(user *entities.User) error
+	FindAccountData(userID int) (*entities.AccountData, error)
+	ScheduleDeletion(deletion *entities.AccountDeletion) error
+	FindDueDeletions(now time.Time) ([]*entities.AccountDeletion, error)
+	EraseAccount(deletion *entities.AccountDeletion) error
+	FindDeletions(filter *dto.DeletionFilter) ([]*entities.AccountDeletion, int64, error)
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) *userRepository {
+	return &userRepository{db}
+}
+
+// FindUsers returns one page of the users matching filter along with the
+// total number of matches, ignoring pagination.
+func (r *userRepository) FindUsers(filter *dto.UserFilter) ([]*entities.User, int64, error) {
+	query := r.db.Model(&entities.User{})
+	if filter.Email != "" {
+		query = query.Where("email LIKE ?", "%"+escapeLike(filter.Email)+"%")
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*entities.User
+	err := query.Order("id").Limit(filter.Limit).Offset(filter.Offset).Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *userRepository) FindByID(id int) (*entities.User, error) {
+	var user *entities.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetDisabled disables the user, or enables it again when disabledAt is nil.
+// It returns gorm.ErrRecordNotFound if there is no user with that id.
+func (r *userRepository) SetDisabled(id int, disabledAt *time.Time) error {
+	return r.updateColumn(id, "disabled_at", disabledAt)
+}
+
+// UpdateRole returns gorm.ErrRecordNotFound if there is no user with that id.
+func (r *userRepository) UpdateRole(id int, role string) error {
+	return r.updateColumn(id, "role", role)
+}
+
+// UpdateProfile stores the profile fields of user, including cleared ones.
+func (r *userRepository) UpdateProfile(user *entities.User) error {
+	return r.db.Model(user).
+		Select("DisplayName", "AvatarURL", "Birthday", "Locale", "Timezone", "DefaultCurrency").
+		Updates(user).Error
+}
+
+// FindAccountData gathers everything stored about the user for a data
+// export, including lists and wishlists in the trash and revoked sessions.
+func (r *userRepository) FindAccountData(userID int) (*entities.AccountData, error) {
+	user, err := r.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	data := &entities.AccountData{User: user}
+	owned := func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped().Where("user_id = ?", userID).Order("id")
+	}
+	finds := []func() error{
+		func() error { return r.db.Scopes(owned).Find(&data.Lists).Error },
+		func() error { return r.db.Scopes(owned).Preload("Tags").Find(&data.Wishlists).Error },
+		func() error { return r.db.Scopes(owned).Find(&data.Tags).Error },
+		func() error { return r.db.Scopes(owned).Find(&data.AchievementEvents).Error },
+		func() error { return r.db.Scopes(owned).Find(&data.Sessions).Error },
+		func() error { return r.db.Scopes(owned).Find(&data.PersonalAccessTokens).Error },
+		func() error { return r.db.Scopes(owned).Find(&data.ExternalIdentities).Error },
+	}
+	for _, find := range finds {
+		if err := find(); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// ScheduleDeletion marks the user for erasure at deletion.ScheduledFor,
+// stores the audit record and signs the user out everywhere. It returns
+// ErrDeletionScheduled if the account is already scheduled for deletion or
+// no longer exists.
+func (r *userRepository) ScheduleDeletion(deletion *entities.AccountDeletion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.User{}).
+			Where("id = ? AND deletion_scheduled_at IS NULL", deletion.UserID).
+			UpdateColumn("deletion_scheduled_at", deletion.ScheduledFor)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDeletionScheduled
+		}
+		if err := tx.Create(deletion).Error; err != nil {
+			return err
+		}
+		return revokeUserTokens(tx, deletion.UserID, "")
+	})
+}
+
+// FindDueDeletions returns the pending deletions whose grace period ended
+// before now.
+func (r *userRepository) FindDueDeletions(now time.Time) ([]*entities.AccountDeletion, error) {
+	var deletions []*entities.AccountDeletion
+	err := r.db.Where("cancelled_at IS NULL AND erased_at IS NULL AND scheduled_for <= ?", now).
+		Order("scheduled_for").
+		Find(&deletions).Error
+	if err != nil {
+		return nil, err
+	}
+	return deletions, nil
+}
+
+// EraseAccount deletes the user of a due deletion; the database cascades
+// the delete to the user's lists, wishlists, tags, sessions and tokens. The
+// audit record is kept without the email address. It returns
+// gorm.ErrRecordNotFound if the deletion was cancelled in the meantime.
+func (r *userRepository) EraseAccount(deletion *entities.AccountDeletion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Where("id = ? AND deletion_scheduled_at <= ?", deletion.UserID, now).
+			Delete(&entities.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		deletion.Email = ""
+		deletion.ErasedAt = &now
+		return tx.Model(deletion).Select("Email", "ErasedAt").Updates(deletion).Error
+	})
+}
+
+// FindDeletions returns one page of the deletion audit records matching
+// filter, newest first, along with the total number of matches.
+func (r *userRepository) FindDeletions(filter *dto.DeletionFilter) ([]*entities.AccountDeletion, int64, error) {
+	query := r.db.Model(&entities.AccountDeletion{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deletions []*entities.AccountDeletion
+	err := query.Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&deletions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return deletions, total, nil
+}
+
+func (r *userRepository) updateColumn(id int, column string, value any) error {
+	result := r.db.Model(&entities.User{}).Where("id = ?", id).UpdateColumn(column, value)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
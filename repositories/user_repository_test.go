@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestUserRepository(t *testing.T) {
+	testCases := []struct {
+		name      string
+		setup     func(mock sqlmock.Sqlmock)
+		run       func(t *testing.T, repo UserRepository) error
+		assertion func(t *testing.T, err error)
+	}{
+		{
+			name: "FindUsers - filters and paginates",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count(*) FROM `users` WHERE email LIKE ? AND role = ?").
+					WithArgs("%example\\_%", "admin").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery("SELECT * FROM `users` WHERE email LIKE ? AND role = ? ORDER BY id LIMIT ? OFFSET ?").
+					WithArgs("%example\\_%", "admin", 2, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role"}).AddRow(9, "root@example_.com", "admin"))
+			},
+			run: func(t *testing.T, repo UserRepository) error {
+				users, total, err := repo.FindUsers(&dto.UserFilter{Email: "example_", Role: "admin", Limit: 2, Offset: 2})
+				if err == nil {
+					assert.Equal(t, int64(3), total)
+					assert.Len(t, users, 1)
+					assert.Equal(t, "admin", users[0].Role)
+				}
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "SetDisabled - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET `disabled_at`=? WHERE id = ?").
+					WithArgs(sqlmock.AnyArg(), 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo UserRepository) error {
+				now := time.Now()
+				return repo.SetDisabled(3, &now)
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "UpdateRole - unknown user",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET `role`=? WHERE id = ?").
+					WithArgs("moderator", 3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo UserRepository) error {
+				return repo.UpdateRole(3, "moderator")
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "UpdateProfile - writes cleared fields",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET `display_name`=?,`avatar_url`=?,`birthday`=?,`locale`=?,`timezone`=?,`default_currency`=?,`updated_at`=? WHERE `id` = ?").
+					WithArgs("Ada", "", nil, "en-GB", "", "EUR", sqlmock.AnyArg(), 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo UserRepository) error {
+				return repo.UpdateProfile(&entities.User{Id: 3, DisplayName: "Ada", Locale: "en-GB", DefaultCurrency: "EUR"})
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "ScheduleDeletion - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET `deletion_scheduled_at`=? WHERE id = ? AND deletion_scheduled_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `account_deletions` (`user_id`,`email`,`scheduled_for`,`cancelled_at`,`erased_at`,`created_at`) VALUES (?,?,?,?,?,?)").
+					WithArgs(3, "user@example.com", sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+				mock.ExpectQuery("SELECT * FROM `refresh_tokens` WHERE user_id = ? AND revoked_at IS NULL").
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec("UPDATE `refresh_tokens` SET `revoked_at`=? WHERE user_id = ? AND revoked_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo UserRepository) error {
+				deletion := &entities.AccountDeletion{UserID: 3, Email: "user@example.com", ScheduledFor: time.Now().Add(time.Hour)}
+				err := repo.ScheduleDeletion(deletion)
+				assert.Equal(t, uint(7), deletion.ID)
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "ScheduleDeletion - already scheduled",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `users` SET `deletion_scheduled_at`=? WHERE id = ? AND deletion_scheduled_at IS NULL").
+					WithArgs(sqlmock.AnyArg(), 3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			run: func(t *testing.T, repo UserRepository) error {
+				return repo.ScheduleDeletion(&entities.AccountDeletion{UserID: 3, ScheduledFor: time.Now()})
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrDeletionScheduled)
+			},
+		},
+		{
+			name: "FindDueDeletions - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT * FROM `account_deletions` WHERE cancelled_at IS NULL AND erased_at IS NULL AND scheduled_for <= ? ORDER BY scheduled_for").
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(7, 3))
+			},
+			run: func(t *testing.T, repo UserRepository) error {
+				deletions, err := repo.FindDueDeletions(time.Now())
+				assert.Len(t, deletions, 1)
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "EraseAccount - success",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `users` WHERE id = ? AND deletion_scheduled_at <= ?").
+					WithArgs(3, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `account_deletions` SET `email`=?,`erased_at`=? WHERE `id` = ?").
+					WithArgs("", sqlmock.AnyArg(), 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(t *testing.T, repo UserRepository) error {
+				deletion := &entities.AccountDeletion{ID: 7, UserID: 3, Email: "user@example.com"}
+				err := repo.EraseAccount(deletion)
+				assert.Empty(t, deletion.Email)
+				assert.NotNil(t, deletion.ErasedAt)
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "EraseAccount - cancelled meanwhile",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `users` WHERE id = ? AND deletion_scheduled_at <= ?").
+					WithArgs(3, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			run: func(t *testing.T, repo UserRepository) error {
+				return repo.EraseAccount(&entities.AccountDeletion{ID: 7, UserID: 3})
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "FindDeletions - filters by user",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count(*) FROM `account_deletions` WHERE user_id = ?").
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery("SELECT * FROM `account_deletions` WHERE user_id = ? ORDER BY id DESC LIMIT ?").
+					WithArgs(3, 20).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(7, 3))
+			},
+			run: func(t *testing.T, repo UserRepository) error {
+				deletions, total, err := repo.FindDeletions(&dto.DeletionFilter{UserID: 3, Limit: 20})
+				assert.Equal(t, int64(1), total)
+				assert.Len(t, deletions, 1)
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "FindAccountData - includes trashed data",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT * FROM `users` WHERE `users`.`id` = ? ORDER BY `users`.`id` LIMIT ?").
+					WithArgs(3, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(3, "user@example.com"))
+				mock.ExpectQuery("SELECT * FROM `lists` WHERE user_id = ? ORDER BY id").
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(2, 3))
+				mock.ExpectQuery("SELECT * FROM `wishlists` WHERE user_id = ? ORDER BY id").
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "deleted_at"}).AddRow(5, 3, time.Now()))
+				mock.ExpectQuery("SELECT * FROM `wishlist_tags` WHERE `wishlist_tags`.`wishlist_id` = ?").
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"wishlist_id", "tag_id"}))
+				for _, table := range []string{"tags", "achievement_events", "sessions", "personal_access_tokens", "external_identities"} {
+					mock.ExpectQuery("SELECT * FROM `" + table + "` WHERE user_id = ? ORDER BY id").
+						WithArgs(3).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				}
+			},
+			run: func(t *testing.T, repo UserRepository) error {
+				data, err := repo.FindAccountData(3)
+				if err == nil {
+					assert.Equal(t, "user@example.com", data.User.Email)
+					assert.Len(t, data.Lists, 1)
+					assert.Len(t, data.Wishlists, 1)
+				}
+				return err
+			},
+			assertion: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewUserRepository(CreateGormDB(db))
+			tc.setup(mock)
+
+			tc.assertion(t, tc.run(t, repo))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -1,13 +1,31 @@
 package repositories
 
 import (
+	"errors"
+	"go-wishlist-api-2/dto"
 	"go-wishlist-api-2/entities"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"time"
 )
 
+// ErrQuantityBelowClaimed is returned when a wishlist's quantity would drop
+// below the units guests have already claimed.
+var ErrQuantityBelowClaimed = errors.New("quantity is below the units already claimed")
+
 type WishlistRepository interface {
-	GetAll() ([]*entities.Wishlist, error)
+	GetAll(filter *dto.WishlistFilter) ([]*entities.Wishlist, int64, error)
+	FindByID(id uint) (*entities.Wishlist, error)
 	CreateWishlist(wishlist *entities.Wishlist) (*entities.Wishlist, error)
+	UpdateWishlist(wishlist *entities.Wishlist) (*entities.Wishlist, error)
+	DeleteWishlist(id uint) error
+	GetTrash(userID int) ([]*entities.Wishlist, error)
+	FindTrashedByID(id uint) (*entities.Wishlist, error)
+	RestoreWishlist(id uint) error
+	PurgeWishlist(id uint) error
+	PurgeDeletedBefore(before time.Time) (int64, error)
+	GetAchievementHistory(wishlistID uint) ([]*entities.AchievementEvent, error)
 }
 
 type wishlistRepository struct {
@@ -18,16 +36,190 @@ func NewWishlistRepository(db *gorm.DB) *wishlistRepository {
 	return &wishlistRepository{db}
 }
 
-func (r *wishlistRepository) GetAll() ([]*entities.Wishlist, error) {
+// GetAll returns one page of the user's wishlists matching filter along with
+// the total number of matches, ignoring pagination.
+func (r *wishlistRepository) GetAll(filter *dto.WishlistFilter) ([]*entities.Wishlist, int64, error) {
+	query := r.db.Model(&entities.Wishlist{}).Where("user_id = ?", filter.UserID)
+	if filter.ListID != nil {
+		query = query.Where("list_id = ?", *filter.ListID)
+	}
+	if filter.IsAchieved != nil {
+		query = query.Where("is_achieved = ?", *filter.IsAchieved)
+	}
+	if filter.Title != "" {
+		query = query.Where("title LIKE ?", "%"+escapeLike(filter.Title)+"%")
+	}
+	if len(filter.Tags) > 0 {
+		tagged := r.db.Table("wishlist_tags").
+			Select("wishlist_tags.wishlist_id").
+			Joins("JOIN tags ON tags.id = wishlist_tags.tag_id").
+			Where("tags.user_id = ? AND tags.name IN ?", filter.UserID, filter.Tags)
+		if filter.MatchAllTags {
+			tagged = tagged.Group("wishlist_tags.wishlist_id").Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tags))
+		}
+		query = query.Where("id IN (?)", tagged)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil {
+		query = query.Where("updated_at >= ?", *filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo != nil {
+		query = query.Where("updated_at <= ?", *filter.UpdatedTo)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if len(filter.After) > 0 {
+		condition, args := keysetCondition(filter.Sort, filter.After)
+		query = query.Where(condition, args...)
+	}
+	for _, field := range filter.Sort {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: field.Column}, Desc: field.Desc})
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
 	var wishlists []*entities.Wishlist
-	if err := r.db.Find(&wishlists).Error; err != nil {
+	if err := query.Preload("Tags").Find(&wishlists).Error; err != nil {
+		return nil, 0, err
+	}
+	return wishlists, total, nil
+}
+
+func (r *wishlistRepository) FindByID(id uint) (*entities.Wishlist, error) {
+	var wishlist *entities.Wishlist
+	if err := r.db.Preload("Tags").First(&wishlist, id).Error; err != nil {
 		return nil, err
 	}
-	return wishlists, nil
+	return wishlist, nil
 }
+
 func (r *wishlistRepository) CreateWishlist(wishlist *entities.Wishlist) (*entities.Wishlist, error) {
 	if err := r.db.Create(&wishlist).Error; err != nil {
 		return nil, err
 	}
 	return wishlist, nil
 }
+
+// UpdateWishlist saves wishlist and replaces its tags. It returns
+// ErrQuantityBelowClaimed if the quantity is lowered below the units already
+// claimed; like CreateClaim it locks the wishlist row while checking.
+func (r *wishlistRepository) UpdateWishlist(wishlist *entities.Wishlist) (*entities.Wishlist, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var locked entities.Wishlist
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&locked, wishlist.ID).Error
+		if err != nil {
+			return err
+		}
+		var claimed int64
+		err = tx.Model(&entities.GiftClaim{}).
+			Select("COALESCE(SUM(quantity), 0)").
+			Where("wishlist_id = ?", wishlist.ID).
+			Scan(&claimed).Error
+		if err != nil {
+			return err
+		}
+		if int64(wishlist.Quantity) < claimed {
+			return ErrQuantityBelowClaimed
+		}
+		if err := tx.Omit("Tags").Save(&wishlist).Error; err != nil {
+			return err
+		}
+		return tx.Model(wishlist).Association("Tags").Replace(wishlist.Tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+func (r *wishlistRepository) DeleteWishlist(id uint) error {
+	return r.db.Delete(&entities.Wishlist{}, id).Error
+}
+
+func (r *wishlistRepository) GetTrash(userID int) ([]*entities.Wishlist, error) {
+	var wishlists []*entities.Wishlist
+	if err := r.db.Unscoped().Where("user_id = ? AND deleted_at IS NOT NULL", userID).Find(&wishlists).Error; err != nil {
+		return nil, err
+	}
+	return wishlists, nil
+}
+
+func (r *wishlistRepository) FindTrashedByID(id uint) (*entities.Wishlist, error) {
+	var wishlist *entities.Wishlist
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&wishlist, id).Error; err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+func (r *wishlistRepository) RestoreWishlist(id uint) error {
+	return r.db.Unscoped().Model(&entities.Wishlist{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r *wishlistRepository) PurgeWishlist(id uint) error {
+	return r.db.Unscoped().Delete(&entities.Wishlist{}, id).Error
+}
+
+func (r *wishlistRepository) PurgeDeletedBefore(before time.Time) (int64, error) {
+	result := r.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&entities.Wishlist{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// GetAchievementHistory returns the achievement events of a wishlist, oldest
+// first.
+func (r *wishlistRepository) GetAchievementHistory(wishlistID uint) ([]*entities.AchievementEvent, error) {
+	var events []*entities.AchievementEvent
+	if err := r.db.Where("wishlist_id = ?", wishlistID).Order("created_at").Order("id").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// keysetCondition builds the WHERE clause selecting rows that come strictly
+// after the row whose sort key values are given, e.g. for (title ASC, id DESC):
+// (title > ?) OR (title = ? AND id < ?).
+// Column names must already be validated by the caller.
+func keysetCondition(sort []dto.SortField, after []any) (string, []any) {
+	var (
+		disjuncts []string
+		args      []any
+	)
+	for i, field := range sort {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, sort[j].Column+" = ?")
+			args = append(args, after[j])
+		}
+		operator := " > ?"
+		if field.Desc {
+			operator = " < ?"
+		}
+		parts = append(parts, field.Column+operator)
+		args = append(args, after[i])
+		disjuncts = append(disjuncts, "("+strings.Join(parts, " AND ")+")")
+	}
+	return strings.Join(disjuncts, " OR "), args
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
+}
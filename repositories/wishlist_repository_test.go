@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/dto"
 	"go-wishlist-api-2/entities"
 	"testing"
 	"time"
@@ -28,9 +29,19 @@ func TestWishlistRepository(t *testing.T) {
 					AddRow(wishlists[0].ID, wishlists[0].Title, wishlists[0].IsAchieved).
 					AddRow(wishlists[1].ID, wishlists[1].Title, wishlists[1].IsAchieved)
 
-				query := "SELECT * FROM `wishlists` WHERE `wishlists`.`deleted_at` IS NULL"
+				mock.ExpectQuery("SELECT count(*) FROM `wishlists` WHERE user_id = ? AND `wishlists`.`deleted_at` IS NULL").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				query := "SELECT * FROM `wishlists` WHERE user_id = ? AND `wishlists`.`deleted_at` IS NULL ORDER BY `id` LIMIT ?"
 				mock.ExpectQuery(query).
+					WithArgs(1, 21).
 					WillReturnRows(rows)
+				mock.ExpectQuery("SELECT * FROM `wishlist_tags` WHERE `wishlist_tags`.`wishlist_id` IN (?,?)").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"wishlist_id", "tag_id"}).AddRow(1, 3))
+				mock.ExpectQuery("SELECT * FROM `tags` WHERE `tags`.`id` = ?").
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name"}).AddRow(3, 1, "tech"))
 			},
 			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
 				assert.NoError(t, err)
@@ -38,13 +49,16 @@ func TestWishlistRepository(t *testing.T) {
 				assert.Len(t, wishlists, 2)
 				assert.Equal(t, wishlists[0].Title, "Wishlist 1")
 				assert.Equal(t, wishlists[1].Title, "Wishlist 2")
+				assert.Equal(t, "tech", wishlists[0].Tags[0].Name)
+				assert.Empty(t, wishlists[1].Tags)
 			},
 		},
 		{
 			name: "GetAll - error",
 			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
-				query := "SELECT * FROM `wishlists` WHERE `wishlists`.`deleted_at` IS NULL"
+				query := "SELECT count(*) FROM `wishlists` WHERE user_id = ? AND `wishlists`.`deleted_at` IS NULL"
 				mock.ExpectQuery(query).
+					WithArgs(1).
 					WillReturnError(fmt.Errorf("Failed to get wishlists"))
 			},
 			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
@@ -52,11 +66,77 @@ func TestWishlistRepository(t *testing.T) {
 				assert.Nil(t, wishlists)
 			},
 		},
+		{
+			name: "GetAll - filtered by cursor",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				mock.ExpectQuery("SELECT count(*) FROM `wishlists` WHERE user_id = ? AND is_achieved = ? AND title LIKE ? AND `wishlists`.`deleted_at` IS NULL").
+					WithArgs(1, false, "%50\\%%").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+				query := "SELECT * FROM `wishlists` WHERE user_id = ? AND is_achieved = ? AND title LIKE ? AND ((title < ?) OR (title = ? AND id > ?)) AND `wishlists`.`deleted_at` IS NULL ORDER BY `title` DESC,`id` LIMIT ?"
+				mock.ExpectQuery(query).
+					WithArgs(1, false, "%50\\%%", "b", "b", 2, 3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "a"))
+				mock.ExpectQuery("SELECT * FROM `wishlist_tags` WHERE `wishlist_tags`.`wishlist_id` = ?").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"wishlist_id", "tag_id"}))
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.NoError(t, err)
+				assert.Len(t, wishlists, 1)
+			},
+		},
+		{
+			name: "GetAll - filtered by tags",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				query := "SELECT count(*) FROM `wishlists` WHERE user_id = ? AND id IN (SELECT wishlist_tags.wishlist_id FROM `wishlist_tags` JOIN tags ON tags.id = wishlist_tags.tag_id WHERE tags.user_id = ? AND tags.name IN (?,?) GROUP BY `wishlist_tags`.`wishlist_id` HAVING COUNT(DISTINCT tags.id) = ?) AND `wishlists`.`deleted_at` IS NULL"
+				mock.ExpectQuery(query).
+					WithArgs(1, 1, "tech", "books", 2).
+					WillReturnError(fmt.Errorf("Failed to count wishlists"))
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "FindByID - success",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				rows := sqlmock.
+					NewRows([]string{"id", "user_id", "title", "is_achieved"}).
+					AddRow(1, 1, "Wishlist 1", false)
+
+				query := "SELECT * FROM `wishlists` WHERE `wishlists`.`id` = ? AND `wishlists`.`deleted_at` IS NULL ORDER BY `wishlists`.`id` LIMIT ?"
+				mock.ExpectQuery(query).
+					WithArgs(1, 1).
+					WillReturnRows(rows)
+				mock.ExpectQuery("SELECT * FROM `wishlist_tags` WHERE `wishlist_tags`.`wishlist_id` = ?").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"wishlist_id", "tag_id"}))
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.NoError(t, err)
+				assert.Len(t, wishlists, 1)
+				assert.Equal(t, 1, wishlists[0].UserID)
+			},
+		},
+		{
+			name: "FindByID - error",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				query := "SELECT * FROM `wishlists` WHERE `wishlists`.`id` = ? AND `wishlists`.`deleted_at` IS NULL ORDER BY `wishlists`.`id` LIMIT ?"
+				mock.ExpectQuery(query).
+					WithArgs(1, 1).
+					WillReturnError(fmt.Errorf("Failed to find wishlist"))
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.Error(t, err)
+				assert.Nil(t, wishlists)
+			},
+		},
 		{
 			name: "Create - success",
 			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
 				wishlist := &entities.Wishlist{
 					ID:         1,
+					UserID:     1,
 					Title:      "New Wishlist",
 					IsAchieved: false,
 					CreatedAt:  time.Now(),
@@ -64,9 +144,9 @@ func TestWishlistRepository(t *testing.T) {
 				}
 
 				mock.ExpectBegin()
-				query := "INSERT INTO `wishlists` (`title`,`is_achieved`,`created_at`,`updated_at`,`deleted_at`,`id`) VALUES (?,?,?,?,?,?)"
+				query := "INSERT INTO `wishlists` (`user_id`,`list_id`,`title`,`is_achieved`,`achieved_at`,`price_amount`,`currency`,`url`,`priority`,`quantity`,`notes`,`target_date`,`created_at`,`updated_at`,`deleted_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
 				mock.ExpectExec(query).
-					WithArgs(wishlist.Title, wishlist.IsAchieved, wishlist.CreatedAt, wishlist.UpdatedAt, nil, 1).
+					WithArgs(wishlist.UserID, nil, wishlist.Title, wishlist.IsAchieved, nil, nil, "", "", entities.PriorityMedium, 1, "", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit()
 			},
@@ -82,15 +162,16 @@ func TestWishlistRepository(t *testing.T) {
 			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
 				wishlist := &entities.Wishlist{
 					ID:         1,
+					UserID:     1,
 					Title:      "New Wishlist",
 					IsAchieved: false,
 					CreatedAt:  time.Now(),
 					UpdatedAt:  time.Now(),
 				}
 				mock.ExpectBegin()
-				query := "INSERT INTO `wishlists` (`title`,`is_achieved`,`created_at`,`updated_at`,`deleted_at`,`id`) VALUES (?,?,?,?,?,?)"
+				query := "INSERT INTO `wishlists` (`user_id`,`list_id`,`title`,`is_achieved`,`achieved_at`,`price_amount`,`currency`,`url`,`priority`,`quantity`,`notes`,`target_date`,`created_at`,`updated_at`,`deleted_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
 				mock.ExpectExec(query).
-					WithArgs(wishlist.Title, wishlist.IsAchieved, wishlist.CreatedAt, wishlist.UpdatedAt, nil, 1).
+					WithArgs(wishlist.UserID, nil, wishlist.Title, wishlist.IsAchieved, nil, nil, "", "", entities.PriorityMedium, 1, "", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1).
 					WillReturnError(fmt.Errorf("Failed to create wishlist"))
 				mock.ExpectRollback()
 			},
@@ -99,6 +180,186 @@ func TestWishlistRepository(t *testing.T) {
 				assert.Nil(t, wishlists)
 			},
 		},
+		{
+			name: "Update - success",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT `id` FROM `wishlists` WHERE `wishlists`.`id` = ? AND `wishlists`.`deleted_at` IS NULL ORDER BY `wishlists`.`id` LIMIT ? FOR UPDATE").
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery("SELECT COALESCE(SUM(quantity), 0) FROM `gift_claims` WHERE wishlist_id = ?").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+				query := "UPDATE `wishlists` SET `user_id`=?,`list_id`=?,`title`=?,`is_achieved`=?,`achieved_at`=?,`price_amount`=?,`currency`=?,`url`=?,`priority`=?,`quantity`=?,`notes`=?,`target_date`=?,`created_at`=?,`updated_at`=?,`deleted_at`=? WHERE `wishlists`.`deleted_at` IS NULL AND `id` = ?"
+				mock.ExpectExec(query).
+					WithArgs(1, nil, "Updated Wishlist", true, nil, nil, "", "", "", 0, "", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `wishlists` SET `updated_at`=? WHERE `wishlists`.`deleted_at` IS NULL AND `id` = ?").
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `wishlist_tags` WHERE `wishlist_tags`.`wishlist_id` = ?").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.NoError(t, err)
+				assert.Len(t, wishlists, 1)
+				assert.Equal(t, "Updated Wishlist", wishlists[0].Title)
+			},
+		},
+		{
+			name: "Update - with achievement event",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT `id` FROM `wishlists` WHERE `wishlists`.`id` = ? AND `wishlists`.`deleted_at` IS NULL ORDER BY `wishlists`.`id` LIMIT ? FOR UPDATE").
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery("SELECT COALESCE(SUM(quantity), 0) FROM `gift_claims` WHERE wishlist_id = ?").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+				query := "UPDATE `wishlists` SET `user_id`=?,`list_id`=?,`title`=?,`is_achieved`=?,`achieved_at`=?,`price_amount`=?,`currency`=?,`url`=?,`priority`=?,`quantity`=?,`notes`=?,`target_date`=?,`created_at`=?,`updated_at`=?,`deleted_at`=? WHERE `wishlists`.`deleted_at` IS NULL AND `id` = ?"
+				mock.ExpectExec(query).
+					WithArgs(1, nil, "Updated Wishlist", true, sqlmock.AnyArg(), nil, "", "", "", 0, "", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `achievement_events` (`wishlist_id`,`user_id`,`achieved`,`created_at`) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE `wishlist_id`=VALUES(`wishlist_id`)").
+					WithArgs(1, 1, true, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE `wishlists` SET `updated_at`=? WHERE `wishlists`.`deleted_at` IS NULL AND `id` = ?").
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `wishlist_tags` WHERE `wishlist_tags`.`wishlist_id` = ?").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.NoError(t, err)
+				assert.Len(t, wishlists, 1)
+				assert.Equal(t, uint(1), wishlists[0].AchievementEvents[0].ID)
+			},
+		},
+		{
+			name: "Update - quantity below claimed",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT `id` FROM `wishlists` WHERE `wishlists`.`id` = ? AND `wishlists`.`deleted_at` IS NULL ORDER BY `wishlists`.`id` LIMIT ? FOR UPDATE").
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery("SELECT COALESCE(SUM(quantity), 0) FROM `gift_claims` WHERE wishlist_id = ?").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(2))
+				mock.ExpectRollback()
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.ErrorIs(t, err, ErrQuantityBelowClaimed)
+			},
+		},
+		{
+			name: "GetAchievementHistory - success",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				rows := sqlmock.
+					NewRows([]string{"id", "wishlist_id", "user_id", "achieved", "created_at"}).
+					AddRow(1, 1, 1, true, time.Now()).
+					AddRow(2, 1, 1, false, time.Now())
+
+				query := "SELECT * FROM `achievement_events` WHERE wishlist_id = ? ORDER BY created_at,id"
+				mock.ExpectQuery(query).
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Delete - success",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				mock.ExpectBegin()
+				query := "UPDATE `wishlists` SET `deleted_at`=? WHERE `wishlists`.`id` = ? AND `wishlists`.`deleted_at` IS NULL"
+				mock.ExpectExec(query).
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Delete - error",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				mock.ExpectBegin()
+				query := "UPDATE `wishlists` SET `deleted_at`=? WHERE `wishlists`.`id` = ? AND `wishlists`.`deleted_at` IS NULL"
+				mock.ExpectExec(query).
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnError(fmt.Errorf("Failed to delete wishlist"))
+				mock.ExpectRollback()
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "GetTrash - success",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				rows := sqlmock.
+					NewRows([]string{"id", "user_id", "title", "deleted_at"}).
+					AddRow(1, 1, "Wishlist 1", time.Now())
+
+				query := "SELECT * FROM `wishlists` WHERE user_id = ? AND deleted_at IS NOT NULL"
+				mock.ExpectQuery(query).
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.NoError(t, err)
+				assert.Len(t, wishlists, 1)
+				assert.True(t, wishlists[0].DeletedAt.Valid)
+			},
+		},
+		{
+			name: "Restore - success",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				mock.ExpectBegin()
+				query := "UPDATE `wishlists` SET `deleted_at`=?,`updated_at`=? WHERE id = ?"
+				mock.ExpectExec(query).
+					WithArgs(nil, sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "Purge - success",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				mock.ExpectBegin()
+				query := "DELETE FROM `wishlists` WHERE `wishlists`.`id` = ?"
+				mock.ExpectExec(query).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "PurgeDeletedBefore - success",
+			setup: func(mock sqlmock.Sqlmock, repo WishlistRepository) {
+				mock.ExpectBegin()
+				query := "DELETE FROM `wishlists` WHERE deleted_at IS NOT NULL AND deleted_at < ?"
+				mock.ExpectExec(query).
+					WithArgs(sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			assertion: func(t *testing.T, err error, wishlists []*entities.Wishlist) {
+				assert.NoError(t, err)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -115,14 +376,46 @@ func TestWishlistRepository(t *testing.T) {
 			tc.setup(mock, repo)
 
 			if tc.name == "GetAll - success" {
-				wishlists, err := repo.GetAll()
+				wishlists, total, err := repo.GetAll(&dto.WishlistFilter{
+					UserID: 1,
+					Sort:   []dto.SortField{{Column: "id"}},
+					Limit:  21,
+				})
+				assert.Equal(t, int64(2), total)
+				tc.assertion(t, err, wishlists)
+			} else if tc.name == "GetAll - filtered by cursor" {
+				achieved := false
+				wishlists, total, err := repo.GetAll(&dto.WishlistFilter{
+					UserID:     1,
+					IsAchieved: &achieved,
+					Title:      "50%",
+					Sort:       []dto.SortField{{Column: "title", Desc: true}, {Column: "id"}},
+					After:      []any{"b", uint(2)},
+					Limit:      3,
+				})
+				assert.Equal(t, int64(5), total)
 				tc.assertion(t, err, wishlists)
 			} else if tc.name == "GetAll - error" {
-				_, err := repo.GetAll()
+				_, _, err := repo.GetAll(&dto.WishlistFilter{UserID: 1})
+				tc.assertion(t, err, nil)
+			} else if tc.name == "GetAll - filtered by tags" {
+				_, _, err := repo.GetAll(&dto.WishlistFilter{
+					UserID:       1,
+					Tags:         []string{"tech", "books"},
+					MatchAllTags: true,
+					Sort:         []dto.SortField{{Column: "id"}},
+				})
+				tc.assertion(t, err, nil)
+			} else if tc.name == "FindByID - success" {
+				got, err := repo.FindByID(1)
+				tc.assertion(t, err, []*entities.Wishlist{got})
+			} else if tc.name == "FindByID - error" {
+				_, err := repo.FindByID(1)
 				tc.assertion(t, err, nil)
 			} else if tc.name == "Create - success" {
 				wishlist := &entities.Wishlist{
 					ID:         1,
+					UserID:     1,
 					Title:      "New Wishlist",
 					IsAchieved: false,
 					CreatedAt:  time.Now(),
@@ -133,6 +426,7 @@ func TestWishlistRepository(t *testing.T) {
 			} else if tc.name == "Create - error" {
 				wishlist := &entities.Wishlist{
 					ID:         1,
+					UserID:     1,
 					Title:      "New Wishlist",
 					IsAchieved: false,
 					CreatedAt:  time.Now(),
@@ -140,7 +434,55 @@ func TestWishlistRepository(t *testing.T) {
 				}
 				_, err := repo.CreateWishlist(wishlist)
 				tc.assertion(t, err, nil)
+			} else if tc.name == "Update - success" {
+				wishlist := &entities.Wishlist{
+					ID:         1,
+					UserID:     1,
+					Title:      "Updated Wishlist",
+					IsAchieved: true,
+					CreatedAt:  time.Now(),
+				}
+				got, err := repo.UpdateWishlist(wishlist)
+				tc.assertion(t, err, []*entities.Wishlist{got})
+			} else if tc.name == "Update - with achievement event" {
+				achievedAt := time.Now()
+				wishlist := &entities.Wishlist{
+					ID:                1,
+					UserID:            1,
+					Title:             "Updated Wishlist",
+					IsAchieved:        true,
+					AchievedAt:        &achievedAt,
+					AchievementEvents: []entities.AchievementEvent{{UserID: 1, Achieved: true, CreatedAt: achievedAt}},
+					CreatedAt:         time.Now(),
+				}
+				got, err := repo.UpdateWishlist(wishlist)
+				tc.assertion(t, err, []*entities.Wishlist{got})
+			} else if tc.name == "Update - quantity below claimed" {
+				_, err := repo.UpdateWishlist(&entities.Wishlist{ID: 1, UserID: 1, Title: "Bike", Quantity: 1})
+				tc.assertion(t, err, nil)
+			} else if tc.name == "GetAchievementHistory - success" {
+				events, err := repo.GetAchievementHistory(1)
+				assert.Len(t, events, 2)
+				assert.False(t, events[1].Achieved)
+				tc.assertion(t, err, nil)
+			} else if tc.name == "Delete - success" || tc.name == "Delete - error" {
+				err := repo.DeleteWishlist(1)
+				tc.assertion(t, err, nil)
+			} else if tc.name == "GetTrash - success" {
+				wishlists, err := repo.GetTrash(1)
+				tc.assertion(t, err, wishlists)
+			} else if tc.name == "Restore - success" {
+				err := repo.RestoreWishlist(1)
+				tc.assertion(t, err, nil)
+			} else if tc.name == "Purge - success" {
+				err := repo.PurgeWishlist(1)
+				tc.assertion(t, err, nil)
+			} else if tc.name == "PurgeDeletedBefore - success" {
+				purged, err := repo.PurgeDeletedBefore(time.Now())
+				assert.Equal(t, int64(2), purged)
+				tc.assertion(t, err, nil)
 			}
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
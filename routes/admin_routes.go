@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/config"
+	"go-wishlist-api-2/handlers"
+	"go-wishlist-api-2/middlewares"
+	"go-wishlist-api-2/rbac"
+	"go-wishlist-api-2/repositories"
+	"go-wishlist-api-2/usecases"
+)
+
+// AdminRouter serves /admin. Every route requires a JWT whose role grants the
+// route's permission; personal access tokens are not accepted.
+func AdminRouter(admin *echo.Group, auth usecases.AuthUsecase) {
+	tokenRepository := repositories.NewTokenRepository(config.DB)
+	usecase := usecases.NewAdminUsecase(
+		repositories.NewUserRepository(config.DB),
+		tokenRepository,
+		repositories.NewWishlistRepository(config.DB),
+		auth,
+	)
+	handler := handlers.NewAdminHandler(usecase)
+	admin.Use(middlewares.JWT(tokenRepository))
+	admin.GET("/users", handler.GetUsers, middlewares.RequirePermission(rbac.ListUsers))
+	admin.GET("/users/:id", handler.GetUser, middlewares.RequirePermission(rbac.ListUsers))
+	admin.POST("/users/:id/disable", handler.DisableUser, middlewares.RequirePermission(rbac.ManageUsers))
+	admin.POST("/users/:id/enable", handler.EnableUser, middlewares.RequirePermission(rbac.ManageUsers))
+	admin.POST("/users/:id/unlock", handler.UnlockUser, middlewares.RequirePermission(rbac.ManageUsers))
+	admin.PUT("/users/:id/role", handler.SetRole, middlewares.RequirePermission(rbac.ManageRoles))
+	admin.GET("/wishlists/:id", handler.GetWishlist, middlewares.RequirePermission(rbac.ReadAnyWishlist))
+	admin.GET("/deletions", handler.GetDeletions, middlewares.RequirePermission(rbac.ReadAuditLog))
+}
@@ -4,14 +4,63 @@ import (
 	"github.com/labstack/echo/v4"
 	"go-wishlist-api-2/config"
 	"go-wishlist-api-2/handlers"
+	"go-wishlist-api-2/lockout"
+	"go-wishlist-api-2/middlewares"
+	"go-wishlist-api-2/passwordpolicy"
 	"go-wishlist-api-2/repositories"
 	"go-wishlist-api-2/usecases"
 )
 
-func AuthRouter(wishlist *echo.Group) {
+// AuthRouter serves the account routes and returns the auth usecase for the
+// routers that share its lockout state.
+func AuthRouter(wishlist *echo.Group) usecases.AuthUsecase {
 	repository := repositories.NewAuthRepository(config.DB)
-	usecase := usecases.NewAuthUsecase(repository)
+	tokenRepository := repositories.NewTokenRepository(config.DB)
+	accountPolicy := lockout.DefaultAccountPolicy
+	accountPolicy.LockoutThreshold = config.ENV.LOGIN_LOCKOUT_THRESHOLD
+	accountPolicy.LockoutDuration = config.ENV.LOGIN_LOCKOUT_DURATION
+	ipPolicy := lockout.DefaultIPPolicy
+	ipPolicy.LockoutThreshold = config.ENV.LOGIN_IP_LOCKOUT_THRESHOLD
+	passwordPolicy := passwordpolicy.DefaultPolicy
+	passwordPolicy.MinLength = config.ENV.PASSWORD_MIN_LENGTH
+	passwordPolicy.MinCharacterClasses = config.ENV.PASSWORD_MIN_CLASSES
+	if config.ENV.BREACHED_PASSWORDS_DIR != "" {
+		passwordPolicy.Breached = passwordpolicy.HashPrefixDir{Dir: config.ENV.BREACHED_PASSWORDS_DIR}
+	}
+	usecase := usecases.NewAuthUsecase(repository, tokenRepository, config.Mailer, usecases.AuthOptions{
+		AppURL:           config.ENV.APP_URL,
+		VerificationTTL:  config.ENV.EMAIL_VERIFICATION_TTL,
+		ResendInterval:   config.ENV.VERIFICATION_RESEND_WAIT,
+		PasswordResetTTL: config.ENV.PASSWORD_RESET_TTL,
+		PasswordResetURL: config.ENV.PASSWORD_RESET_URL,
+		AccountLimiter:   lockout.NewLimiter(lockout.NewMemoryStore(), accountPolicy),
+		IPLimiter:        lockout.NewLimiter(lockout.NewMemoryStore(), ipPolicy),
+		TOTPIssuer:       config.ENV.TOTP_ISSUER,
+		MFAChallengeTTL:  config.ENV.MFA_CHALLENGE_TTL,
+		PasswordPolicy:   &passwordPolicy,
+		OIDCProviders:    config.OIDCProviders,
+		OIDCLoginTTL:     config.ENV.OIDC_LOGIN_TTL,
+	})
 	handler := handlers.NewAuthHandler(usecase)
+	wishlist.GET("/.well-known/jwks.json", handler.JWKS)
 	wishlist.POST("/register", handler.Register)
 	wishlist.POST("/login", handler.Login)
+	wishlist.POST("/login/mfa", handler.VerifyMFA)
+	wishlist.GET("/auth/oidc/:provider", handler.BeginOIDCLogin)
+	wishlist.GET("/auth/oidc/:provider/callback", handler.CompleteOIDCLogin)
+	wishlist.GET("/verify-email", handler.VerifyEmail)
+	wishlist.POST("/verify-email/resend", handler.ResendVerification)
+	wishlist.POST("/password/forgot", handler.ForgotPassword)
+	wishlist.GET("/password/reset", handler.ResetPasswordForm)
+	wishlist.POST("/password/reset", handler.ResetPassword)
+	wishlist.GET("/email/confirm", handler.ConfirmEmailChange)
+	wishlist.POST("/refresh", handler.Refresh)
+	wishlist.POST("/logout", handler.Logout, middlewares.JWT(tokenRepository))
+	wishlist.POST("/logout-all", handler.LogoutAll, middlewares.JWT(tokenRepository))
+	wishlist.PUT("/me/password", handler.ChangePassword, middlewares.JWT(tokenRepository))
+	wishlist.PUT("/me/email", handler.ChangeEmail, middlewares.JWT(tokenRepository))
+	wishlist.POST("/me/2fa/totp", handler.EnrollTOTP, middlewares.JWT(tokenRepository))
+	wishlist.POST("/me/2fa/totp/confirm", handler.ConfirmTOTP, middlewares.JWT(tokenRepository))
+	wishlist.DELETE("/me/2fa/totp", handler.DisableTOTP, middlewares.JWT(tokenRepository))
+	return usecase
 }
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/config"
+	"go-wishlist-api-2/handlers"
+	"go-wishlist-api-2/middlewares"
+	"go-wishlist-api-2/repositories"
+	"go-wishlist-api-2/usecases"
+)
+
+func ListRouter(list *echo.Group) {
+	repository := repositories.NewListRepository(config.DB)
+	wishlistRepository := repositories.NewWishlistRepository(config.DB)
+	tagRepository := repositories.NewTagRepository(config.DB)
+	usecase := usecases.NewListUsecase(repository)
+	wishlistUsecase := usecases.NewWishlistUsecase(wishlistRepository, repository, tagRepository)
+	handler := handlers.NewListHandler(usecase, wishlistUsecase)
+	claimRepository := repositories.NewClaimRepository(config.DB)
+	shareHandler := handlers.NewShareHandler(usecases.NewShareUsecase(repository, wishlistRepository, claimRepository, config.ENV.APP_URL, nil))
+	list.Use(middlewares.JWT(repositories.NewTokenRepository(config.DB)))
+	list.GET("", handler.GetAll)
+	list.POST("", handler.Create)
+	list.GET("/:id", handler.GetByID)
+	list.PUT("/:id", handler.Update)
+	list.DELETE("/:id", handler.Delete)
+	list.GET("/:id/items", handler.GetItems)
+	list.POST("/:id/items", handler.CreateItem)
+	list.GET("/:id/share", shareHandler.Get)
+	list.POST("/:id/share", shareHandler.Create)
+	list.PATCH("/:id/share", shareHandler.UpdateSettings)
+	list.POST("/:id/share/regenerate", shareHandler.Regenerate)
+	list.DELETE("/:id/share", shareHandler.Revoke)
+}
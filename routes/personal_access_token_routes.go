@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/config"
+	"go-wishlist-api-2/handlers"
+	"go-wishlist-api-2/middlewares"
+	"go-wishlist-api-2/repositories"
+	"go-wishlist-api-2/usecases"
+)
+
+// PersonalAccessTokenRouter serves /me/tokens. Managing tokens requires a
+// JWT, so a leaked personal access token cannot be used to mint new ones.
+func PersonalAccessTokenRouter(tokens *echo.Group) {
+	repository := repositories.NewPersonalAccessTokenRepository(config.DB)
+	usecase := usecases.NewPersonalAccessTokenUsecase(repository)
+	handler := handlers.NewPersonalAccessTokenHandler(usecase)
+	tokens.Use(middlewares.JWT(repositories.NewTokenRepository(config.DB)))
+	tokens.GET("", handler.GetAll)
+	tokens.POST("", handler.Create)
+	tokens.DELETE("/:id", handler.Revoke)
+}
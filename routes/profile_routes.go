@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/config"
+	"go-wishlist-api-2/handlers"
+	"go-wishlist-api-2/middlewares"
+	"go-wishlist-api-2/repositories"
+	"go-wishlist-api-2/usecases"
+)
+
+// ProfileRouter serves /me, the signed-in user's own profile, data export
+// and account deletion.
+func ProfileRouter(me *echo.Group) {
+	tokenRepository := repositories.NewTokenRepository(config.DB)
+	userRepository := repositories.NewUserRepository(config.DB)
+	usecase := usecases.NewProfileUsecase(userRepository)
+	handler := handlers.NewProfileHandler(usecase)
+	accountHandler := handlers.NewAccountHandler(newAccountUsecase(userRepository))
+	me.Use(middlewares.JWT(tokenRepository))
+	me.GET("", handler.Get)
+	me.PATCH("", handler.Update)
+	me.DELETE("", accountHandler.Delete)
+	me.GET("/export", accountHandler.Export)
+}
+
+// AccountRouter serves /account, where users confirm the deletion of their
+// account from the link they were emailed. It needs no sign-in: the emailed
+// token proves who the user is.
+func AccountRouter(account *echo.Group) {
+	handler := handlers.NewAccountHandler(newAccountUsecase(repositories.NewUserRepository(config.DB)))
+	account.GET("/delete", handler.ConfirmDeletionForm)
+	account.POST("/delete", handler.ConfirmDeletion)
+}
+
+func newAccountUsecase(r repositories.UserRepository) usecases.AccountUsecase {
+	return usecases.NewAccountUsecase(r, config.Mailer, config.ENV.ACCOUNT_DELETION_GRACE, config.ENV.APP_URL)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/config"
+	"go-wishlist-api-2/handlers"
+	"go-wishlist-api-2/middlewares"
+	"go-wishlist-api-2/repositories"
+	"go-wishlist-api-2/usecases"
+)
+
+// SessionRouter serves /me/sessions, the devices the user is signed in on.
+func SessionRouter(sessions *echo.Group) {
+	tokenRepository := repositories.NewTokenRepository(config.DB)
+	usecase := usecases.NewSessionUsecase(tokenRepository)
+	handler := handlers.NewSessionHandler(usecase)
+	sessions.Use(middlewares.JWT(tokenRepository))
+	sessions.GET("", handler.GetAll)
+	sessions.DELETE("/:id", handler.Revoke)
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/config"
+	"go-wishlist-api-2/handlers"
+	"go-wishlist-api-2/middlewares"
+	"go-wishlist-api-2/repositories"
+	"go-wishlist-api-2/usecases"
+)
+
+// SharedRouter serves /shared, the lists their owners shared by link. It
+// needs no authentication; the token in the URL grants access to the list
+// and its gift claims. Signed in visitors are recognised so that owners do
+// not see the claims on their own lists in surprise mode.
+func SharedRouter(shared *echo.Group) {
+	usecase := usecases.NewShareUsecase(
+		repositories.NewListRepository(config.DB),
+		repositories.NewWishlistRepository(config.DB),
+		repositories.NewClaimRepository(config.DB),
+		config.ENV.APP_URL,
+		nil,
+	)
+	handler := handlers.NewShareHandler(usecase)
+	shared.Use(middlewares.OptionalJWT(repositories.NewTokenRepository(config.DB)))
+	shared.GET("/:token", handler.View)
+	shared.POST("/:token/items/:id/claims", handler.Claim)
+	shared.PATCH("/:token/claims/:id", handler.UpdateClaim)
+	shared.DELETE("/:token/claims/:id", handler.ReleaseClaim)
+}
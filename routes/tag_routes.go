@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"go-wishlist-api-2/config"
+	"go-wishlist-api-2/handlers"
+	"go-wishlist-api-2/middlewares"
+	"go-wishlist-api-2/repositories"
+	"go-wishlist-api-2/usecases"
+)
+
+func TagRouter(tag *echo.Group) {
+	repository := repositories.NewTagRepository(config.DB)
+	usecase := usecases.NewTagUsecase(repository)
+	handler := handlers.NewTagHandler(usecase)
+	tag.Use(middlewares.JWT(repositories.NewTokenRepository(config.DB)))
+	tag.GET("", handler.Search)
+	tag.PATCH("/:id", handler.Rename)
+	tag.POST("/:id/merge", handler.Merge)
+}
@@ -1,20 +1,34 @@
 package routes
 
 import (
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
-	"github.com/spf13/viper"
 	"go-wishlist-api-2/config"
+	"go-wishlist-api-2/entities"
 	"go-wishlist-api-2/handlers"
+	"go-wishlist-api-2/middlewares"
 	"go-wishlist-api-2/repositories"
 	"go-wishlist-api-2/usecases"
 )
 
 func WishlistRouter(wishlist *echo.Group) {
 	repository := repositories.NewWishlistRepository(config.DB)
-	usecase := usecases.NewWishlistUsecase(repository)
+	listRepository := repositories.NewListRepository(config.DB)
+	tagRepository := repositories.NewTagRepository(config.DB)
+	usecase := usecases.NewWishlistUsecase(repository, listRepository, tagRepository)
 	handler := handlers.NewWishlistHandler(usecase)
-	wishlist.Use(echojwt.JWT([]byte(viper.GetString("SECRET_TOKEN"))))
+	personalAccessTokens := usecases.NewPersonalAccessTokenUsecase(repositories.NewPersonalAccessTokenRepository(config.DB))
+	wishlist.Use(
+		middlewares.JWTOrPersonalAccessToken(repositories.NewTokenRepository(config.DB), personalAccessTokens),
+		middlewares.RequireScopes(entities.ScopeWishlistsRead, entities.ScopeWishlistsWrite),
+	)
 	wishlist.GET("", handler.GetAll)
 	wishlist.POST("", handler.Create)
+	wishlist.GET("/trash", handler.GetTrash)
+	wishlist.DELETE("/trash/:id", handler.Purge)
+	wishlist.GET("/:id", handler.GetByID)
+	wishlist.PUT("/:id", handler.Update)
+	wishlist.PATCH("/:id", handler.Patch)
+	wishlist.DELETE("/:id", handler.Delete)
+	wishlist.POST("/:id/restore", handler.Restore)
+	wishlist.GET("/:id/history", handler.History)
 }
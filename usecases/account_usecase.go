@@ -0,0 +1,431 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/mailer"
+	"go-wishlist-api-2/repositories"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultDeletionGracePeriod is how long a deleted account can still be
+// recovered by signing in again.
+const defaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+// deletionConfirmationTTL is how long the link in a deletion confirmation
+// email stays valid.
+const deletionConfirmationTTL = time.Hour
+
+// Deletion statuses reported in dto.AccountDeletionResponse.
+const (
+	DeletionScheduled = "scheduled"
+	DeletionCancelled = "cancelled"
+	DeletionErased    = "erased"
+)
+
+// Activity types of a data export.
+const (
+	activitySignedIn   = "signed_in"
+	activityAchieved   = "achieved"
+	activityUnachieved = "unachieved"
+)
+
+type AccountUsecase interface {
+	Export(userID int) ([]byte, error)
+	Delete(userID int, request *dto.PasswordRequest) (*dto.AccountDeletionResponse, error)
+	RequestDeletion(userID int) error
+	ConfirmDeletion(request *dto.TokenRequest) (*dto.AccountDeletionResponse, error)
+	EraseDueAccounts() (int64, error)
+}
+
+type accountUsecase struct {
+	repository  repositories.UserRepository
+	mailer      mailer.Mailer
+	gracePeriod time.Duration
+	appURL      string
+}
+
+// NewAccountUsecase builds the account usecase. appURL is the public base URL
+// used to build the link in deletion confirmation emails.
+func NewAccountUsecase(r repositories.UserRepository, m mailer.Mailer, gracePeriod time.Duration, appURL string) *accountUsecase {
+	if gracePeriod <= 0 {
+		gracePeriod = defaultDeletionGracePeriod
+	}
+	if appURL == "" {
+		appURL = defaultAppURL
+	}
+	return &accountUsecase{r, m, gracePeriod, strings.TrimRight(appURL, "/")}
+}
+
+// Export returns a zip archive of everything stored about the user: all of
+// it as account.json and the lists, wishlists and activity also as CSV files
+// for spreadsheets.
+func (uc *accountUsecase) Export(userID int) ([]byte, error) {
+	data, err := uc.repository.FindAccountData(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.NotFoundError{Message: "User not found"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	archive, err := writeExportArchive(toAccountExport(data, time.Now()))
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return archive, nil
+}
+
+// Delete schedules the erasure of the user's account after the grace period
+// and signs the user out everywhere. Signing in again before the account is
+// erased cancels the deletion.
+func (uc *accountUsecase) Delete(userID int, request *dto.PasswordRequest) (*dto.AccountDeletionResponse, error) {
+	user, err := uc.repository.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.UnAuthorizedError{Message: "User no longer exists"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if err := helper.VerifyPassword(request.Password, user.Password); err != nil {
+		return nil, &errorHandler.BadRequestError{Message: "Password is wrong"}
+	}
+	return uc.scheduleDeletion(user)
+}
+
+// RequestDeletion emails the user a link to confirm the deletion of their
+// account instead of asking for the password, which accounts created through
+// single sign-on never had.
+func (uc *accountUsecase) RequestDeletion(userID int) error {
+	user, err := uc.repository.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &errorHandler.UnAuthorizedError{Message: "User no longer exists"}
+		}
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if user.DeletionScheduledAt != nil {
+		return errDeletionScheduled()
+	}
+
+	token, err := helper.GenerateActionToken(user, helper.PurposeDeleteAccount, deletionConfirmationTTL)
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	link := uc.appURL + "/account/delete?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Open the link below to delete your Wishlist account:\n\n%s\n\n"+
+		"The link expires in %s. If you did not ask to delete your account, ignore this email.\n",
+		link, deletionConfirmationTTL)
+	if err := uc.mailer.Send(mailer.Message{To: user.Email, Subject: "Confirm the deletion of your account", Body: body}); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+// ConfirmDeletion schedules the deletion the user confirmed with the token
+// from RequestDeletion.
+func (uc *accountUsecase) ConfirmDeletion(request *dto.TokenRequest) (*dto.AccountDeletionResponse, error) {
+	claims, err := helper.ParseActionToken(request.Token, helper.PurposeDeleteAccount)
+	if err != nil {
+		return nil, &errorHandler.BadRequestError{Message: err.Error()}
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, &errorHandler.BadRequestError{Message: err.Error()}
+	}
+	user, err := uc.repository.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.BadRequestError{Message: helper.ErrInvalidActionToken.Error()}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	// The token is bound to the address it was sent to.
+	if user.Email != claims.Email {
+		return nil, &errorHandler.BadRequestError{Message: helper.ErrInvalidActionToken.Error()}
+	}
+	return uc.scheduleDeletion(user)
+}
+
+// scheduleDeletion schedules the erasure of user's account after the grace
+// period and tells the user by email.
+func (uc *accountUsecase) scheduleDeletion(user *entities.User) (*dto.AccountDeletionResponse, error) {
+	if user.DeletionScheduledAt != nil {
+		return nil, errDeletionScheduled()
+	}
+
+	deletion := &entities.AccountDeletion{
+		UserID:       user.Id,
+		Email:        user.Email,
+		ScheduledFor: time.Now().Add(uc.gracePeriod),
+	}
+	if err := uc.repository.ScheduleDeletion(deletion); err != nil {
+		if errors.Is(err, repositories.ErrDeletionScheduled) {
+			return nil, errDeletionScheduled()
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+
+	body := fmt.Sprintf("Your Wishlist account and all of its data will be erased on %s.\n\n"+
+		"If you change your mind, sign in before then to keep your account.\n",
+		deletion.ScheduledFor.Format(time.RFC1123))
+	if err := uc.mailer.Send(mailer.Message{To: user.Email, Subject: "Your account will be deleted", Body: body}); err != nil {
+		log.Printf("sending deletion notice to user %d failed: %v", user.Id, err)
+	}
+	return toAccountDeletionResponse(deletion), nil
+}
+
+// EraseDueAccounts erases the accounts whose grace period has ended and
+// returns how many were erased. A failure to erase one account does not stop
+// the others.
+func (uc *accountUsecase) EraseDueAccounts() (int64, error) {
+	deletions, err := uc.repository.FindDueDeletions(time.Now())
+	if err != nil {
+		return 0, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	var erased int64
+	for _, deletion := range deletions {
+		if err := uc.repository.EraseAccount(deletion); err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("erasing account %d failed: %v", deletion.UserID, err)
+			}
+			continue
+		}
+		erased++
+	}
+	return erased, nil
+}
+
+func errDeletionScheduled() error {
+	return &errorHandler.BadRequestError{Message: "Account deletion is already scheduled"}
+}
+
+func toAccountDeletionResponse(deletion *entities.AccountDeletion) *dto.AccountDeletionResponse {
+	status := DeletionScheduled
+	switch {
+	case deletion.ErasedAt != nil:
+		status = DeletionErased
+	case deletion.CancelledAt != nil:
+		status = DeletionCancelled
+	}
+	return &dto.AccountDeletionResponse{
+		ID:           deletion.ID,
+		UserID:       deletion.UserID,
+		Email:        deletion.Email,
+		Status:       status,
+		RequestedAt:  deletion.CreatedAt,
+		ScheduledFor: deletion.ScheduledFor,
+		CancelledAt:  deletion.CancelledAt,
+		ErasedAt:     deletion.ErasedAt,
+	}
+}
+
+func toAccountExport(data *entities.AccountData, now time.Time) *dto.AccountExport {
+	export := &dto.AccountExport{
+		ExportedAt:       now,
+		Profile:          toUserResponse(data.User),
+		Lists:            make([]dto.ExportList, len(data.Lists)),
+		Wishlists:        make([]dto.ExportWishlist, len(data.Wishlists)),
+		Tags:             make([]dto.ExportTag, len(data.Tags)),
+		Activity:         []dto.ExportActivity{},
+		AccessTokens:     make([]dto.ExportAccessToken, len(data.PersonalAccessTokens)),
+		LinkedIdentities: make([]dto.ExportLinkedIdentity, len(data.ExternalIdentities)),
+	}
+	for i, list := range data.Lists {
+		export.Lists[i] = dto.ExportList{
+			ID:        list.ID,
+			Name:      list.Name,
+			IsDefault: list.IsDefault,
+			CreatedAt: list.CreatedAt,
+			DeletedAt: deletedAt(list.DeletedAt),
+		}
+	}
+	titles := make(map[uint]string, len(data.Wishlists))
+	for i, wishlist := range data.Wishlists {
+		tags := make([]string, len(wishlist.Tags))
+		for j, tag := range wishlist.Tags {
+			tags[j] = tag.Name
+		}
+		titles[wishlist.ID] = wishlist.Title
+		export.Wishlists[i] = dto.ExportWishlist{
+			ID:         wishlist.ID,
+			ListID:     wishlist.ListID,
+			Title:      wishlist.Title,
+			IsAchieved: wishlist.IsAchieved,
+			AchievedAt: wishlist.AchievedAt,
+			Price:      wishlist.PriceAmount,
+			Currency:   wishlist.Currency,
+			URL:        wishlist.URL,
+			Priority:   wishlist.Priority,
+			Quantity:   wishlist.Quantity,
+			Notes:      wishlist.Notes,
+			TargetDate: wishlist.TargetDate,
+			Tags:       tags,
+			CreatedAt:  wishlist.CreatedAt,
+			UpdatedAt:  wishlist.UpdatedAt,
+			DeletedAt:  deletedAt(wishlist.DeletedAt),
+		}
+	}
+	for i, tag := range data.Tags {
+		export.Tags[i] = dto.ExportTag{ID: tag.ID, Name: tag.Name, CreatedAt: tag.CreatedAt}
+	}
+	for _, session := range data.Sessions {
+		export.Activity = append(export.Activity, dto.ExportActivity{
+			At:     session.CreatedAt,
+			Type:   activitySignedIn,
+			Detail: strings.TrimSpace(session.UserAgent + " from " + session.IP),
+		})
+	}
+	for _, event := range data.AchievementEvents {
+		activity := dto.ExportActivity{At: event.CreatedAt, Type: activityUnachieved, Detail: titles[event.WishlistID]}
+		if event.Achieved {
+			activity.Type = activityAchieved
+		}
+		wishlistID := event.WishlistID
+		activity.WishlistID = &wishlistID
+		export.Activity = append(export.Activity, activity)
+	}
+	sort.SliceStable(export.Activity, func(i, j int) bool {
+		return export.Activity[i].At.Before(export.Activity[j].At)
+	})
+	for i, token := range data.PersonalAccessTokens {
+		export.AccessTokens[i] = dto.ExportAccessToken{
+			Name:       token.Name,
+			Scopes:     strings.Fields(token.Scopes),
+			CreatedAt:  token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+			LastUsedAt: token.LastUsedAt,
+		}
+	}
+	for i, identity := range data.ExternalIdentities {
+		export.LinkedIdentities[i] = dto.ExportLinkedIdentity{
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		}
+	}
+	return export
+}
+
+func deletedAt(deleted gorm.DeletedAt) *time.Time {
+	if !deleted.Valid {
+		return nil
+	}
+	return &deleted.Time
+}
+
+// writeExportArchive zips account.json together with CSV copies of the
+// lists, wishlists and activity.
+func writeExportArchive(export *dto.AccountExport) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	file, err := archive.Create("account.json")
+	if err != nil {
+		return nil, err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return nil, err
+	}
+
+	lists := [][]string{{"id", "name", "is_default", "created_at", "deleted_at"}}
+	for _, list := range export.Lists {
+		lists = append(lists, []string{
+			formatUint(list.ID), list.Name, strconv.FormatBool(list.IsDefault),
+			formatTime(&list.CreatedAt), formatTime(list.DeletedAt),
+		})
+	}
+	wishlists := [][]string{{
+		"id", "list_id", "title", "is_achieved", "achieved_at", "price", "currency", "url", "priority",
+		"quantity", "notes", "target_date", "tags", "created_at", "updated_at", "deleted_at",
+	}}
+	for _, wishlist := range export.Wishlists {
+		var listID, price string
+		if wishlist.ListID != nil {
+			listID = formatUint(*wishlist.ListID)
+		}
+		if wishlist.Price != nil {
+			price = strconv.FormatInt(*wishlist.Price, 10)
+		}
+		wishlists = append(wishlists, []string{
+			formatUint(wishlist.ID), listID, wishlist.Title, strconv.FormatBool(wishlist.IsAchieved),
+			formatTime(wishlist.AchievedAt), price, wishlist.Currency, wishlist.URL, wishlist.Priority,
+			strconv.Itoa(wishlist.Quantity), wishlist.Notes, formatTime(wishlist.TargetDate),
+			strings.Join(wishlist.Tags, ";"), formatTime(&wishlist.CreatedAt), formatTime(&wishlist.UpdatedAt),
+			formatTime(wishlist.DeletedAt),
+		})
+	}
+	activity := [][]string{{"at", "type", "wishlist_id", "detail"}}
+	for _, event := range export.Activity {
+		var wishlistID string
+		if event.WishlistID != nil {
+			wishlistID = formatUint(*event.WishlistID)
+		}
+		activity = append(activity, []string{formatTime(&event.At), event.Type, wishlistID, event.Detail})
+	}
+
+	for _, table := range []struct {
+		name string
+		rows [][]string
+	}{
+		{"lists.csv", lists},
+		{"wishlists.csv", wishlists},
+		{"activity.csv", activity},
+	} {
+		file, err := archive.Create(table.name)
+		if err != nil {
+			return nil, err
+		}
+		writer := csv.NewWriter(file)
+		for _, row := range table.rows {
+			for i := range row {
+				row[i] = escapeCSVFormula(row[i])
+			}
+		}
+		if err := writer.WriteAll(table.rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// escapeCSVFormula keeps spreadsheets from evaluating user supplied text
+// such as wishlist titles as formulas.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatUint(value uint) string {
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,238 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/drivers/mysql/mocks"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/mailer"
+	"go-wishlist-api-2/repositories"
+	"gorm.io/gorm"
+	"io"
+	"testing"
+	"time"
+)
+
+func readArchive(t *testing.T, archive []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.NoError(t, err)
+	files := map[string]string{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		files[file.Name] = string(content)
+	}
+	return files
+}
+
+func TestAccountUsecase_Export(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		uc := NewAccountUsecase(mockRepo, mailer.NewOutbox(""), 0, "")
+		listID := uint(2)
+		created := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+		mockRepo.On("FindAccountData", 1).Return(&entities.AccountData{
+			User:  &entities.User{Id: 1, Email: "user@example.com", Password: "secret-hash"},
+			Lists: []*entities.List{{ID: 2, Name: "Birthday", CreatedAt: created}},
+			Wishlists: []*entities.Wishlist{
+				{ID: 5, ListID: &listID, Title: "=HYPERLINK(\"evil\")", Tags: []entities.Tag{{Name: "books"}}, CreatedAt: created},
+				{ID: 6, ListID: &listID, Title: "Old", DeletedAt: gorm.DeletedAt{Time: created, Valid: true}},
+			},
+			AchievementEvents:    []*entities.AchievementEvent{{WishlistID: 5, Achieved: true, CreatedAt: created.Add(time.Hour)}},
+			Sessions:             []*entities.Session{{UserAgent: "curl/8", IP: "10.0.0.1", CreatedAt: created}},
+			PersonalAccessTokens: []*entities.PersonalAccessToken{{Name: "ci", TokenHash: "token-hash", Scopes: "wishlists:read"}},
+		}, nil)
+
+		archive, err := uc.Export(1)
+
+		assert.NoError(t, err)
+		files := readArchive(t, archive)
+		assert.ElementsMatch(t, []string{"account.json", "lists.csv", "wishlists.csv", "activity.csv"}, keys(files))
+		assert.NotContains(t, files["account.json"], "secret-hash")
+		assert.NotContains(t, files["account.json"], "token-hash")
+
+		var export dto.AccountExport
+		assert.NoError(t, json.Unmarshal([]byte(files["account.json"]), &export))
+		assert.Equal(t, "user@example.com", export.Profile.Email)
+		assert.Len(t, export.Wishlists, 2)
+		assert.Equal(t, []string{"books"}, export.Wishlists[0].Tags)
+		assert.NotNil(t, export.Wishlists[1].DeletedAt)
+		assert.Equal(t, []string{"wishlists:read"}, export.AccessTokens[0].Scopes)
+		if assert.Len(t, export.Activity, 2) {
+			assert.Equal(t, "signed_in", export.Activity[0].Type)
+			assert.Equal(t, "achieved", export.Activity[1].Type)
+		}
+
+		assert.Contains(t, files["wishlists.csv"], `5,2,"'=HYPERLINK(""evil"")"`)
+		assert.Contains(t, files["activity.csv"], "2024-03-01T11:00:00Z,achieved,5,")
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		uc := NewAccountUsecase(mockRepo, mailer.NewOutbox(""), 0, "")
+		mockRepo.On("FindAccountData", 1).Return(nil, gorm.ErrRecordNotFound)
+		_, err := uc.Export(1)
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+	})
+}
+
+func keys(m map[string]string) []string {
+	var result []string
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
+
+func TestAccountUsecase_Delete(t *testing.T) {
+	const (
+		testEmail    = "user@example.com"
+		testPassword = "admin123"
+	)
+	password, _ := helper.HashPassword(testPassword)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAccountUsecase(mockRepo, outbox, 48*time.Hour, "")
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: testEmail, Password: password}, nil)
+		mockRepo.On("ScheduleDeletion", mock.MatchedBy(func(deletion *entities.AccountDeletion) bool {
+			return deletion.UserID == 1 && deletion.Email == testEmail &&
+				time.Until(deletion.ScheduledFor) > 47*time.Hour
+		})).Return(nil)
+
+		deletion, err := uc.Delete(1, &dto.PasswordRequest{Password: testPassword})
+
+		assert.NoError(t, err)
+		assert.Equal(t, DeletionScheduled, deletion.Status)
+		_, sent := outbox.Last(testEmail)
+		assert.True(t, sent)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		uc := NewAccountUsecase(mockRepo, mailer.NewOutbox(""), 0, "")
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Password: password}, nil)
+
+		_, err := uc.Delete(1, &dto.PasswordRequest{Password: "wrong"})
+
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "ScheduleDeletion", mock.Anything)
+	})
+
+	t.Run("Already scheduled", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		uc := NewAccountUsecase(mockRepo, mailer.NewOutbox(""), 0, "")
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Password: password}, nil)
+		mockRepo.On("ScheduleDeletion", mock.Anything).Return(repositories.ErrDeletionScheduled)
+
+		_, err := uc.Delete(1, &dto.PasswordRequest{Password: testPassword})
+
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
+
+func TestAccountUsecase_RequestDeletion(t *testing.T) {
+	const testEmail = "user@example.com"
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAccountUsecase(mockRepo, outbox, 0, "https://wishlist.example/")
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: testEmail}, nil)
+
+		err := uc.RequestDeletion(1)
+
+		assert.NoError(t, err)
+		message, sent := outbox.Last(testEmail)
+		assert.True(t, sent)
+		assert.Contains(t, message.Body, "https://wishlist.example/account/delete?token=")
+		mockRepo.AssertNotCalled(t, "ScheduleDeletion", mock.Anything)
+	})
+
+	t.Run("Already scheduled", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAccountUsecase(mockRepo, outbox, 0, "")
+		scheduled := time.Now()
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: testEmail, DeletionScheduledAt: &scheduled}, nil)
+
+		err := uc.RequestDeletion(1)
+
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		_, sent := outbox.Last(testEmail)
+		assert.False(t, sent)
+	})
+}
+
+func TestAccountUsecase_ConfirmDeletion(t *testing.T) {
+	const testEmail = "user@example.com"
+	user := &entities.User{Id: 1, Email: testEmail}
+	token, _ := helper.GenerateActionToken(user, helper.PurposeDeleteAccount, time.Hour)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		uc := NewAccountUsecase(mockRepo, mailer.NewOutbox(""), 0, "")
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: testEmail}, nil)
+		mockRepo.On("ScheduleDeletion", mock.MatchedBy(func(deletion *entities.AccountDeletion) bool {
+			return deletion.UserID == 1
+		})).Return(nil)
+
+		deletion, err := uc.ConfirmDeletion(&dto.TokenRequest{Token: token})
+
+		assert.NoError(t, err)
+		assert.Equal(t, DeletionScheduled, deletion.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Token for another purpose", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		uc := NewAccountUsecase(mockRepo, mailer.NewOutbox(""), 0, "")
+		other, _ := helper.GenerateActionToken(user, helper.PurposeVerifyEmail, time.Hour)
+
+		_, err := uc.ConfirmDeletion(&dto.TokenRequest{Token: other})
+
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "ScheduleDeletion", mock.Anything)
+	})
+
+	t.Run("Email changed since", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		uc := NewAccountUsecase(mockRepo, mailer.NewOutbox(""), 0, "")
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "new@example.com"}, nil)
+
+		_, err := uc.ConfirmDeletion(&dto.TokenRequest{Token: token})
+
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "ScheduleDeletion", mock.Anything)
+	})
+}
+
+func TestAccountUsecase_EraseDueAccounts(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	uc := NewAccountUsecase(mockRepo, mailer.NewOutbox(""), 0, "")
+	erased := &entities.AccountDeletion{ID: 1, UserID: 1}
+	cancelled := &entities.AccountDeletion{ID: 2, UserID: 2}
+	failed := &entities.AccountDeletion{ID: 3, UserID: 3}
+	mockRepo.On("FindDueDeletions", mock.Anything).Return([]*entities.AccountDeletion{erased, cancelled, failed}, nil)
+	mockRepo.On("EraseAccount", erased).Return(nil)
+	mockRepo.On("EraseAccount", cancelled).Return(gorm.ErrRecordNotFound)
+	mockRepo.On("EraseAccount", failed).Return(errors.New("db down"))
+
+	count, err := uc.EraseDueAccounts()
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	mockRepo.AssertExpectations(t)
+}
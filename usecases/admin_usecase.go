@@ -0,0 +1,212 @@
+package usecases
+
+import (
+	"errors"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/rbac"
+	"go-wishlist-api-2/repositories"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AdminUsecase interface {
+	ListUsers(query *dto.UserFilter) ([]*dto.AdminUserResponse, *dto.PageMeta, error)
+	GetUser(id int) (*dto.AdminUserResponse, error)
+	DisableUser(actorID int, id int) (*dto.AdminUserResponse, error)
+	EnableUser(id int) (*dto.AdminUserResponse, error)
+	UnlockUser(id int) error
+	SetRole(actorID int, id int, request *dto.RoleRequest) (*dto.AdminUserResponse, error)
+	GetWishlist(id uint) (*entities.Wishlist, error)
+	ListDeletions(query *dto.DeletionFilter) ([]*dto.AccountDeletionResponse, *dto.PageMeta, error)
+}
+
+// AccountUnlocker lifts login lockouts; it is implemented by AuthUsecase,
+// which owns the lockout state.
+type AccountUnlocker interface {
+	UnlockAccount(email string) error
+}
+
+type adminUsecase struct {
+	repository         repositories.UserRepository
+	tokenRepository    repositories.TokenRepository
+	wishlistRepository repositories.WishlistRepository
+	unlocker           AccountUnlocker
+}
+
+func NewAdminUsecase(r repositories.UserRepository, tr repositories.TokenRepository, wr repositories.WishlistRepository, unlocker AccountUnlocker) *adminUsecase {
+	return &adminUsecase{r, tr, wr, unlocker}
+}
+
+func (uc *adminUsecase) ListUsers(query *dto.UserFilter) ([]*dto.AdminUserResponse, *dto.PageMeta, error) {
+	if query.Limit < 0 || query.Offset < 0 {
+		return nil, nil, &errorHandler.BadRequestError{Message: "limit and offset must not be negative"}
+	}
+	if query.Role != "" && !rbac.ValidRole(query.Role) {
+		return nil, nil, &errorHandler.BadRequestError{Message: "Unknown role"}
+	}
+	filter := &dto.UserFilter{
+		Email:  strings.TrimSpace(query.Email),
+		Role:   query.Role,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+	if filter.Limit > maxPageLimit {
+		filter.Limit = maxPageLimit
+	}
+
+	users, total, err := uc.repository.FindUsers(filter)
+	if err != nil {
+		return nil, nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	responses := make([]*dto.AdminUserResponse, len(users))
+	for i, user := range users {
+		responses[i] = toAdminUserResponse(user)
+	}
+	return responses, &dto.PageMeta{Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+func (uc *adminUsecase) GetUser(id int) (*dto.AdminUserResponse, error) {
+	user, err := uc.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	return toAdminUserResponse(user), nil
+}
+
+// DisableUser blocks the user from signing in and ends all of their sessions.
+func (uc *adminUsecase) DisableUser(actorID int, id int) (*dto.AdminUserResponse, error) {
+	if actorID == id {
+		return nil, &errorHandler.BadRequestError{Message: "You cannot disable your own account"}
+	}
+	user, err := uc.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt == nil {
+		now := time.Now()
+		if err := uc.repository.SetDisabled(id, &now); err != nil {
+			return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		user.DisabledAt = &now
+	}
+	if err := uc.tokenRepository.RevokeAllForUser(id); err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return toAdminUserResponse(user), nil
+}
+
+func (uc *adminUsecase) EnableUser(id int) (*dto.AdminUserResponse, error) {
+	user, err := uc.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		if err := uc.repository.SetDisabled(id, nil); err != nil {
+			return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		user.DisabledAt = nil
+	}
+	return toAdminUserResponse(user), nil
+}
+
+// UnlockUser lifts the login lockout of the user after too many failed
+// attempts.
+func (uc *adminUsecase) UnlockUser(id int) error {
+	user, err := uc.findUser(id)
+	if err != nil {
+		return err
+	}
+	return uc.unlocker.UnlockAccount(user.Email)
+}
+
+// SetRole changes the role of the user. The user's sessions are ended so that
+// no token still carries the old role.
+func (uc *adminUsecase) SetRole(actorID int, id int, request *dto.RoleRequest) (*dto.AdminUserResponse, error) {
+	if !rbac.ValidRole(request.Role) {
+		return nil, &errorHandler.BadRequestError{Message: "Unknown role"}
+	}
+	if actorID == id {
+		return nil, &errorHandler.BadRequestError{Message: "You cannot change your own role"}
+	}
+	user, err := uc.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == request.Role {
+		return toAdminUserResponse(user), nil
+	}
+	if err := uc.repository.UpdateRole(id, request.Role); err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	user.Role = request.Role
+	if err := uc.tokenRepository.RevokeAllForUser(id); err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return toAdminUserResponse(user), nil
+}
+
+// GetWishlist returns any user's wishlist, regardless of its owner.
+func (uc *adminUsecase) GetWishlist(id uint) (*entities.Wishlist, error) {
+	wishlist, err := uc.wishlistRepository.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.NotFoundError{Message: "Wishlist not found"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return wishlist, nil
+}
+
+// ListDeletions returns the audit records of requested account deletions,
+// newest first.
+func (uc *adminUsecase) ListDeletions(query *dto.DeletionFilter) ([]*dto.AccountDeletionResponse, *dto.PageMeta, error) {
+	if query.Limit < 0 || query.Offset < 0 {
+		return nil, nil, &errorHandler.BadRequestError{Message: "limit and offset must not be negative"}
+	}
+	filter := *query
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+	if filter.Limit > maxPageLimit {
+		filter.Limit = maxPageLimit
+	}
+
+	deletions, total, err := uc.repository.FindDeletions(&filter)
+	if err != nil {
+		return nil, nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	responses := make([]*dto.AccountDeletionResponse, len(deletions))
+	for i, deletion := range deletions {
+		responses[i] = toAccountDeletionResponse(deletion)
+	}
+	return responses, &dto.PageMeta{Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+func (uc *adminUsecase) findUser(id int) (*entities.User, error) {
+	user, err := uc.repository.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.NotFoundError{Message: "User not found"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return user, nil
+}
+
+func toAdminUserResponse(user *entities.User) *dto.AdminUserResponse {
+	return &dto.AdminUserResponse{
+		ID:              user.Id,
+		Email:           user.Email,
+		Role:            user.Role,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		DisabledAt:      user.DisabledAt,
+		CreatedAt:       user.CreatedAt,
+	}
+}
@@ -0,0 +1,171 @@
+package usecases
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/drivers/mysql/mocks"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+type mockAccountUnlocker struct {
+	mock.Mock
+}
+
+func (m *mockAccountUnlocker) UnlockAccount(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+type adminTest struct {
+	users     *mocks.MockUserRepository
+	tokens    *mocks.MockTokenRepository
+	wishlists *mocks.MockWishlistRepository
+	unlocker  *mockAccountUnlocker
+	uc        *adminUsecase
+}
+
+func newAdminTest() *adminTest {
+	test := &adminTest{
+		users:     new(mocks.MockUserRepository),
+		tokens:    new(mocks.MockTokenRepository),
+		wishlists: new(mocks.MockWishlistRepository),
+		unlocker:  new(mockAccountUnlocker),
+	}
+	test.uc = NewAdminUsecase(test.users, test.tokens, test.wishlists, test.unlocker)
+	return test
+}
+
+func TestAdminUsecase_ListUsers(t *testing.T) {
+	t.Run("Applies default limit", func(t *testing.T) {
+		test := newAdminTest()
+		test.users.On("FindUsers", &dto.UserFilter{Email: "bob", Limit: defaultPageLimit}).
+			Return([]*entities.User{{Id: 2, Email: "bob@example.com", Password: "hash", Role: entities.RoleUser}}, int64(1), nil)
+		users, meta, err := test.uc.ListUsers(&dto.UserFilter{Email: " bob "})
+		assert.NoError(t, err)
+		assert.Equal(t, "bob@example.com", users[0].Email)
+		assert.Equal(t, int64(1), meta.Total)
+	})
+
+	t.Run("Unknown role", func(t *testing.T) {
+		_, _, err := newAdminTest().uc.ListUsers(&dto.UserFilter{Role: "root"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
+
+func TestAdminUsecase_DisableUser(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		test := newAdminTest()
+		test.users.On("FindByID", 2).Return(&entities.User{Id: 2, Role: entities.RoleUser}, nil)
+		test.users.On("SetDisabled", 2, mock.AnythingOfType("*time.Time")).Return(nil)
+		test.tokens.On("RevokeAllForUser", 2).Return(nil)
+		user, err := test.uc.DisableUser(1, 2)
+		assert.NoError(t, err)
+		assert.NotNil(t, user.DisabledAt)
+		test.tokens.AssertExpectations(t)
+	})
+
+	t.Run("Own account", func(t *testing.T) {
+		_, err := newAdminTest().uc.DisableUser(1, 1)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		test := newAdminTest()
+		test.users.On("FindByID", 2).Return(nil, gorm.ErrRecordNotFound)
+		_, err := test.uc.DisableUser(1, 2)
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+	})
+}
+
+func TestAdminUsecase_EnableUser(t *testing.T) {
+	test := newAdminTest()
+	disabledAt := time.Now()
+	test.users.On("FindByID", 2).Return(&entities.User{Id: 2, DisabledAt: &disabledAt}, nil)
+	test.users.On("SetDisabled", 2, (*time.Time)(nil)).Return(nil)
+	user, err := test.uc.EnableUser(2)
+	assert.NoError(t, err)
+	assert.Nil(t, user.DisabledAt)
+}
+
+func TestAdminUsecase_UnlockUser(t *testing.T) {
+	test := newAdminTest()
+	test.users.On("FindByID", 2).Return(&entities.User{Id: 2, Email: "bob@example.com"}, nil)
+	test.unlocker.On("UnlockAccount", "bob@example.com").Return(nil)
+	assert.NoError(t, test.uc.UnlockUser(2))
+	test.unlocker.AssertExpectations(t)
+}
+
+func TestAdminUsecase_SetRole(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		test := newAdminTest()
+		test.users.On("FindByID", 2).Return(&entities.User{Id: 2, Role: entities.RoleUser}, nil)
+		test.users.On("UpdateRole", 2, entities.RoleModerator).Return(nil)
+		test.tokens.On("RevokeAllForUser", 2).Return(nil)
+		user, err := test.uc.SetRole(1, 2, &dto.RoleRequest{Role: entities.RoleModerator})
+		assert.NoError(t, err)
+		assert.Equal(t, entities.RoleModerator, user.Role)
+		test.tokens.AssertExpectations(t)
+	})
+
+	t.Run("Unchanged role", func(t *testing.T) {
+		test := newAdminTest()
+		test.users.On("FindByID", 2).Return(&entities.User{Id: 2, Role: entities.RoleAdmin}, nil)
+		_, err := test.uc.SetRole(1, 2, &dto.RoleRequest{Role: entities.RoleAdmin})
+		assert.NoError(t, err)
+		test.users.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown role", func(t *testing.T) {
+		_, err := newAdminTest().uc.SetRole(1, 2, &dto.RoleRequest{Role: "root"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Own role", func(t *testing.T) {
+		_, err := newAdminTest().uc.SetRole(1, 1, &dto.RoleRequest{Role: entities.RoleUser})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
+
+func TestAdminUsecase_GetWishlist(t *testing.T) {
+	t.Run("Any owner", func(t *testing.T) {
+		test := newAdminTest()
+		test.wishlists.On("FindByID", uint(5)).Return(&entities.Wishlist{ID: 5, UserID: 9}, nil)
+		wishlist, err := test.uc.GetWishlist(5)
+		assert.NoError(t, err)
+		assert.Equal(t, 9, wishlist.UserID)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		test := newAdminTest()
+		test.wishlists.On("FindByID", uint(5)).Return(nil, gorm.ErrRecordNotFound)
+		_, err := test.uc.GetWishlist(5)
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+	})
+}
+
+func TestAdminUsecase_ListDeletions(t *testing.T) {
+	t.Run("Reports status", func(t *testing.T) {
+		test := newAdminTest()
+		erasedAt := time.Now()
+		test.users.On("FindDeletions", &dto.DeletionFilter{UserID: 3, Limit: maxPageLimit}).
+			Return([]*entities.AccountDeletion{
+				{ID: 2, UserID: 3, ErasedAt: &erasedAt},
+				{ID: 1, UserID: 3, Email: "user@example.com"},
+			}, int64(2), nil)
+		deletions, meta, err := test.uc.ListDeletions(&dto.DeletionFilter{UserID: 3, Limit: 500})
+		assert.NoError(t, err)
+		assert.Equal(t, DeletionErased, deletions[0].Status)
+		assert.Equal(t, DeletionScheduled, deletions[1].Status)
+		assert.Equal(t, int64(2), meta.Total)
+	})
+
+	t.Run("Negative offset", func(t *testing.T) {
+		_, _, err := newAdminTest().uc.ListDeletions(&dto.DeletionFilter{Offset: -1})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
@@ -0,0 +1,111 @@
+package usecases
+
+import (
+	"errors"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/repositories"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const maxListNameLength = 100
+
+type ListUsecase interface {
+	GetAll(userID int) ([]*entities.List, error)
+	GetByID(userID int, id uint) (*entities.List, error)
+	Create(userID int, list *dto.ListRequest) (*entities.List, error)
+	Update(userID int, id uint, list *dto.ListRequest) (*entities.List, error)
+	Delete(userID int, id uint) error
+}
+
+type listUsecase struct {
+	repository repositories.ListRepository
+}
+
+func NewListUsecase(r repositories.ListRepository) *listUsecase {
+	return &listUsecase{r}
+}
+
+func (uc *listUsecase) GetAll(userID int) ([]*entities.List, error) {
+	lists, err := uc.repository.GetAll(userID)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return lists, nil
+}
+
+func (uc *listUsecase) GetByID(userID int, id uint) (*entities.List, error) {
+	return findOwnedList(uc.repository, userID, id)
+}
+
+func (uc *listUsecase) Create(userID int, req *dto.ListRequest) (*entities.List, error) {
+	name, err := validateListName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	list, err := uc.repository.CreateList(&entities.List{UserID: userID, Name: name})
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return list, nil
+}
+
+func (uc *listUsecase) Update(userID int, id uint, req *dto.ListRequest) (*entities.List, error) {
+	name, err := validateListName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	list, err := findOwnedList(uc.repository, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	list.Name = name
+	updated, err := uc.repository.UpdateList(list)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return updated, nil
+}
+
+func (uc *listUsecase) Delete(userID int, id uint) error {
+	list, err := findOwnedList(uc.repository, userID, id)
+	if err != nil {
+		return err
+	}
+	if list.IsDefault {
+		return &errorHandler.BadRequestError{Message: "The default list cannot be deleted"}
+	}
+	if err := uc.repository.DeleteList(id); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+func validateListName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", &errorHandler.BadRequestError{Message: "List name must be filled"}
+	}
+	if len(name) > maxListNameLength {
+		return "", &errorHandler.BadRequestError{Message: "List name is too long"}
+	}
+	return name, nil
+}
+
+// findOwnedList loads a list and makes sure it belongs to userID.
+func findOwnedList(repository repositories.ListRepository, userID int, id uint) (*entities.List, error) {
+	list, err := repository.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.NotFoundError{Message: "List not found"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if list.UserID != userID {
+		return nil, &errorHandler.ForbiddenError{Message: "You don't have access to this list"}
+	}
+	return list, nil
+}
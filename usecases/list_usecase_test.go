@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/drivers/mysql/mocks"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+)
+
+func TestListUsecase_GetAll(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockListRepository)
+		uc := NewListUsecase(mockRepo)
+		mockRepo.On("GetAll", 1).Return([]*entities.List{{ID: 1, UserID: 1, Name: "Birthday"}}, nil)
+		lists, err := uc.GetAll(1)
+		assert.NoError(t, err)
+		assert.Len(t, lists, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRepo := new(mocks.MockListRepository)
+		uc := NewListUsecase(mockRepo)
+		mockRepo.On("GetAll", 1).Return(nil, errors.New("Failed to get lists"))
+		lists, err := uc.GetAll(1)
+		assert.Nil(t, lists)
+		assert.IsType(t, &errorHandler.InternalServerError{}, err)
+	})
+}
+
+func TestListUsecase_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockListRepository)
+		uc := NewListUsecase(mockRepo)
+		mockRepo.On("CreateList", mock.MatchedBy(func(l *entities.List) bool {
+			return l.UserID == 1 && l.Name == "Travel" && !l.IsDefault
+		})).Return(&entities.List{ID: 2, UserID: 1, Name: "Travel"}, nil)
+		list, err := uc.Create(1, &dto.ListRequest{Name: "  Travel "})
+		assert.NoError(t, err)
+		assert.Equal(t, "Travel", list.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Empty name", func(t *testing.T) {
+		mockRepo := new(mocks.MockListRepository)
+		uc := NewListUsecase(mockRepo)
+		list, err := uc.Create(1, &dto.ListRequest{Name: " "})
+		assert.Nil(t, list)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Name too long", func(t *testing.T) {
+		mockRepo := new(mocks.MockListRepository)
+		uc := NewListUsecase(mockRepo)
+		list, err := uc.Create(1, &dto.ListRequest{Name: strings.Repeat("a", maxListNameLength+1)})
+		assert.Nil(t, list)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
+
+func TestListUsecase_Update(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockListRepository)
+		uc := NewListUsecase(mockRepo)
+		mockRepo.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 1, Name: "Travel"}, nil)
+		mockRepo.On("UpdateList", mock.MatchedBy(func(l *entities.List) bool {
+			return l.Name == "Holiday"
+		})).Return(&entities.List{ID: 2, UserID: 1, Name: "Holiday"}, nil)
+		list, err := uc.Update(1, 2, &dto.ListRequest{Name: "Holiday"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Holiday", list.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockRepo := new(mocks.MockListRepository)
+		uc := NewListUsecase(mockRepo)
+		mockRepo.On("FindByID", uint(2)).Return(nil, gorm.ErrRecordNotFound)
+		list, err := uc.Update(1, 2, &dto.ListRequest{Name: "Holiday"})
+		assert.Nil(t, list)
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+	})
+}
+
+func TestListUsecase_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockListRepository)
+		uc := NewListUsecase(mockRepo)
+		mockRepo.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 1}, nil)
+		mockRepo.On("DeleteList", uint(2)).Return(nil)
+		err := uc.Delete(1, 2)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Default list", func(t *testing.T) {
+		mockRepo := new(mocks.MockListRepository)
+		uc := NewListUsecase(mockRepo)
+		mockRepo.On("FindByID", uint(1)).Return(&entities.List{ID: 1, UserID: 1, IsDefault: true}, nil)
+		err := uc.Delete(1, 1)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "DeleteList", mock.Anything)
+	})
+
+	t.Run("Owned by another user", func(t *testing.T) {
+		mockRepo := new(mocks.MockListRepository)
+		uc := NewListUsecase(mockRepo)
+		mockRepo.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 2}, nil)
+		err := uc.Delete(1, 2)
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		mockRepo.AssertNotCalled(t, "DeleteList", mock.Anything)
+	})
+}
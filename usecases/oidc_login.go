@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/oidc"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BeginOIDCLogin starts signing in with an OpenID Connect provider. The user
+// is to be sent to the returned URL; the returned state comes back with the
+// callback and is handed to CompleteOIDCLogin.
+func (uc *authUsecase) BeginOIDCLogin(name string) (*dto.OIDCLoginRedirect, error) {
+	provider, err := uc.oidcProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	request, err := oidc.NewAuthRequest()
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	authURL, err := provider.AuthCodeURL(context.Background(), request)
+	if err != nil {
+		log.Printf("starting login with %s failed: %v", name, err)
+		return nil, &errorHandler.InternalServerError{Message: "Identity provider is unavailable"}
+	}
+	err = uc.repository.CreateOIDCLoginState(&entities.OIDCLoginState{
+		StateHash:    helper.HashToken(request.State),
+		Provider:     name,
+		Nonce:        request.Nonce,
+		CodeVerifier: request.CodeVerifier,
+		ExpiresAt:    time.Now().Add(uc.options.OIDCLoginTTL),
+	})
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return &dto.OIDCLoginRedirect{URL: authURL, State: request.State}, nil
+}
+
+// CompleteOIDCLogin finishes a sign-in started with BeginOIDCLogin. It
+// exchanges the authorization code, validates the ID token and signs in the
+// user linked to the identity, creating the account on first login.
+func (uc *authUsecase) CompleteOIDCLogin(name, state, code string, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	provider, err := uc.oidcProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	if state == "" || code == "" {
+		return nil, &errorHandler.BadRequestError{Message: "state and code are required"}
+	}
+	invalidState := &errorHandler.BadRequestError{Message: "Invalid or expired login state"}
+	loginState, err := uc.repository.ConsumeOIDCLoginState(helper.HashToken(state))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidState
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if loginState.Provider != name || time.Now().After(loginState.ExpiresAt) {
+		return nil, invalidState
+	}
+
+	failed := &errorHandler.UnAuthorizedError{Message: "Login Failed: sign in with " + name + " failed"}
+	ctx := context.Background()
+	rawIDToken, err := provider.Exchange(ctx, code, loginState.CodeVerifier)
+	if err != nil {
+		log.Printf("login with %s failed: %v", name, err)
+		return nil, failed
+	}
+	identity, err := provider.Verify(ctx, rawIDToken, loginState.Nonce)
+	if err != nil {
+		log.Printf("login with %s failed: %v", name, err)
+		return nil, failed
+	}
+
+	user, err := uc.oidcUser(name, identity)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := uc.totpEnabled(user.Id)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return uc.mfaChallenge(user)
+	}
+	return uc.login(user, client)
+}
+
+// oidcUser returns the user linked to identity. An identity seen for the first
+// time is linked to the account with the same email address, or a new account
+// is created for it. Either way the provider must have verified the address.
+func (uc *authUsecase) oidcUser(provider string, identity *oidc.Identity) (*entities.User, error) {
+	linked, err := uc.repository.FindExternalIdentity(provider, identity.Subject)
+	if err == nil {
+		user, err := uc.repository.FindByID(linked.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, &errorHandler.UnAuthorizedError{Message: "User no longer exists"}
+			}
+			return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+
+	if identity.Email == "" || !identity.EmailVerified || !validEmail(identity.Email) {
+		return nil, &errorHandler.ForbiddenError{Message: "Login Failed: " + provider + " did not confirm your email address"}
+	}
+	link := &entities.ExternalIdentity{Provider: provider, Subject: identity.Subject, Email: identity.Email}
+
+	user, err := uc.repository.FindByEmail(identity.Email)
+	if err == nil {
+		// Anyone can register an address they do not own; linking such an
+		// account would let them sign in to it once it is verified.
+		if user.EmailVerifiedAt == nil {
+			return nil, &errorHandler.ForbiddenError{Message: "Login Failed: verify the email address of your existing account first"}
+		}
+		link.UserID = user.Id
+		if err := uc.repository.CreateExternalIdentity(link); err != nil {
+			return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		uc.notify(user, "New sign-in method",
+			fmt.Sprintf("Your %s account was linked to your wishlist account and can now be used to sign in.\n\nIf this wasn't you, reset your password and contact support.", provider))
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+
+	// Accounts created this way get a random password nobody knows; one can
+	// be set through the password reset flow.
+	secret, err := helper.GenerateResetToken()
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	hash, err := helper.HashPassword(secret)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	verifiedAt := time.Now()
+	user, err = uc.repository.CreateUserWithIdentity(&entities.User{
+		Email:           identity.Email,
+		Password:        hash,
+		EmailVerifiedAt: &verifiedAt,
+		Role:            entities.RoleUser,
+	}, link)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return user, nil
+}
+
+func (uc *authUsecase) oidcProvider(name string) (*oidc.Provider, error) {
+	provider, ok := uc.options.OIDCProviders[name]
+	if !ok {
+		return nil, &errorHandler.NotFoundError{Message: "Unknown identity provider"}
+	}
+	return provider, nil
+}
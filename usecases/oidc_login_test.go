@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/drivers/mysql/mocks"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/mailer"
+	"go-wishlist-api-2/oidc"
+	"go-wishlist-api-2/oidc/oidctest"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type oidcTest struct {
+	uc        *authUsecase
+	repo      *mocks.MockAuthRepository
+	tokenRepo *mocks.MockTokenRepository
+	outbox    *mailer.Outbox
+	fake      *oidctest.Provider
+}
+
+func newOIDCTest(t *testing.T) *oidcTest {
+	fake := oidctest.NewProvider("client", "secret")
+	t.Cleanup(fake.Close)
+	provider := oidc.NewProvider("test", oidc.Config{
+		Issuer:       fake.Issuer(),
+		ClientID:     fake.ClientID,
+		ClientSecret: fake.ClientSecret,
+		RedirectURL:  "http://localhost:1323/auth/oidc/test/callback",
+	}, nil)
+	repo := new(mocks.MockAuthRepository)
+	tokenRepo := new(mocks.MockTokenRepository)
+	outbox := mailer.NewOutbox("")
+	uc := NewAuthUsecase(repo, tokenRepo, outbox, AuthOptions{
+		OIDCProviders: map[string]*oidc.Provider{"test": provider},
+	})
+	return &oidcTest{uc, repo, tokenRepo, outbox, fake}
+}
+
+// signIn starts a login, lets the fake provider approve it and returns the
+// state and code of the callback.
+func (o *oidcTest) signIn(t *testing.T) (string, string) {
+	var saved *entities.OIDCLoginState
+	o.repo.On("CreateOIDCLoginState", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*entities.OIDCLoginState)
+	}).Return(nil).Once()
+	redirect, err := o.uc.BeginOIDCLogin("test")
+	assert.NoError(t, err)
+	assert.Equal(t, helper.HashToken(redirect.State), saved.StateHash)
+
+	callback, err := o.fake.Authorize(redirect.URL)
+	assert.NoError(t, err)
+	o.repo.On("ConsumeOIDCLoginState", saved.StateHash).Return(saved, nil).Once()
+	return callback.Query().Get("state"), callback.Query().Get("code")
+}
+
+func (o *oidcTest) expectLogin(userID int) {
+	o.repo.On("FindTOTPCredential", userID).Return(nil, gorm.ErrRecordNotFound)
+	o.tokenRepo.On("CreateSession", mock.Anything).Return(&entities.Session{ID: 3}, nil)
+	o.tokenRepo.On("CreateRefreshToken", mock.MatchedBy(func(token *entities.RefreshToken) bool {
+		return token.UserID == userID
+	})).Return(&entities.RefreshToken{ID: 1}, nil)
+}
+
+func TestAuthUsecase_OIDCLogin(t *testing.T) {
+	verifiedAt := time.Now()
+
+	t.Run("First login creates an account", func(t *testing.T) {
+		o := newOIDCTest(t)
+		state, code := o.signIn(t)
+		o.repo.On("FindExternalIdentity", "test", "1234567890").Return(nil, gorm.ErrRecordNotFound)
+		o.repo.On("FindByEmail", "user@example.com").Return(nil, gorm.ErrRecordNotFound)
+		o.repo.On("CreateUserWithIdentity", mock.MatchedBy(func(user *entities.User) bool {
+			return user.Email == "user@example.com" && user.EmailVerifiedAt != nil && user.Password != ""
+		}), &entities.ExternalIdentity{Provider: "test", Subject: "1234567890", Email: "user@example.com"}).
+			Return(&entities.User{Id: 5, Email: "user@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+		o.expectLogin(5)
+
+		response, err := o.uc.CompleteOIDCLogin("test", state, code, dto.ClientInfo{})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		assert.NotEmpty(t, response.RefreshToken)
+		o.repo.AssertExpectations(t)
+		o.tokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Linked identity", func(t *testing.T) {
+		o := newOIDCTest(t)
+		state, code := o.signIn(t)
+		o.repo.On("FindExternalIdentity", "test", "1234567890").Return(&entities.ExternalIdentity{ID: 1, UserID: 5}, nil)
+		o.repo.On("FindByID", 5).Return(&entities.User{Id: 5, Email: "old@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+		o.expectLogin(5)
+
+		response, err := o.uc.CompleteOIDCLogin("test", state, code, dto.ClientInfo{})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		o.repo.AssertNotCalled(t, "FindByEmail", mock.Anything)
+	})
+
+	t.Run("Links an existing verified account", func(t *testing.T) {
+		o := newOIDCTest(t)
+		state, code := o.signIn(t)
+		o.repo.On("FindExternalIdentity", "test", "1234567890").Return(nil, gorm.ErrRecordNotFound)
+		o.repo.On("FindByEmail", "user@example.com").Return(&entities.User{Id: 7, Email: "user@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+		o.repo.On("CreateExternalIdentity", &entities.ExternalIdentity{UserID: 7, Provider: "test", Subject: "1234567890", Email: "user@example.com"}).Return(nil)
+		o.expectLogin(7)
+
+		_, err := o.uc.CompleteOIDCLogin("test", state, code, dto.ClientInfo{})
+		assert.NoError(t, err)
+		message, ok := o.outbox.Last("user@example.com")
+		assert.True(t, ok)
+		assert.Equal(t, "New sign-in method", message.Subject)
+	})
+
+	t.Run("Existing unverified account is not linked", func(t *testing.T) {
+		o := newOIDCTest(t)
+		state, code := o.signIn(t)
+		o.repo.On("FindExternalIdentity", "test", "1234567890").Return(nil, gorm.ErrRecordNotFound)
+		o.repo.On("FindByEmail", "user@example.com").Return(&entities.User{Id: 7, Email: "user@example.com"}, nil)
+
+		_, err := o.uc.CompleteOIDCLogin("test", state, code, dto.ClientInfo{})
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		o.repo.AssertNotCalled(t, "CreateExternalIdentity", mock.Anything)
+	})
+
+	t.Run("Email not verified by the provider", func(t *testing.T) {
+		o := newOIDCTest(t)
+		o.fake.Identity.EmailVerified = false
+		state, code := o.signIn(t)
+		o.repo.On("FindExternalIdentity", "test", "1234567890").Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := o.uc.CompleteOIDCLogin("test", state, code, dto.ClientInfo{})
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		o.repo.AssertNotCalled(t, "FindByEmail", mock.Anything)
+	})
+
+	t.Run("Two-factor authentication", func(t *testing.T) {
+		o := newOIDCTest(t)
+		state, code := o.signIn(t)
+		o.repo.On("FindExternalIdentity", "test", "1234567890").Return(&entities.ExternalIdentity{ID: 1, UserID: 5}, nil)
+		o.repo.On("FindByID", 5).Return(&entities.User{Id: 5, Email: "user@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+		o.repo.On("FindTOTPCredential", 5).Return(&entities.TOTPCredential{UserID: 5, ConfirmedAt: &verifiedAt}, nil)
+
+		response, err := o.uc.CompleteOIDCLogin("test", state, code, dto.ClientInfo{})
+		assert.NoError(t, err)
+		assert.True(t, response.MFARequired)
+		assert.Empty(t, response.Token)
+	})
+
+	t.Run("Tampered ID token", func(t *testing.T) {
+		o := newOIDCTest(t)
+		o.fake.ModifyClaims = func(claims jwt.MapClaims) { claims["aud"] = "other-client" }
+		state, code := o.signIn(t)
+
+		_, err := o.uc.CompleteOIDCLogin("test", state, code, dto.ClientInfo{})
+		assert.IsType(t, &errorHandler.UnAuthorizedError{}, err)
+		o.repo.AssertNotCalled(t, "FindExternalIdentity", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Used or unknown state", func(t *testing.T) {
+		o := newOIDCTest(t)
+		o.repo.On("ConsumeOIDCLoginState", helper.HashToken("state")).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := o.uc.CompleteOIDCLogin("test", "state", "code", dto.ClientInfo{})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Expired state", func(t *testing.T) {
+		o := newOIDCTest(t)
+		o.repo.On("ConsumeOIDCLoginState", helper.HashToken("state")).
+			Return(&entities.OIDCLoginState{Provider: "test", ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+		_, err := o.uc.CompleteOIDCLogin("test", "state", "code", dto.ClientInfo{})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		o := newOIDCTest(t)
+		_, err := o.uc.BeginOIDCLogin("other")
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+	})
+}
@@ -0,0 +1,176 @@
+package usecases
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+var sortableWishlistColumns = map[string]bool{
+	"id":          true,
+	"title":       true,
+	"is_achieved": true,
+	"created_at":  true,
+	"updated_at":  true,
+}
+
+// parseSort turns "-created_at,title" into sort fields, always ending with id
+// so that the order is total and usable as a cursor.
+func parseSort(sort string) ([]dto.SortField, error) {
+	var fields []dto.SortField
+	seen := map[string]bool{}
+	for _, key := range strings.Split(sort, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		field := dto.SortField{Column: key}
+		if strings.HasPrefix(key, "-") {
+			field = dto.SortField{Column: key[1:], Desc: true}
+		}
+		if !sortableWishlistColumns[field.Column] {
+			return nil, &errorHandler.BadRequestError{Message: "Cannot sort by " + field.Column}
+		}
+		if seen[field.Column] {
+			return nil, &errorHandler.BadRequestError{Message: "Duplicate sort key " + field.Column}
+		}
+		seen[field.Column] = true
+		fields = append(fields, field)
+	}
+	if !seen["id"] {
+		fields = append(fields, dto.SortField{Column: "id"})
+	}
+	return fields, nil
+}
+
+func encodeCursor(sort []dto.SortField, wishlist *entities.Wishlist) string {
+	values := make([]any, len(sort))
+	for i, field := range sort {
+		switch field.Column {
+		case "id":
+			values[i] = wishlist.ID
+		case "title":
+			values[i] = wishlist.Title
+		case "is_achieved":
+			values[i] = wishlist.IsAchieved
+		case "created_at":
+			values[i] = wishlist.CreatedAt.Format(time.RFC3339Nano)
+		case "updated_at":
+			values[i] = wishlist.UpdatedAt.Format(time.RFC3339Nano)
+		}
+	}
+	raw, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor reverses encodeCursor. A cursor is only valid for the sort
+// order it was produced with.
+func decodeCursor(sort []dto.SortField, cursor string) ([]any, error) {
+	invalid := &errorHandler.BadRequestError{Message: "Invalid cursor"}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var values []any
+	if err := json.Unmarshal(raw, &values); err != nil || len(values) != len(sort) {
+		return nil, invalid
+	}
+	for i, field := range sort {
+		switch field.Column {
+		case "id":
+			id, ok := values[i].(float64)
+			if !ok {
+				return nil, invalid
+			}
+			values[i] = uint(id)
+		case "title":
+			if _, ok := values[i].(string); !ok {
+				return nil, invalid
+			}
+		case "is_achieved":
+			if _, ok := values[i].(bool); !ok {
+				return nil, invalid
+			}
+		case "created_at", "updated_at":
+			text, ok := values[i].(string)
+			if !ok {
+				return nil, invalid
+			}
+			parsed, err := time.Parse(time.RFC3339Nano, text)
+			if err != nil {
+				return nil, invalid
+			}
+			values[i] = parsed
+		}
+	}
+	return values, nil
+}
+
+func buildWishlistFilter(userID int, query *dto.WishlistQuery) (*dto.WishlistFilter, error) {
+	if query.Limit < 0 || query.Offset < 0 {
+		return nil, &errorHandler.BadRequestError{Message: "limit and offset must not be negative"}
+	}
+	if query.Cursor != "" && query.Offset > 0 {
+		return nil, &errorHandler.BadRequestError{Message: "cursor and offset cannot be combined"}
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	sort, err := parseSort(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeTagNames(query.Tags)
+	if err != nil {
+		return nil, err
+	}
+	var matchAllTags bool
+	switch strings.ToLower(query.TagMode) {
+	case "", "and":
+		matchAllTags = true
+	case "or":
+	default:
+		return nil, &errorHandler.BadRequestError{Message: "tag_mode must be either and or or"}
+	}
+
+	filter := &dto.WishlistFilter{
+		UserID:       userID,
+		ListID:       query.ListID,
+		IsAchieved:   query.IsAchieved,
+		Title:        query.Title,
+		Tags:         tags,
+		MatchAllTags: matchAllTags,
+		CreatedFrom:  query.CreatedFrom,
+		CreatedTo:    query.CreatedTo,
+		UpdatedFrom:  query.UpdatedFrom,
+		UpdatedTo:    query.UpdatedTo,
+		Sort:         sort,
+		Limit:        limit,
+		Offset:       query.Offset,
+	}
+	if query.Cursor != "" {
+		after, err := decodeCursor(sort, query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+	return filter, nil
+}
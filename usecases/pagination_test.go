@@ -0,0 +1,45 @@
+package usecases
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"testing"
+	"time"
+)
+
+func TestParseSort(t *testing.T) {
+	fields, err := parseSort("-created_at, title")
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.SortField{
+		{Column: "created_at", Desc: true},
+		{Column: "title"},
+		{Column: "id"},
+	}, fields)
+
+	fields, err = parseSort("-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.SortField{{Column: "id", Desc: true}}, fields)
+
+	_, err = parseSort("title,title")
+	assert.IsType(t, &errorHandler.BadRequestError{}, err)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	sort := []dto.SortField{{Column: "updated_at", Desc: true}, {Column: "is_achieved"}, {Column: "id"}}
+	updatedAt := time.Date(2024, 5, 1, 10, 30, 0, 123, time.UTC)
+	cursor := encodeCursor(sort, &entities.Wishlist{ID: 42, IsAchieved: true, UpdatedAt: updatedAt})
+
+	values, err := decodeCursor(sort, cursor)
+	assert.NoError(t, err)
+	assert.True(t, updatedAt.Equal(values[0].(time.Time)))
+	assert.Equal(t, true, values[1])
+	assert.Equal(t, uint(42), values[2])
+
+	_, err = decodeCursor([]dto.SortField{{Column: "id"}}, cursor)
+	assert.IsType(t, &errorHandler.BadRequestError{}, err)
+
+	_, err = decodeCursor(sort, "not a cursor")
+	assert.IsType(t, &errorHandler.BadRequestError{}, err)
+}
@@ -0,0 +1,174 @@
+package usecases
+
+import (
+	"errors"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/repositories"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxPersonalAccessTokens       = 50
+	maxPersonalAccessTokenNameLen = 100
+	// lastUsedResolution limits how often LastUsedAt is written so that
+	// busy scripts do not cause a database write per request.
+	lastUsedResolution = time.Minute
+)
+
+// personalAccessTokenScopes are the scopes a token can be granted.
+var personalAccessTokenScopes = map[string]bool{
+	entities.ScopeWishlistsRead:  true,
+	entities.ScopeWishlistsWrite: true,
+}
+
+type PersonalAccessTokenUsecase interface {
+	Create(userID int, request *dto.PersonalAccessTokenRequest) (*dto.PersonalAccessTokenResponse, error)
+	GetAll(userID int) ([]*dto.PersonalAccessTokenResponse, error)
+	Revoke(userID int, id uint) error
+	Authenticate(token string) (*entities.PersonalAccessToken, error)
+}
+
+type personalAccessTokenUsecase struct {
+	repository repositories.PersonalAccessTokenRepository
+}
+
+func NewPersonalAccessTokenUsecase(r repositories.PersonalAccessTokenRepository) *personalAccessTokenUsecase {
+	return &personalAccessTokenUsecase{r}
+}
+
+// Create issues a new token. The plain token is only part of the returned
+// response; afterwards only its hash is known.
+func (uc *personalAccessTokenUsecase) Create(userID int, request *dto.PersonalAccessTokenRequest) (*dto.PersonalAccessTokenResponse, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, &errorHandler.BadRequestError{Message: "Token name must be filled"}
+	}
+	if utf8.RuneCountInString(name) > maxPersonalAccessTokenNameLen {
+		return nil, &errorHandler.BadRequestError{Message: "Token name is too long"}
+	}
+	scopes, err := normalizeScopes(request.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return nil, &errorHandler.BadRequestError{Message: "expires_at must be in the future"}
+	}
+
+	count, err := uc.repository.CountByUser(userID)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if count >= maxPersonalAccessTokens {
+		return nil, &errorHandler.BadRequestError{Message: "Too many access tokens, please revoke unused ones"}
+	}
+
+	plain, err := helper.GeneratePersonalAccessToken()
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	token, err := uc.repository.CreateToken(&entities.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: helper.HashToken(plain),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: request.ExpiresAt,
+	})
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	response := personalAccessTokenResponse(token)
+	response.Token = plain
+	return response, nil
+}
+
+func (uc *personalAccessTokenUsecase) GetAll(userID int) ([]*dto.PersonalAccessTokenResponse, error) {
+	tokens, err := uc.repository.FindByUser(userID)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	responses := make([]*dto.PersonalAccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		responses = append(responses, personalAccessTokenResponse(token))
+	}
+	return responses, nil
+}
+
+func (uc *personalAccessTokenUsecase) Revoke(userID int, id uint) error {
+	if err := uc.repository.DeleteToken(userID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &errorHandler.NotFoundError{Message: "Access token not found"}
+		}
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+// Authenticate returns the stored token matching a plain token from a
+// request and records that it was used.
+func (uc *personalAccessTokenUsecase) Authenticate(plain string) (*entities.PersonalAccessToken, error) {
+	token, err := uc.repository.FindByHash(helper.HashToken(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.UnAuthorizedError{Message: "Invalid access token"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	now := time.Now()
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(now) {
+		return nil, &errorHandler.UnAuthorizedError{Message: "Access token has expired"}
+	}
+	if token.User != nil && token.User.DisabledAt != nil {
+		return nil, &errorHandler.ForbiddenError{Message: "Account is disabled"}
+	}
+	if token.User != nil && token.User.DeletionScheduledAt != nil {
+		return nil, &errorHandler.ForbiddenError{Message: "Account is scheduled for deletion"}
+	}
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		if err := uc.repository.TouchToken(token.ID, now); err != nil {
+			log.Printf("recording use of access token %d failed: %v", token.ID, err)
+		} else {
+			token.LastUsedAt = &now
+		}
+	}
+	return token, nil
+}
+
+// normalizeScopes validates and de-duplicates requested scopes.
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, &errorHandler.BadRequestError{Message: "At least one scope is required"}
+	}
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !personalAccessTokenScopes[scope] {
+			return nil, &errorHandler.BadRequestError{Message: "Unknown scope " + scope}
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		normalized = append(normalized, scope)
+	}
+	return normalized, nil
+}
+
+func personalAccessTokenResponse(token *entities.PersonalAccessToken) *dto.PersonalAccessTokenResponse {
+	return &dto.PersonalAccessTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Scopes:     strings.Fields(token.Scopes),
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
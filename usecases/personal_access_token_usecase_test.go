@@ -0,0 +1,151 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/drivers/mysql/mocks"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestPersonalAccessTokenUsecase_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockPersonalAccessTokenRepository)
+		uc := NewPersonalAccessTokenUsecase(mockRepo)
+		expiresAt := time.Now().Add(24 * time.Hour)
+		mockRepo.On("CountByUser", 1).Return(int64(0), nil)
+		mockRepo.On("CreateToken", mock.MatchedBy(func(token *entities.PersonalAccessToken) bool {
+			return token.UserID == 1 && token.Name == "ci" && token.Scopes == "wishlists:read" && len(token.TokenHash) == 64
+		})).Return(&entities.PersonalAccessToken{ID: 3, UserID: 1, Name: "ci", Scopes: "wishlists:read"}, nil)
+		token, err := uc.Create(1, &dto.PersonalAccessTokenRequest{
+			Name:      " ci ",
+			Scopes:    []string{"wishlists:read", "wishlists:read"},
+			ExpiresAt: &expiresAt,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), token.ID)
+		assert.Equal(t, []string{"wishlists:read"}, token.Scopes)
+		assert.True(t, helper.IsPersonalAccessToken(token.Token))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown scope", func(t *testing.T) {
+		uc := NewPersonalAccessTokenUsecase(new(mocks.MockPersonalAccessTokenRepository))
+		_, err := uc.Create(1, &dto.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{"admin"}})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Missing scopes", func(t *testing.T) {
+		uc := NewPersonalAccessTokenUsecase(new(mocks.MockPersonalAccessTokenRepository))
+		_, err := uc.Create(1, &dto.PersonalAccessTokenRequest{Name: "ci"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Expiry in the past", func(t *testing.T) {
+		uc := NewPersonalAccessTokenUsecase(new(mocks.MockPersonalAccessTokenRepository))
+		expiresAt := time.Now().Add(-time.Minute)
+		_, err := uc.Create(1, &dto.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{"wishlists:write"}, ExpiresAt: &expiresAt})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Too many tokens", func(t *testing.T) {
+		mockRepo := new(mocks.MockPersonalAccessTokenRepository)
+		uc := NewPersonalAccessTokenUsecase(mockRepo)
+		mockRepo.On("CountByUser", 1).Return(int64(maxPersonalAccessTokens), nil)
+		_, err := uc.Create(1, &dto.PersonalAccessTokenRequest{Name: "ci", Scopes: []string{"wishlists:write"}})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
+
+func TestPersonalAccessTokenUsecase_Revoke(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockPersonalAccessTokenRepository)
+		uc := NewPersonalAccessTokenUsecase(mockRepo)
+		mockRepo.On("DeleteToken", 1, uint(3)).Return(nil)
+		assert.NoError(t, uc.Revoke(1, 3))
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockRepo := new(mocks.MockPersonalAccessTokenRepository)
+		uc := NewPersonalAccessTokenUsecase(mockRepo)
+		mockRepo.On("DeleteToken", 1, uint(3)).Return(gorm.ErrRecordNotFound)
+		assert.IsType(t, &errorHandler.NotFoundError{}, uc.Revoke(1, 3))
+	})
+}
+
+func TestPersonalAccessTokenUsecase_Authenticate(t *testing.T) {
+	plain := "wlp_token"
+
+	t.Run("Records first use", func(t *testing.T) {
+		mockRepo := new(mocks.MockPersonalAccessTokenRepository)
+		uc := NewPersonalAccessTokenUsecase(mockRepo)
+		mockRepo.On("FindByHash", helper.HashToken(plain)).Return(&entities.PersonalAccessToken{ID: 3, UserID: 1}, nil)
+		mockRepo.On("TouchToken", uint(3), mock.Anything).Return(nil)
+		token, err := uc.Authenticate(plain)
+		assert.NoError(t, err)
+		assert.NotNil(t, token.LastUsedAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Recently used", func(t *testing.T) {
+		mockRepo := new(mocks.MockPersonalAccessTokenRepository)
+		uc := NewPersonalAccessTokenUsecase(mockRepo)
+		lastUsedAt := time.Now().Add(-time.Second)
+		mockRepo.On("FindByHash", helper.HashToken(plain)).Return(&entities.PersonalAccessToken{ID: 3, UserID: 1, LastUsedAt: &lastUsedAt}, nil)
+		_, err := uc.Authenticate(plain)
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "TouchToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed touch does not fail the request", func(t *testing.T) {
+		mockRepo := new(mocks.MockPersonalAccessTokenRepository)
+		uc := NewPersonalAccessTokenUsecase(mockRepo)
+		mockRepo.On("FindByHash", helper.HashToken(plain)).Return(&entities.PersonalAccessToken{ID: 3, UserID: 1}, nil)
+		mockRepo.On("TouchToken", uint(3), mock.Anything).Return(errors.New("db down"))
+		_, err := uc.Authenticate(plain)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		mockRepo := new(mocks.MockPersonalAccessTokenRepository)
+		uc := NewPersonalAccessTokenUsecase(mockRepo)
+		expiresAt := time.Now().Add(-time.Minute)
+		mockRepo.On("FindByHash", helper.HashToken(plain)).Return(&entities.PersonalAccessToken{ID: 3, UserID: 1, ExpiresAt: &expiresAt}, nil)
+		_, err := uc.Authenticate(plain)
+		assert.IsType(t, &errorHandler.UnAuthorizedError{}, err)
+	})
+
+	t.Run("Disabled account", func(t *testing.T) {
+		mockRepo := new(mocks.MockPersonalAccessTokenRepository)
+		uc := NewPersonalAccessTokenUsecase(mockRepo)
+		disabledAt := time.Now()
+		mockRepo.On("FindByHash", helper.HashToken(plain)).
+			Return(&entities.PersonalAccessToken{ID: 3, UserID: 1, User: &entities.User{Id: 1, DisabledAt: &disabledAt}}, nil)
+		_, err := uc.Authenticate(plain)
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+	})
+
+	t.Run("Account scheduled for deletion", func(t *testing.T) {
+		mockRepo := new(mocks.MockPersonalAccessTokenRepository)
+		uc := NewPersonalAccessTokenUsecase(mockRepo)
+		scheduledFor := time.Now().Add(time.Hour)
+		mockRepo.On("FindByHash", helper.HashToken(plain)).
+			Return(&entities.PersonalAccessToken{ID: 3, UserID: 1, User: &entities.User{Id: 1, DeletionScheduledAt: &scheduledFor}}, nil)
+		_, err := uc.Authenticate(plain)
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		mockRepo := new(mocks.MockPersonalAccessTokenRepository)
+		uc := NewPersonalAccessTokenUsecase(mockRepo)
+		mockRepo.On("FindByHash", helper.HashToken(plain)).Return(nil, gorm.ErrRecordNotFound)
+		_, err := uc.Authenticate(plain)
+		assert.IsType(t, &errorHandler.UnAuthorizedError{}, err)
+	})
+}
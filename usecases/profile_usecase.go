@@ -0,0 +1,172 @@
+package usecases
+
+import (
+	"errors"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/repositories"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
+)
+
+const (
+	maxDisplayNameLength = 100
+	maxLocaleLength      = 35
+	maxTimezoneLength    = 64
+)
+
+// minBirthday is the earliest birthday accepted, to catch typos in the year.
+var minBirthday = time.Date(1900, time.January, 1, 0, 0, 0, 0, time.Local)
+
+type ProfileUsecase interface {
+	Get(userID int) (*dto.UserResponse, error)
+	Update(userID int, request *dto.ProfileRequest) (*dto.UserResponse, error)
+}
+
+type profileUsecase struct {
+	repository repositories.UserRepository
+}
+
+func NewProfileUsecase(r repositories.UserRepository) *profileUsecase {
+	return &profileUsecase{r}
+}
+
+func (uc *profileUsecase) Get(userID int) (*dto.UserResponse, error) {
+	user, err := uc.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return toUserResponse(user), nil
+}
+
+// Update changes the profile fields set in request. Nothing is stored unless
+// all of them are valid.
+func (uc *profileUsecase) Update(userID int, request *dto.ProfileRequest) (*dto.UserResponse, error) {
+	user, err := uc.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyProfileRequest(user, request); err != nil {
+		return nil, err
+	}
+	if err := uc.repository.UpdateProfile(user); err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return toUserResponse(user), nil
+}
+
+func (uc *profileUsecase) findUser(userID int) (*entities.User, error) {
+	user, err := uc.repository.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.NotFoundError{Message: "User not found"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return user, nil
+}
+
+// applyProfileRequest validates and normalizes the fields set in request and
+// copies them to user.
+func applyProfileRequest(user *entities.User, request *dto.ProfileRequest) error {
+	if request.DisplayName != nil {
+		name := strings.TrimSpace(*request.DisplayName)
+		if utf8.RuneCountInString(name) > maxDisplayNameLength {
+			return &errorHandler.BadRequestError{Message: "Display name is too long"}
+		}
+		user.DisplayName = name
+	}
+
+	if request.AvatarURL != nil {
+		avatar := strings.TrimSpace(*request.AvatarURL)
+		if avatar != "" {
+			parsed, err := url.ParseRequestURI(avatar)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return &errorHandler.BadRequestError{Message: "Avatar URL must be an absolute http or https URL"}
+			}
+			if len(avatar) > maxURLLength {
+				return &errorHandler.BadRequestError{Message: "Avatar URL is too long"}
+			}
+		}
+		user.AvatarURL = avatar
+	}
+
+	if request.Birthday != nil {
+		user.Birthday = nil
+		if raw := strings.TrimSpace(*request.Birthday); raw != "" {
+			// Dates are read back in the local time zone of the database
+			// connection, so they are stored as local midnight as well.
+			birthday, err := time.ParseInLocation(time.DateOnly, raw, time.Local)
+			if err != nil {
+				return &errorHandler.BadRequestError{Message: "Birthday must be a YYYY-MM-DD date"}
+			}
+			if birthday.Before(minBirthday) || birthday.After(time.Now()) {
+				return &errorHandler.BadRequestError{Message: "Birthday must be a past date after 1900"}
+			}
+			user.Birthday = &birthday
+		}
+	}
+
+	if request.Locale != nil {
+		locale := strings.TrimSpace(*request.Locale)
+		if locale != "" {
+			tag, err := language.Parse(locale)
+			if err != nil || len(tag.String()) > maxLocaleLength {
+				return &errorHandler.BadRequestError{Message: "Locale must be a BCP 47 language tag such as en-US"}
+			}
+			locale = tag.String()
+		}
+		user.Locale = locale
+	}
+
+	if request.Timezone != nil {
+		timezone := strings.TrimSpace(*request.Timezone)
+		if timezone != "" {
+			// LoadLocation also accepts "Local", which means nothing to
+			// other machines.
+			if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" || len(timezone) > maxTimezoneLength {
+				return &errorHandler.BadRequestError{Message: "Timezone must be an IANA time zone such as Europe/Berlin"}
+			}
+		}
+		user.Timezone = timezone
+	}
+
+	if request.DefaultCurrency != nil {
+		code := strings.ToUpper(strings.TrimSpace(*request.DefaultCurrency))
+		if code != "" {
+			if _, err := currency.ParseISO(code); err != nil {
+				return &errorHandler.BadRequestError{Message: "Default currency must be an ISO 4217 code"}
+			}
+		}
+		user.DefaultCurrency = code
+	}
+	return nil
+}
+
+func toUserResponse(user *entities.User) *dto.UserResponse {
+	response := &dto.UserResponse{
+		ID:              user.Id,
+		Email:           user.Email,
+		PendingEmail:    user.PendingEmail,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		Role:            user.Role,
+		DisplayName:     user.DisplayName,
+		AvatarURL:       user.AvatarURL,
+		Locale:          user.Locale,
+		Timezone:        user.Timezone,
+		DefaultCurrency: user.DefaultCurrency,
+		CreatedAt:       user.CreatedAt,
+	}
+	if user.Birthday != nil {
+		birthday := user.Birthday.Format(time.DateOnly)
+		response.Birthday = &birthday
+	}
+	return response
+}
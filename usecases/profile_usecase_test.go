@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/drivers/mysql/mocks"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestProfileUsecase_Get(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		uc := NewProfileUsecase(mockRepo)
+		birthday := time.Date(1990, time.May, 17, 0, 0, 0, 0, time.Local)
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", Password: "hash", Birthday: &birthday}, nil)
+
+		profile, err := uc.Get(1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1990-05-17", *profile.Birthday)
+		body, _ := json.Marshal(profile)
+		assert.NotContains(t, string(body), "hash")
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		uc := NewProfileUsecase(mockRepo)
+		mockRepo.On("FindByID", 1).Return(nil, gorm.ErrRecordNotFound)
+		_, err := uc.Get(1)
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+	})
+}
+
+func TestProfileUsecase_Update(t *testing.T) {
+	t.Run("Normalizes fields", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		uc := NewProfileUsecase(mockRepo)
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, DisplayName: "Old", Timezone: "UTC"}, nil)
+		mockRepo.On("UpdateProfile", mock.MatchedBy(func(user *entities.User) bool {
+			return user.DisplayName == "Ada" && user.Locale == "en-GB" && user.DefaultCurrency == "EUR" &&
+				user.Timezone == "UTC" && user.Birthday.Format(time.DateOnly) == "1990-05-17"
+		})).Return(nil)
+
+		profile, err := uc.Update(1, &dto.ProfileRequest{
+			DisplayName:     stringPtr("  Ada "),
+			Birthday:        stringPtr("1990-05-17"),
+			Locale:          stringPtr("en-gb"),
+			DefaultCurrency: stringPtr("eur"),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Ada", profile.DisplayName)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Empty strings clear fields", func(t *testing.T) {
+		mockRepo := new(mocks.MockUserRepository)
+		uc := NewProfileUsecase(mockRepo)
+		birthday := time.Date(1990, time.May, 17, 0, 0, 0, 0, time.Local)
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, AvatarURL: "https://example.com/a.png", Birthday: &birthday}, nil)
+		mockRepo.On("UpdateProfile", mock.MatchedBy(func(user *entities.User) bool {
+			return user.AvatarURL == "" && user.Birthday == nil
+		})).Return(nil)
+
+		_, err := uc.Update(1, &dto.ProfileRequest{AvatarURL: stringPtr(""), Birthday: stringPtr("")})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	invalid := map[string]*dto.ProfileRequest{
+		"Avatar without http":    {AvatarURL: stringPtr("javascript:alert(1)")},
+		"Birthday in the future": {Birthday: stringPtr(time.Now().AddDate(1, 0, 0).Format(time.DateOnly))},
+		"Malformed birthday":     {Birthday: stringPtr("17.05.1990")},
+		"Unknown locale":         {Locale: stringPtr("not a locale")},
+		"Unknown timezone":       {Timezone: stringPtr("Mars/Olympus")},
+		"Local timezone":         {Timezone: stringPtr("Local")},
+		"Unknown currency":       {DefaultCurrency: stringPtr("ABC")},
+	}
+	for name, request := range invalid {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mocks.MockUserRepository)
+			uc := NewProfileUsecase(mockRepo)
+			mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1}, nil)
+
+			_, err := uc.Update(1, request)
+
+			assert.IsType(t, &errorHandler.BadRequestError{}, err)
+			mockRepo.AssertNotCalled(t, "UpdateProfile", mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"errors"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/repositories"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// maxUserAgentLen is the length user agents are cut to when a session is
+// recorded.
+const maxUserAgentLen = 255
+
+type SessionUsecase interface {
+	GetAll(claims *helper.JWTClaims) ([]*dto.SessionResponse, error)
+	Revoke(claims *helper.JWTClaims, id uint) error
+}
+
+type sessionUsecase struct {
+	tokenRepository repositories.TokenRepository
+}
+
+func NewSessionUsecase(tr repositories.TokenRepository) *sessionUsecase {
+	return &sessionUsecase{tr}
+}
+
+// GetAll lists the devices the user is signed in on.
+func (uc *sessionUsecase) GetAll(claims *helper.JWTClaims) ([]*dto.SessionResponse, error) {
+	sessions, err := uc.tokenRepository.FindActiveSessions(claims.Id)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	responses := make([]*dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = toSessionResponse(session, claims.SessionID)
+	}
+	return responses, nil
+}
+
+// Revoke signs the user out on one device. Its refresh token stops working
+// and its access tokens are rejected from now on.
+func (uc *sessionUsecase) Revoke(claims *helper.JWTClaims, id uint) error {
+	if err := uc.tokenRepository.RevokeSession(claims.Id, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &errorHandler.NotFoundError{Message: "Session not found"}
+		}
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+func toSessionResponse(session *entities.Session, currentID uint) *dto.SessionResponse {
+	return &dto.SessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		Current:    currentID != 0 && session.ID == currentID,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+	}
+}
+
+// truncate cuts s to at most max runes.
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
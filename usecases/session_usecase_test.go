@@ -0,0 +1,52 @@
+package usecases
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/drivers/mysql/mocks"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+)
+
+func TestSessionUsecase_GetAll(t *testing.T) {
+	mockTokenRepo := new(mocks.MockTokenRepository)
+	uc := NewSessionUsecase(mockTokenRepo)
+	mockTokenRepo.On("FindActiveSessions", 1).Return([]*entities.Session{
+		{ID: 3, UserID: 1, UserAgent: "Firefox", IP: "10.0.0.1"},
+		{ID: 4, UserID: 1, UserAgent: "wishlist-cli/1.0", IP: "10.0.0.2"},
+	}, nil)
+
+	sessions, err := uc.GetAll(&helper.JWTClaims{Id: 1, SessionID: 4})
+
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+	assert.Equal(t, "10.0.0.2", sessions[1].IP)
+}
+
+func TestSessionUsecase_Revoke(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewSessionUsecase(mockTokenRepo)
+		mockTokenRepo.On("RevokeSession", 1, uint(3)).Return(nil)
+		assert.NoError(t, uc.Revoke(&helper.JWTClaims{Id: 1, SessionID: 4}, 3))
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewSessionUsecase(mockTokenRepo)
+		mockTokenRepo.On("RevokeSession", 1, uint(3)).Return(gorm.ErrRecordNotFound)
+		assert.IsType(t, &errorHandler.NotFoundError{}, uc.Revoke(&helper.JWTClaims{Id: 1}, 3))
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "Firefox", truncate("Firefox", maxUserAgentLen))
+	long := strings.Repeat("é", maxUserAgentLen+10)
+	assert.Equal(t, strings.Repeat("é", maxUserAgentLen), truncate(long, maxUserAgentLen))
+}
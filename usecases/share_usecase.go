@@ -0,0 +1,480 @@
+package usecases
+
+import (
+	"errors"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/lockout"
+	"go-wishlist-api-2/repositories"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// maxSharePasswordLength is measured in bytes since bcrypt only uses the
+	// first 72.
+	maxSharePasswordLength = 72
+	maxClaimNameLength     = 100
+)
+
+type ShareUsecase interface {
+	Get(userID int, listID uint) (*dto.ListShareResponse, error)
+	Create(userID int, listID uint, request *dto.ListShareRequest) (*dto.ListShareResponse, error)
+	Regenerate(userID int, listID uint) (*dto.ListShareResponse, error)
+	UpdateSettings(userID int, listID uint, request *dto.ShareSettingsRequest) (*dto.ListShareResponse, error)
+	Revoke(userID int, listID uint) error
+	View(token string, query *dto.SharedListQuery) (*dto.SharedListResponse, *dto.PageMeta, error)
+	Claim(token string, wishlistID uint, access *dto.ShareAccess, request *dto.ClaimRequest) (*dto.ClaimResponse, error)
+	UpdateClaim(token string, claimID uint, claimToken string, access *dto.ShareAccess, request *dto.ClaimUpdateRequest) (*dto.ClaimResponse, error)
+	ReleaseClaim(token string, claimID uint, claimToken string, access *dto.ShareAccess) error
+}
+
+type shareUsecase struct {
+	listRepository     repositories.ListRepository
+	wishlistRepository repositories.WishlistRepository
+	claimRepository    repositories.ClaimRepository
+	appURL             string
+	limiter            *lockout.Limiter
+}
+
+// NewShareUsecase builds share links on appURL. Wrong passwords for a share
+// are throttled per client IP by limiter, which defaults to an in-memory
+// limiter with the default account lockout policy.
+func NewShareUsecase(lr repositories.ListRepository, wr repositories.WishlistRepository, cr repositories.ClaimRepository, appURL string, limiter *lockout.Limiter) *shareUsecase {
+	if appURL == "" {
+		appURL = defaultAppURL
+	}
+	if limiter == nil {
+		limiter = lockout.NewLimiter(lockout.NewMemoryStore(), lockout.DefaultAccountPolicy)
+	}
+	return &shareUsecase{lr, wr, cr, strings.TrimRight(appURL, "/"), limiter}
+}
+
+func (uc *shareUsecase) Get(userID int, listID uint) (*dto.ListShareResponse, error) {
+	if _, err := findOwnedList(uc.listRepository, userID, listID); err != nil {
+		return nil, err
+	}
+	share, err := uc.listRepository.FindShare(listID)
+	if err != nil {
+		return nil, shareError(err)
+	}
+	return toListShareResponse(share), nil
+}
+
+// Create shares the list under a new link, replacing the previous link of
+// the list if there is one.
+func (uc *shareUsecase) Create(userID int, listID uint, request *dto.ListShareRequest) (*dto.ListShareResponse, error) {
+	if _, err := findOwnedList(uc.listRepository, userID, listID); err != nil {
+		return nil, err
+	}
+	if len(request.Password) > maxSharePasswordLength {
+		return nil, &errorHandler.BadRequestError{Message: "Password must be at most 72 bytes long"}
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return nil, &errorHandler.BadRequestError{Message: "expires_at must be in the future"}
+	}
+
+	share := &entities.ListShare{ListID: listID, ExpiresAt: request.ExpiresAt, RevealClaims: request.RevealClaims}
+	if request.Password != "" {
+		hash, err := helper.HashPassword(request.Password)
+		if err != nil {
+			return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		share.PasswordHash = hash
+	}
+	token, err := helper.GenerateShareToken()
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	share.TokenHash = helper.HashToken(token)
+	saved, err := uc.listRepository.SaveShare(share)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return uc.withLink(toListShareResponse(saved), token), nil
+}
+
+// Regenerate replaces the link of a shared list, keeping its password and
+// expiry. The previous link stops working.
+func (uc *shareUsecase) Regenerate(userID int, listID uint) (*dto.ListShareResponse, error) {
+	if _, err := findOwnedList(uc.listRepository, userID, listID); err != nil {
+		return nil, err
+	}
+	token, err := helper.GenerateShareToken()
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if err := uc.listRepository.UpdateShareToken(listID, helper.HashToken(token)); err != nil {
+		return nil, shareError(err)
+	}
+	share, err := uc.listRepository.FindShare(listID)
+	if err != nil {
+		return nil, shareError(err)
+	}
+	return uc.withLink(toListShareResponse(share), token), nil
+}
+
+// UpdateSettings changes the settings of a shared list without replacing its
+// link.
+func (uc *shareUsecase) UpdateSettings(userID int, listID uint, request *dto.ShareSettingsRequest) (*dto.ListShareResponse, error) {
+	if _, err := findOwnedList(uc.listRepository, userID, listID); err != nil {
+		return nil, err
+	}
+	if request.RevealClaims == nil {
+		return nil, &errorHandler.BadRequestError{Message: "reveal_claims must be filled"}
+	}
+	share, err := uc.listRepository.FindShare(listID)
+	if err != nil {
+		return nil, shareError(err)
+	}
+	if err := uc.listRepository.UpdateShareRevealClaims(listID, *request.RevealClaims); err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	share.RevealClaims = *request.RevealClaims
+	return toListShareResponse(share), nil
+}
+
+func (uc *shareUsecase) Revoke(userID int, listID uint) error {
+	if _, err := findOwnedList(uc.listRepository, userID, listID); err != nil {
+		return err
+	}
+	if err := uc.listRepository.DeleteShare(listID); err != nil {
+		return shareError(err)
+	}
+	return nil
+}
+
+// View returns one page of a shared list to anyone holding its link, with
+// the claims on every item unless the owner looks at the list in surprise
+// mode.
+func (uc *shareUsecase) View(token string, query *dto.SharedListQuery) (*dto.SharedListResponse, *dto.PageMeta, error) {
+	if query.Limit < 0 || query.Offset < 0 {
+		return nil, nil, &errorHandler.BadRequestError{Message: "limit and offset must not be negative"}
+	}
+	share, err := uc.openShare(token, &query.ShareAccess)
+	if err != nil {
+		return nil, nil, err
+	}
+	list := share.List
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	wishlists, total, err := uc.wishlistRepository.GetAll(&dto.WishlistFilter{
+		UserID: list.UserID,
+		ListID: &list.ID,
+		Sort:   []dto.SortField{{Column: "id"}},
+		Limit:  limit,
+		Offset: query.Offset,
+	})
+	if err != nil {
+		return nil, nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	response := &dto.SharedListResponse{
+		Name:         list.Name,
+		OwnerName:    list.User.DisplayName,
+		ClaimsHidden: query.ViewerID == list.UserID && !share.RevealClaims,
+		Items:        make([]*dto.SharedWishlistResponse, len(wishlists)),
+	}
+	for i, wishlist := range wishlists {
+		response.Items[i] = toSharedWishlistResponse(wishlist)
+	}
+	if !response.ClaimsHidden {
+		// The owner can only be recognised by their sign in, so anonymous
+		// visitors see how many units are taken but not by whom.
+		withClaimers := share.RevealClaims || query.ViewerID != 0
+		if err := uc.addClaims(response.Items, withClaimers); err != nil {
+			return nil, nil, err
+		}
+	}
+	return response, &dto.PageMeta{Total: total, Limit: limit, Offset: query.Offset}, nil
+}
+
+// Claim reserves or buys units of an item of a shared list for the visitor.
+// The owner cannot claim gifts on their own list; like surprise mode this is
+// best effort, as an owner who is signed out looks like any other visitor.
+func (uc *shareUsecase) Claim(token string, wishlistID uint, access *dto.ShareAccess, request *dto.ClaimRequest) (*dto.ClaimResponse, error) {
+	share, err := uc.openShare(token, access)
+	if err != nil {
+		return nil, err
+	}
+	if access.ViewerID == share.List.UserID {
+		return nil, &errorHandler.ForbiddenError{Message: "You cannot claim gifts on your own list"}
+	}
+	claim := &entities.GiftClaim{
+		WishlistID: wishlistID,
+		Name:       strings.TrimSpace(request.Name),
+		Status:     request.Status,
+		Quantity:   request.Quantity,
+	}
+	if err := normalizeClaim(claim); err != nil {
+		return nil, err
+	}
+	wishlist, err := uc.wishlistRepository.FindByID(wishlistID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.NotFoundError{Message: "Item not found"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if wishlist.ListID == nil || *wishlist.ListID != share.ListID {
+		return nil, &errorHandler.NotFoundError{Message: "Item not found"}
+	}
+	if wishlist.IsAchieved {
+		return nil, &errorHandler.BadRequestError{Message: "Item is already achieved"}
+	}
+
+	claimToken, err := helper.GenerateClaimToken()
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	claim.TokenHash = helper.HashToken(claimToken)
+	claim, err = uc.claimRepository.CreateClaim(claim)
+	if err != nil {
+		return nil, claimError(err)
+	}
+	response := toClaimResponse(claim)
+	response.Token = claimToken
+	return response, nil
+}
+
+// UpdateClaim changes the status of a claim, typically from reserved to
+// purchased. Only the holder of the claim's token may change it.
+func (uc *shareUsecase) UpdateClaim(token string, claimID uint, claimToken string, access *dto.ShareAccess, request *dto.ClaimUpdateRequest) (*dto.ClaimResponse, error) {
+	claim, err := uc.findClaim(token, claimID, claimToken, access)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != entities.ClaimReserved && request.Status != entities.ClaimPurchased {
+		return nil, &errorHandler.BadRequestError{Message: "Status must be reserved or purchased"}
+	}
+	if err := uc.claimRepository.UpdateStatus(claim.ID, request.Status); err != nil {
+		return nil, claimError(err)
+	}
+	claim.Status = request.Status
+	return toClaimResponse(claim), nil
+}
+
+// ReleaseClaim withdraws a claim so that others can claim the units again.
+// Only the holder of the claim's token may release it.
+func (uc *shareUsecase) ReleaseClaim(token string, claimID uint, claimToken string, access *dto.ShareAccess) error {
+	claim, err := uc.findClaim(token, claimID, claimToken, access)
+	if err != nil {
+		return err
+	}
+	if err := uc.claimRepository.DeleteClaim(claim.ID); err != nil {
+		return claimError(err)
+	}
+	return nil
+}
+
+// openShare returns the share with the given token if the visitor may open
+// it. Links of lists in the trash and of disabled or deleted accounts stop
+// working.
+func (uc *shareUsecase) openShare(token string, access *dto.ShareAccess) (*entities.ListShare, error) {
+	notFound := &errorHandler.NotFoundError{Message: "Shared list not found"}
+	share, err := uc.listRepository.FindShareByTokenHash(helper.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFound
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	list := share.List
+	if list == nil || list.User == nil || list.User.DisabledAt != nil || list.User.DeletionScheduledAt != nil {
+		return nil, notFound
+	}
+	if share.ExpiresAt != nil && !share.ExpiresAt.After(time.Now()) {
+		return nil, &errorHandler.NotFoundError{Message: "Share link has expired"}
+	}
+	if err := uc.checkSharePassword(share, access); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// findClaim returns a claim on the shared list if claimToken is its token.
+func (uc *shareUsecase) findClaim(token string, claimID uint, claimToken string, access *dto.ShareAccess) (*entities.GiftClaim, error) {
+	share, err := uc.openShare(token, access)
+	if err != nil {
+		return nil, err
+	}
+	claim, err := uc.claimRepository.FindByID(claimID)
+	if err != nil {
+		return nil, claimError(err)
+	}
+	if claim.Wishlist == nil || claim.Wishlist.ListID == nil || *claim.Wishlist.ListID != share.ListID {
+		return nil, &errorHandler.NotFoundError{Message: "Claim not found"}
+	}
+	if claimToken == "" || helper.HashToken(claimToken) != claim.TokenHash {
+		return nil, &errorHandler.ForbiddenError{Message: "Only whoever made the claim can change it"}
+	}
+	return claim, nil
+}
+
+// addClaims fills in the claims of the items.
+func (uc *shareUsecase) addClaims(items []*dto.SharedWishlistResponse, withClaimers bool) error {
+	ids := make([]uint, len(items))
+	byID := make(map[uint]*dto.SharedWishlistResponse, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+		byID[item.ID] = item
+		claimed := 0
+		item.Claimed = &claimed
+		if withClaimers {
+			item.Claims = []*dto.SharedClaimResponse{}
+		}
+	}
+	claims, err := uc.claimRepository.FindByWishlistIDs(ids)
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	for _, claim := range claims {
+		item, ok := byID[claim.WishlistID]
+		if !ok {
+			continue
+		}
+		*item.Claimed += claim.Quantity
+		if !withClaimers {
+			continue
+		}
+		item.Claims = append(item.Claims, &dto.SharedClaimResponse{
+			ID:        claim.ID,
+			Name:      claim.Name,
+			Status:    claim.Status,
+			Quantity:  claim.Quantity,
+			CreatedAt: claim.CreatedAt,
+		})
+	}
+	return nil
+}
+
+// checkSharePassword lets the visitor in if the share needs no password or
+// the right one was given. Attempts are counted per share and client IP
+// before the password is checked, so that the password cannot be guessed,
+// not even with parallel requests, and so that guessing from one address
+// does not lock everyone else out of the list.
+func (uc *shareUsecase) checkSharePassword(share *entities.ListShare, access *dto.ShareAccess) error {
+	if share.PasswordHash == "" {
+		return nil
+	}
+	if access.Password == "" {
+		return &errorHandler.UnAuthorizedError{Message: "This list is password protected"}
+	}
+	key := "share:" + strconv.FormatUint(uint64(share.ID), 10) + ":ip:" + access.IP
+	wait, err := uc.limiter.Reserve(key)
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if wait > 0 {
+		return &errorHandler.TooManyRequestsError{
+			Message:    "Too many wrong passwords, please try again later",
+			RetryAfter: wait,
+		}
+	}
+	if err := helper.VerifyPassword(access.Password, share.PasswordHash); err != nil {
+		return &errorHandler.UnAuthorizedError{Message: "Wrong password"}
+	}
+	if err := uc.limiter.Reset(key); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+func (uc *shareUsecase) withLink(response *dto.ListShareResponse, token string) *dto.ListShareResponse {
+	response.Token = token
+	response.URL = uc.appURL + "/shared/" + token
+	return response
+}
+
+func normalizeClaim(claim *entities.GiftClaim) error {
+	if claim.Name == "" {
+		return &errorHandler.BadRequestError{Message: "Name must be filled"}
+	}
+	if utf8.RuneCountInString(claim.Name) > maxClaimNameLength {
+		return &errorHandler.BadRequestError{Message: "Name is too long"}
+	}
+	if claim.Status == "" {
+		claim.Status = entities.ClaimReserved
+	}
+	if claim.Status != entities.ClaimReserved && claim.Status != entities.ClaimPurchased {
+		return &errorHandler.BadRequestError{Message: "Status must be reserved or purchased"}
+	}
+	if claim.Quantity == 0 {
+		claim.Quantity = 1
+	}
+	if claim.Quantity < 1 {
+		return &errorHandler.BadRequestError{Message: "Quantity must be at least 1"}
+	}
+	return nil
+}
+
+func claimError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &errorHandler.NotFoundError{Message: "Claim not found"}
+	}
+	if errors.Is(err, repositories.ErrClaimLimitReached) {
+		return &errorHandler.ConflictError{Message: "Item is already fully claimed"}
+	}
+	return &errorHandler.InternalServerError{Message: err.Error()}
+}
+
+func shareError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &errorHandler.NotFoundError{Message: "List is not shared"}
+	}
+	return &errorHandler.InternalServerError{Message: err.Error()}
+}
+
+func toListShareResponse(share *entities.ListShare) *dto.ListShareResponse {
+	return &dto.ListShareResponse{
+		ListID:       share.ListID,
+		HasPassword:  share.PasswordHash != "",
+		ExpiresAt:    share.ExpiresAt,
+		RevealClaims: share.RevealClaims,
+		CreatedAt:    share.CreatedAt,
+		UpdatedAt:    share.UpdatedAt,
+	}
+}
+
+func toClaimResponse(claim *entities.GiftClaim) *dto.ClaimResponse {
+	return &dto.ClaimResponse{
+		ID:         claim.ID,
+		WishlistID: claim.WishlistID,
+		Name:       claim.Name,
+		Status:     claim.Status,
+		Quantity:   claim.Quantity,
+		CreatedAt:  claim.CreatedAt,
+		UpdatedAt:  claim.UpdatedAt,
+	}
+}
+
+func toSharedWishlistResponse(wishlist *entities.Wishlist) *dto.SharedWishlistResponse {
+	tags := make([]string, len(wishlist.Tags))
+	for i, tag := range wishlist.Tags {
+		tags[i] = tag.Name
+	}
+	return &dto.SharedWishlistResponse{
+		ID:         wishlist.ID,
+		Title:      wishlist.Title,
+		IsAchieved: wishlist.IsAchieved,
+		Price:      wishlist.PriceAmount,
+		Currency:   wishlist.Currency,
+		URL:        wishlist.URL,
+		Priority:   wishlist.Priority,
+		Quantity:   wishlist.Quantity,
+		Notes:      wishlist.Notes,
+		TargetDate: wishlist.TargetDate,
+		Tags:       tags,
+	}
+}
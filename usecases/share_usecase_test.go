@@ -0,0 +1,406 @@
+package usecases
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/drivers/mysql/mocks"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/lockout"
+	"go-wishlist-api-2/repositories"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+	"time"
+)
+
+type shareTest struct {
+	lists     *mocks.MockListRepository
+	wishlists *mocks.MockWishlistRepository
+	claims    *mocks.MockClaimRepository
+	uc        *shareUsecase
+}
+
+func newShareTest() *shareTest {
+	test := &shareTest{
+		lists:     new(mocks.MockListRepository),
+		wishlists: new(mocks.MockWishlistRepository),
+		claims:    new(mocks.MockClaimRepository),
+	}
+	test.uc = NewShareUsecase(test.lists, test.wishlists, test.claims, "https://wishlist.example.com/", nil)
+	return test
+}
+
+// sharedList returns a share of list 2 owned by user 1.
+func sharedList(passwordHash string) *entities.ListShare {
+	return &entities.ListShare{
+		ID:           4,
+		ListID:       2,
+		PasswordHash: passwordHash,
+		List: &entities.List{
+			ID:     2,
+			UserID: 1,
+			Name:   "Birthday",
+			User:   &entities.User{Id: 1, Email: "owner@example.com", DisplayName: "Ada"},
+		},
+	}
+}
+
+func TestShareUsecase_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		test := newShareTest()
+		expiresAt := time.Now().Add(24 * time.Hour)
+		test.lists.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 1}, nil)
+		test.lists.On("SaveShare", mock.MatchedBy(func(share *entities.ListShare) bool {
+			return share.ListID == 2 && len(share.TokenHash) == 64 &&
+				helper.VerifyPassword("family", share.PasswordHash) == nil
+		})).Return(&entities.ListShare{ID: 4, ListID: 2, PasswordHash: "hash", ExpiresAt: &expiresAt}, nil)
+
+		share, err := test.uc.Create(1, 2, &dto.ListShareRequest{Password: "family", ExpiresAt: &expiresAt})
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, share.Token)
+		assert.Equal(t, "https://wishlist.example.com/shared/"+share.Token, share.URL)
+		assert.True(t, share.HasPassword)
+		test.lists.AssertExpectations(t)
+	})
+
+	t.Run("Expiry in the past", func(t *testing.T) {
+		test := newShareTest()
+		expiresAt := time.Now().Add(-time.Minute)
+		test.lists.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 1}, nil)
+		_, err := test.uc.Create(1, 2, &dto.ListShareRequest{ExpiresAt: &expiresAt})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Password too long", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 1}, nil)
+		_, err := test.uc.Create(1, 2, &dto.ListShareRequest{Password: strings.Repeat("a", 73)})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("List of another user", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 9}, nil)
+		_, err := test.uc.Create(1, 2, &dto.ListShareRequest{})
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		test.lists.AssertNotCalled(t, "SaveShare", mock.Anything)
+	})
+}
+
+func TestShareUsecase_Regenerate(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 1}, nil)
+		test.lists.On("UpdateShareToken", uint(2), mock.AnythingOfType("string")).Return(nil)
+		test.lists.On("FindShare", uint(2)).Return(&entities.ListShare{ID: 4, ListID: 2}, nil)
+
+		share, err := test.uc.Regenerate(1, 2)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, share.Token)
+		test.lists.AssertCalled(t, "UpdateShareToken", uint(2), helper.HashToken(share.Token))
+	})
+
+	t.Run("List not shared", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 1}, nil)
+		test.lists.On("UpdateShareToken", uint(2), mock.Anything).Return(gorm.ErrRecordNotFound)
+		_, err := test.uc.Regenerate(1, 2)
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+	})
+}
+
+func TestShareUsecase_UpdateSettings(t *testing.T) {
+	reveal := true
+
+	t.Run("Success", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 1}, nil)
+		test.lists.On("FindShare", uint(2)).Return(&entities.ListShare{ID: 4, ListID: 2}, nil)
+		test.lists.On("UpdateShareRevealClaims", uint(2), true).Return(nil)
+
+		share, err := test.uc.UpdateSettings(1, 2, &dto.ShareSettingsRequest{RevealClaims: &reveal})
+
+		assert.NoError(t, err)
+		assert.True(t, share.RevealClaims)
+		assert.Empty(t, share.Token)
+	})
+
+	t.Run("List not shared", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 1}, nil)
+		test.lists.On("FindShare", uint(2)).Return(nil, gorm.ErrRecordNotFound)
+		_, err := test.uc.UpdateSettings(1, 2, &dto.ShareSettingsRequest{RevealClaims: &reveal})
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+	})
+}
+
+func TestShareUsecase_Revoke(t *testing.T) {
+	test := newShareTest()
+	test.lists.On("FindByID", uint(2)).Return(&entities.List{ID: 2, UserID: 1}, nil)
+	test.lists.On("DeleteShare", uint(2)).Return(nil)
+	assert.NoError(t, test.uc.Revoke(1, 2))
+	test.lists.AssertExpectations(t)
+}
+
+func TestShareUsecase_View(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", helper.HashToken("token")).Return(sharedList(""), nil)
+		test.wishlists.On("GetAll", mock.MatchedBy(func(filter *dto.WishlistFilter) bool {
+			return filter.UserID == 1 && *filter.ListID == 2 && filter.Limit == defaultPageLimit
+		})).Return([]*entities.Wishlist{{ID: 5, UserID: 1, Title: "Bike", Quantity: 2, Tags: []entities.Tag{{Name: "sport"}}}}, int64(1), nil)
+		test.claims.On("FindByWishlistIDs", []uint{5}).Return([]*entities.GiftClaim{
+			{ID: 7, WishlistID: 5, Name: "Grace", Status: entities.ClaimPurchased, Quantity: 1},
+		}, nil)
+
+		list, meta, err := test.uc.View("token", &dto.SharedListQuery{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Birthday", list.Name)
+		assert.Equal(t, "Ada", list.OwnerName)
+		assert.Equal(t, []string{"sport"}, list.Items[0].Tags)
+		assert.False(t, list.ClaimsHidden)
+		assert.Equal(t, 1, *list.Items[0].Claimed)
+		assert.Nil(t, list.Items[0].Claims, "anonymous visitors must not see who claimed")
+		assert.Equal(t, int64(1), meta.Total)
+	})
+
+	t.Run("Signed in guest", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(""), nil)
+		test.wishlists.On("GetAll", mock.Anything).Return([]*entities.Wishlist{{ID: 5, UserID: 1}}, int64(1), nil)
+		test.claims.On("FindByWishlistIDs", []uint{5}).Return([]*entities.GiftClaim{
+			{ID: 7, WishlistID: 5, Name: "Grace", Status: entities.ClaimPurchased, Quantity: 1},
+		}, nil)
+
+		list, _, err := test.uc.View("token", &dto.SharedListQuery{ShareAccess: dto.ShareAccess{ViewerID: 3}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, *list.Items[0].Claimed)
+		assert.Equal(t, "Grace", list.Items[0].Claims[0].Name)
+	})
+
+	t.Run("Owner in surprise mode", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(""), nil)
+		test.wishlists.On("GetAll", mock.Anything).Return([]*entities.Wishlist{{ID: 5, UserID: 1}}, int64(1), nil)
+
+		list, _, err := test.uc.View("token", &dto.SharedListQuery{ShareAccess: dto.ShareAccess{ViewerID: 1}})
+
+		assert.NoError(t, err)
+		assert.True(t, list.ClaimsHidden)
+		assert.Nil(t, list.Items[0].Claimed)
+		assert.Nil(t, list.Items[0].Claims)
+		test.claims.AssertNotCalled(t, "FindByWishlistIDs", mock.Anything)
+	})
+
+	t.Run("Owner with claims revealed", func(t *testing.T) {
+		test := newShareTest()
+		share := sharedList("")
+		share.RevealClaims = true
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(share, nil)
+		test.wishlists.On("GetAll", mock.Anything).Return([]*entities.Wishlist{{ID: 5, UserID: 1}}, int64(1), nil)
+		test.claims.On("FindByWishlistIDs", []uint{5}).Return([]*entities.GiftClaim{}, nil)
+
+		list, _, err := test.uc.View("token", &dto.SharedListQuery{ShareAccess: dto.ShareAccess{ViewerID: 1}})
+
+		assert.NoError(t, err)
+		assert.False(t, list.ClaimsHidden)
+		assert.Equal(t, 0, *list.Items[0].Claimed)
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+		_, _, err := test.uc.View("token", &dto.SharedListQuery{})
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+	})
+
+	t.Run("List in the trash", func(t *testing.T) {
+		test := newShareTest()
+		share := sharedList("")
+		share.List = nil
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(share, nil)
+		_, _, err := test.uc.View("token", &dto.SharedListQuery{})
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		test := newShareTest()
+		share := sharedList("")
+		expiresAt := time.Now().Add(-time.Minute)
+		share.ExpiresAt = &expiresAt
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(share, nil)
+		_, _, err := test.uc.View("token", &dto.SharedListQuery{})
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+		test.wishlists.AssertNotCalled(t, "GetAll", mock.Anything)
+	})
+
+	t.Run("Password required", func(t *testing.T) {
+		test := newShareTest()
+		hash, _ := helper.HashPassword("family")
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(hash), nil)
+		_, _, err := test.uc.View("token", &dto.SharedListQuery{})
+		assert.IsType(t, &errorHandler.UnAuthorizedError{}, err)
+	})
+
+	t.Run("Correct password", func(t *testing.T) {
+		test := newShareTest()
+		hash, _ := helper.HashPassword("family")
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(hash), nil)
+		test.wishlists.On("GetAll", mock.Anything).Return([]*entities.Wishlist{}, int64(0), nil)
+		test.claims.On("FindByWishlistIDs", []uint{}).Return([]*entities.GiftClaim{}, nil)
+		_, _, err := test.uc.View("token", &dto.SharedListQuery{ShareAccess: dto.ShareAccess{Password: "family"}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Wrong passwords are throttled", func(t *testing.T) {
+		test := newShareTest()
+		policy := lockout.Policy{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Minute, ResetAfter: time.Hour}
+		test.uc.limiter = lockout.NewLimiter(lockout.NewMemoryStore(), policy)
+		hash, _ := helper.HashPassword("family")
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(hash), nil)
+
+		test.wishlists.On("GetAll", mock.Anything).Return([]*entities.Wishlist{}, int64(0), nil)
+		test.claims.On("FindByWishlistIDs", []uint{}).Return([]*entities.GiftClaim{}, nil)
+		attacker := dto.ShareAccess{IP: "10.0.0.1"}
+
+		attacker.Password = "guess-1"
+		_, _, err := test.uc.View("token", &dto.SharedListQuery{ShareAccess: attacker})
+		assert.IsType(t, &errorHandler.UnAuthorizedError{}, err)
+		attacker.Password = "guess-2"
+		_, _, err = test.uc.View("token", &dto.SharedListQuery{ShareAccess: attacker})
+		assert.IsType(t, &errorHandler.UnAuthorizedError{}, err)
+		attacker.Password = "family"
+		_, _, err = test.uc.View("token", &dto.SharedListQuery{ShareAccess: attacker})
+		assert.IsType(t, &errorHandler.TooManyRequestsError{}, err)
+
+		// Visitors from other addresses are not locked out.
+		_, _, err = test.uc.View("token", &dto.SharedListQuery{ShareAccess: dto.ShareAccess{Password: "family", IP: "10.0.0.2"}})
+		assert.NoError(t, err)
+	})
+}
+
+func TestShareUsecase_Claim(t *testing.T) {
+	listID := uint(2)
+	otherListID := uint(3)
+
+	t.Run("Success", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(""), nil)
+		test.wishlists.On("FindByID", uint(5)).Return(&entities.Wishlist{ID: 5, UserID: 1, ListID: &listID, Quantity: 2}, nil)
+		test.claims.On("CreateClaim", mock.MatchedBy(func(claim *entities.GiftClaim) bool {
+			return claim.WishlistID == 5 && claim.Name == "Grace" && claim.Status == entities.ClaimReserved &&
+				claim.Quantity == 1 && len(claim.TokenHash) == 64
+		})).Return(&entities.GiftClaim{ID: 7, WishlistID: 5, Name: "Grace", Status: entities.ClaimReserved, Quantity: 1}, nil)
+
+		claim, err := test.uc.Claim("token", 5, &dto.ShareAccess{}, &dto.ClaimRequest{Name: " Grace "})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), claim.ID)
+		assert.NotEmpty(t, claim.Token)
+		test.claims.AssertExpectations(t)
+	})
+
+	t.Run("Owner", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(""), nil)
+		_, err := test.uc.Claim("token", 5, &dto.ShareAccess{ViewerID: 1}, &dto.ClaimRequest{Name: "Ada"})
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+	})
+
+	t.Run("Invalid status", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(""), nil)
+		_, err := test.uc.Claim("token", 5, &dto.ShareAccess{}, &dto.ClaimRequest{Name: "Grace", Status: "wrapped"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Item of another list", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(""), nil)
+		test.wishlists.On("FindByID", uint(5)).Return(&entities.Wishlist{ID: 5, UserID: 1, ListID: &otherListID}, nil)
+		_, err := test.uc.Claim("token", 5, &dto.ShareAccess{}, &dto.ClaimRequest{Name: "Grace"})
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+		test.claims.AssertNotCalled(t, "CreateClaim", mock.Anything)
+	})
+
+	t.Run("Fully claimed", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(""), nil)
+		test.wishlists.On("FindByID", uint(5)).Return(&entities.Wishlist{ID: 5, UserID: 1, ListID: &listID, Quantity: 1}, nil)
+		test.claims.On("CreateClaim", mock.Anything).Return(nil, repositories.ErrClaimLimitReached)
+		_, err := test.uc.Claim("token", 5, &dto.ShareAccess{}, &dto.ClaimRequest{Name: "Grace"})
+		assert.IsType(t, &errorHandler.ConflictError{}, err)
+	})
+}
+
+func TestShareUsecase_UpdateClaim(t *testing.T) {
+	listID := uint(2)
+	claim := func() *entities.GiftClaim {
+		return &entities.GiftClaim{
+			ID: 7, WishlistID: 5, Name: "Grace", Status: entities.ClaimReserved, Quantity: 1,
+			TokenHash: helper.HashToken("claim-token"),
+			Wishlist:  &entities.Wishlist{ID: 5, ListID: &listID},
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(""), nil)
+		test.claims.On("FindByID", uint(7)).Return(claim(), nil)
+		test.claims.On("UpdateStatus", uint(7), entities.ClaimPurchased).Return(nil)
+
+		updated, err := test.uc.UpdateClaim("token", 7, "claim-token", &dto.ShareAccess{}, &dto.ClaimUpdateRequest{Status: entities.ClaimPurchased})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ClaimPurchased, updated.Status)
+		assert.Empty(t, updated.Token)
+	})
+
+	t.Run("Wrong claim token", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(""), nil)
+		test.claims.On("FindByID", uint(7)).Return(claim(), nil)
+
+		_, err := test.uc.UpdateClaim("token", 7, "guess", &dto.ShareAccess{}, &dto.ClaimUpdateRequest{Status: entities.ClaimPurchased})
+
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		test.claims.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+	})
+}
+
+func TestShareUsecase_ReleaseClaim(t *testing.T) {
+	listID := uint(2)
+	otherListID := uint(3)
+
+	t.Run("Success", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(""), nil)
+		test.claims.On("FindByID", uint(7)).Return(&entities.GiftClaim{
+			ID: 7, TokenHash: helper.HashToken("claim-token"), Wishlist: &entities.Wishlist{ID: 5, ListID: &listID},
+		}, nil)
+		test.claims.On("DeleteClaim", uint(7)).Return(nil)
+
+		assert.NoError(t, test.uc.ReleaseClaim("token", 7, "claim-token", &dto.ShareAccess{}))
+		test.claims.AssertExpectations(t)
+	})
+
+	t.Run("Claim on another list", func(t *testing.T) {
+		test := newShareTest()
+		test.lists.On("FindShareByTokenHash", mock.Anything).Return(sharedList(""), nil)
+		test.claims.On("FindByID", uint(7)).Return(&entities.GiftClaim{
+			ID: 7, TokenHash: helper.HashToken("claim-token"), Wishlist: &entities.Wishlist{ID: 5, ListID: &otherListID},
+		}, nil)
+
+		err := test.uc.ReleaseClaim("token", 7, "claim-token", &dto.ShareAccess{})
+
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+		test.claims.AssertNotCalled(t, "DeleteClaim", mock.Anything)
+	})
+}
@@ -0,0 +1,153 @@
+package usecases
+
+import (
+	"errors"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/repositories"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxTagLength     = 50
+	maxTagsPerItem   = 20
+	defaultTagLimit  = 10
+	maxTagSearchSize = 50
+)
+
+type TagUsecase interface {
+	Search(userID int, prefix string, limit int) ([]*entities.Tag, error)
+	Rename(userID int, id uint, tag *dto.TagRequest) (*entities.Tag, error)
+	Merge(userID int, sourceID, targetID uint) (*entities.Tag, error)
+}
+
+type tagUsecase struct {
+	repository repositories.TagRepository
+}
+
+func NewTagUsecase(r repositories.TagRepository) *tagUsecase {
+	return &tagUsecase{r}
+}
+
+func (uc *tagUsecase) Search(userID int, prefix string, limit int) ([]*entities.Tag, error) {
+	if limit <= 0 {
+		limit = defaultTagLimit
+	}
+	if limit > maxTagSearchSize {
+		limit = maxTagSearchSize
+	}
+	tags, err := uc.repository.Search(userID, normalizeTagName(prefix), limit)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return tags, nil
+}
+
+// Rename changes the name of a tag. Renaming a tag to the name of another
+// existing tag merges the two.
+func (uc *tagUsecase) Rename(userID int, id uint, req *dto.TagRequest) (*entities.Tag, error) {
+	name, err := validateTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := uc.findOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if tag.Name == name {
+		return tag, nil
+	}
+
+	existing, err := uc.repository.FindByName(userID, name)
+	if err == nil {
+		return uc.merge(tag, existing)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+
+	tag.Name = name
+	updated, err := uc.repository.UpdateTag(tag)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return updated, nil
+}
+
+func (uc *tagUsecase) Merge(userID int, sourceID, targetID uint) (*entities.Tag, error) {
+	if sourceID == targetID {
+		return nil, &errorHandler.BadRequestError{Message: "A tag cannot be merged into itself"}
+	}
+	source, err := uc.findOwned(userID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := uc.findOwned(userID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.merge(source, target)
+}
+
+func (uc *tagUsecase) merge(source, target *entities.Tag) (*entities.Tag, error) {
+	if err := uc.repository.MergeTags(source.ID, target.ID); err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return target, nil
+}
+
+func (uc *tagUsecase) findOwned(userID int, id uint) (*entities.Tag, error) {
+	tag, err := uc.repository.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.NotFoundError{Message: "Tag not found"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if tag.UserID != userID {
+		return nil, &errorHandler.ForbiddenError{Message: "You don't have access to this tag"}
+	}
+	return tag, nil
+}
+
+// normalizeTagName lower-cases a tag and collapses inner whitespace so that
+// "Under  50" and "under 50" are the same tag.
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func validateTagName(name string) (string, error) {
+	name = normalizeTagName(name)
+	if name == "" {
+		return "", &errorHandler.BadRequestError{Message: "Tag name must be filled"}
+	}
+	if utf8.RuneCountInString(name) > maxTagLength {
+		return "", &errorHandler.BadRequestError{Message: "Tag name is too long"}
+	}
+	return name, nil
+}
+
+// normalizeTagNames validates and de-duplicates a list of tag names.
+func normalizeTagNames(names []string) ([]string, error) {
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name, err := validateTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	if len(normalized) > maxTagsPerItem {
+		return nil, &errorHandler.BadRequestError{Message: "Too many tags"}
+	}
+	return normalized, nil
+}
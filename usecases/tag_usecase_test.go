@@ -0,0 +1,110 @@
+package usecases
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/drivers/mysql/mocks"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestTagUsecase_Search(t *testing.T) {
+	mockRepo := new(mocks.MockTagRepository)
+	uc := NewTagUsecase(mockRepo)
+	mockRepo.On("Search", 1, "under 50", defaultTagLimit).Return([]*entities.Tag{{ID: 1, UserID: 1, Name: "under 50"}}, nil)
+	tags, err := uc.Search(1, " Under  50", 0)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTagUsecase_Rename(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockTagRepository)
+		uc := NewTagUsecase(mockRepo)
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Tag{ID: 1, UserID: 1, Name: "tech"}, nil)
+		mockRepo.On("FindByName", 1, "gadgets").Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("UpdateTag", mock.MatchedBy(func(tag *entities.Tag) bool {
+			return tag.Name == "gadgets"
+		})).Return(&entities.Tag{ID: 1, UserID: 1, Name: "gadgets"}, nil)
+		tag, err := uc.Rename(1, 1, &dto.TagRequest{Name: "Gadgets"})
+		assert.NoError(t, err)
+		assert.Equal(t, "gadgets", tag.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Merges into existing tag", func(t *testing.T) {
+		mockRepo := new(mocks.MockTagRepository)
+		uc := NewTagUsecase(mockRepo)
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Tag{ID: 1, UserID: 1, Name: "tech"}, nil)
+		mockRepo.On("FindByName", 1, "gadgets").Return(&entities.Tag{ID: 2, UserID: 1, Name: "gadgets"}, nil)
+		mockRepo.On("MergeTags", uint(1), uint(2)).Return(nil)
+		tag, err := uc.Rename(1, 1, &dto.TagRequest{Name: "gadgets"})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), tag.ID)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpdateTag", mock.Anything)
+	})
+
+	t.Run("Owned by another user", func(t *testing.T) {
+		mockRepo := new(mocks.MockTagRepository)
+		uc := NewTagUsecase(mockRepo)
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Tag{ID: 1, UserID: 2, Name: "tech"}, nil)
+		tag, err := uc.Rename(1, 1, &dto.TagRequest{Name: "gadgets"})
+		assert.Nil(t, tag)
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+	})
+
+	t.Run("Empty name", func(t *testing.T) {
+		mockRepo := new(mocks.MockTagRepository)
+		uc := NewTagUsecase(mockRepo)
+		tag, err := uc.Rename(1, 1, &dto.TagRequest{Name: "  "})
+		assert.Nil(t, tag)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
+
+func TestTagUsecase_Merge(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockTagRepository)
+		uc := NewTagUsecase(mockRepo)
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Tag{ID: 1, UserID: 1, Name: "tech"}, nil)
+		mockRepo.On("FindByID", uint(2)).Return(&entities.Tag{ID: 2, UserID: 1, Name: "gadgets"}, nil)
+		mockRepo.On("MergeTags", uint(1), uint(2)).Return(nil)
+		tag, err := uc.Merge(1, 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "gadgets", tag.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Into itself", func(t *testing.T) {
+		mockRepo := new(mocks.MockTagRepository)
+		uc := NewTagUsecase(mockRepo)
+		tag, err := uc.Merge(1, 1, 1)
+		assert.Nil(t, tag)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Target not found", func(t *testing.T) {
+		mockRepo := new(mocks.MockTagRepository)
+		uc := NewTagUsecase(mockRepo)
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Tag{ID: 1, UserID: 1, Name: "tech"}, nil)
+		mockRepo.On("FindByID", uint(2)).Return(nil, gorm.ErrRecordNotFound)
+		tag, err := uc.Merge(1, 1, 2)
+		assert.Nil(t, tag)
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+		mockRepo.AssertNotCalled(t, "MergeTags", mock.Anything, mock.Anything)
+	})
+}
+
+func TestNormalizeTagNames(t *testing.T) {
+	names, err := normalizeTagNames([]string{"Tech", " tech ", "Under  50"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tech", "under 50"}, names)
+
+	_, err = normalizeTagNames([]string{"tech", ""})
+	assert.IsType(t, &errorHandler.BadRequestError{}, err)
+}
@@ -0,0 +1,223 @@
+package usecases
+
+import (
+	"errors"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/repositories"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount is the number of recovery codes handed out when two-factor
+// authentication is enabled.
+const recoveryCodeCount = 10
+
+// VerifyMFA is the second login step for accounts with two-factor
+// authentication. It exchanges the challenge token returned by Login and a
+// TOTP or recovery code for a token pair. Wrong codes count as failed logins.
+func (uc *authUsecase) VerifyMFA(request *dto.MFALoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	invalidToken := &errorHandler.UnAuthorizedError{Message: "Invalid or expired MFA token"}
+	claims, err := helper.ParseActionToken(request.MFAToken, helper.PurposeMFA)
+	if err != nil {
+		return nil, invalidToken
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, invalidToken
+	}
+	user, err := uc.repository.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidToken
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if !strings.EqualFold(user.Email, claims.Email) {
+		return nil, invalidToken
+	}
+
+	credential, err := uc.repository.FindTOTPCredential(user.Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidToken
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if credential.ConfirmedAt == nil {
+		return nil, invalidToken
+	}
+	accountKey := loginAccountKey(user.Email)
+	ipKey := "ip:" + client.IP
+	if err := uc.reserveLoginAttempt(accountKey, ipKey); err != nil {
+		return nil, err
+	}
+
+	invalidCode := &errorHandler.BadRequestError{Message: "Invalid authentication code"}
+	usedRecoveryCode := false
+	if step, ok := helper.ValidateTOTP(credential.Secret, request.Code, time.Now()); ok {
+		if err := uc.repository.UseTOTPStep(user.Id, step); err != nil {
+			if errors.Is(err, repositories.ErrTOTPCodeReused) {
+				return nil, invalidCode
+			}
+			return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		}
+	} else {
+		hash := helper.HashToken(helper.NormalizeRecoveryCode(request.Code))
+		if err := uc.repository.UseRecoveryCode(user.Id, hash); err != nil {
+			if errors.Is(err, repositories.ErrRecoveryCodeInvalid) {
+				return nil, invalidCode
+			}
+			return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		usedRecoveryCode = true
+	}
+
+	if err := uc.releaseLoginAttempt(accountKey, ipKey); err != nil {
+		return nil, err
+	}
+	if err := uc.options.AccountLimiter.Reset(accountKey); err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if usedRecoveryCode {
+		uc.notify(user, "A recovery code was used",
+			"A recovery code was just used to sign in to your Wishlist account. Each code works only once.\n\nIf this was not you, change your password right away.\n")
+	}
+	return uc.login(user, client)
+}
+
+// EnrollTOTP starts enabling two-factor authentication by generating a new
+// secret. It only takes effect once confirmed with a code from the app.
+func (uc *authUsecase) EnrollTOTP(claims *helper.JWTClaims) (*dto.TOTPEnrollment, error) {
+	user, err := uc.currentUser(claims)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := uc.totpEnabled(user.Id)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, &errorHandler.BadRequestError{Message: "Two-factor authentication is already enabled"}
+	}
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if err := uc.repository.SaveTOTPCredential(&entities.TOTPCredential{UserID: user.Id, Secret: secret}); err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return &dto.TOTPEnrollment{
+		Secret: secret,
+		URI:    helper.TOTPURI(uc.options.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves the
+// authenticator app works, and returns freshly generated recovery codes. The
+// codes are only ever shown here.
+func (uc *authUsecase) ConfirmTOTP(claims *helper.JWTClaims, request *dto.CodeRequest) (*dto.RecoveryCodesResponse, error) {
+	user, err := uc.currentUser(claims)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := uc.repository.FindTOTPCredential(user.Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.BadRequestError{Message: "Two-factor enrollment has not been started"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	alreadyEnabled := &errorHandler.BadRequestError{Message: "Two-factor authentication is already enabled"}
+	if credential.ConfirmedAt != nil {
+		return nil, alreadyEnabled
+	}
+	step, ok := helper.ValidateTOTP(credential.Secret, request.Code, time.Now())
+	if !ok {
+		return nil, &errorHandler.BadRequestError{Message: "Invalid authentication code"}
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	stored := make([]*entities.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := helper.GenerateRecoveryCode()
+		if err != nil {
+			return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		codes[i] = code
+		stored[i] = &entities.RecoveryCode{
+			UserID:   user.Id,
+			CodeHash: helper.HashToken(helper.NormalizeRecoveryCode(code)),
+		}
+	}
+	now := time.Now()
+	credential.ConfirmedAt = &now
+	credential.LastUsedStep = step
+	if err := uc.repository.ConfirmTOTPCredential(credential, stored); err != nil {
+		if errors.Is(err, repositories.ErrTOTPAlreadyConfirmed) {
+			return nil, alreadyEnabled
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	uc.notify(user, "Two-factor authentication enabled",
+		"Two-factor authentication was just enabled for your Wishlist account.\n\nIf you did not do this, change your password right away.\n")
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP turns two-factor authentication off after checking the
+// password and deletes the recovery codes.
+func (uc *authUsecase) DisableTOTP(claims *helper.JWTClaims, request *dto.PasswordRequest) error {
+	user, err := uc.currentUser(claims)
+	if err != nil {
+		return err
+	}
+	if err := helper.VerifyPassword(request.Password, user.Password); err != nil {
+		return &errorHandler.BadRequestError{Message: "Password is wrong"}
+	}
+	enabled, err := uc.totpEnabled(user.Id)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return &errorHandler.BadRequestError{Message: "Two-factor authentication is not enabled"}
+	}
+	if err := uc.repository.DeleteTOTPCredential(user.Id); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	uc.notify(user, "Two-factor authentication disabled",
+		"Two-factor authentication was just disabled for your Wishlist account.\n\nIf you did not do this, change your password right away.\n")
+	return nil
+}
+
+// totpEnabled reports whether the user has a confirmed TOTP credential.
+func (uc *authUsecase) totpEnabled(userID int) (bool, error) {
+	credential, err := uc.repository.FindTOTPCredential(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return credential.ConfirmedAt != nil, nil
+}
+
+// mfaChallenge returns the short-lived token of the second login step.
+func (uc *authUsecase) mfaChallenge(user *entities.User) (*dto.LoginResponse, error) {
+	if user.DisabledAt != nil {
+		return nil, errAccountDisabled()
+	}
+	token, err := helper.GenerateActionToken(user, helper.PurposeMFA, uc.options.MFAChallengeTTL)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return &dto.LoginResponse{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresAt:   time.Now().Add(uc.options.MFAChallengeTTL),
+	}, nil
+}
@@ -0,0 +1,208 @@
+package usecases
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go-wishlist-api-2/drivers/mysql/mocks"
+	"go-wishlist-api-2/dto"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/lockout"
+	"go-wishlist-api-2/mailer"
+	"go-wishlist-api-2/repositories"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestAuthUsecase_Login_TwoFactor(t *testing.T) {
+	mockRepo := new(mocks.MockAuthRepository)
+	mockTokenRepo := new(mocks.MockTokenRepository)
+	uc := NewAuthUsecase(mockRepo, mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+	password, _ := helper.HashPassword("admin123")
+	verifiedAt := time.Now()
+	mockRepo.On("FindByEmail", "admin@example.com").Return(&entities.User{Id: 1, Email: "admin@example.com", Password: password, EmailVerifiedAt: &verifiedAt}, nil)
+	mockRepo.On("FindTOTPCredential", 1).Return(&entities.TOTPCredential{UserID: 1, Secret: "SECRET", ConfirmedAt: &verifiedAt}, nil)
+
+	response, err := uc.Login(&dto.UserRequest{Email: "admin@example.com", Password: "admin123"}, dto.ClientInfo{IP: "10.0.0.1"})
+	assert.NoError(t, err)
+	assert.True(t, response.MFARequired)
+	assert.Empty(t, response.Token)
+	claims, err := helper.ParseActionToken(response.MFAToken, helper.PurposeMFA)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", claims.Subject)
+	mockTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+func TestAuthUsecase_VerifyMFA(t *testing.T) {
+	secret, _ := helper.GenerateTOTPSecret()
+	confirmedAt := time.Now()
+	user := &entities.User{Id: 1, Email: "admin@example.com"}
+	mfaToken, _ := helper.GenerateActionToken(user, helper.PurposeMFA, time.Minute)
+	credential := &entities.TOTPCredential{UserID: 1, Secret: secret, ConfirmedAt: &confirmedAt}
+	setup := func(options AuthOptions) (*authUsecase, *mocks.MockAuthRepository, *mocks.MockTokenRepository, *mailer.Outbox) {
+		mockRepo := new(mocks.MockAuthRepository)
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		outbox := mailer.NewOutbox("")
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("FindTOTPCredential", 1).Return(credential, nil)
+		return NewAuthUsecase(mockRepo, mockTokenRepo, outbox, options), mockRepo, mockTokenRepo, outbox
+	}
+
+	t.Run("TOTP code", func(t *testing.T) {
+		uc, mockRepo, mockTokenRepo, _ := setup(AuthOptions{})
+		step := helper.TOTPStep(time.Now())
+		code, _ := helper.TOTPCode(secret, step)
+		mockRepo.On("UseTOTPStep", 1, step).Return(nil)
+		mockTokenRepo.On("CreateSession", mock.Anything).Return(&entities.Session{ID: 3}, nil)
+		mockTokenRepo.On("CreateRefreshToken", mock.Anything).Return(&entities.RefreshToken{ID: 1}, nil)
+		response, err := uc.VerifyMFA(&dto.MFALoginRequest{MFAToken: mfaToken, Code: code}, dto.ClientInfo{IP: "10.0.0.1"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		assert.False(t, response.MFARequired)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reused TOTP code", func(t *testing.T) {
+		uc, mockRepo, _, _ := setup(AuthOptions{})
+		code, _ := helper.TOTPCode(secret, helper.TOTPStep(time.Now()))
+		mockRepo.On("UseTOTPStep", 1, mock.Anything).Return(repositories.ErrTOTPCodeReused)
+		_, err := uc.VerifyMFA(&dto.MFALoginRequest{MFAToken: mfaToken, Code: code}, dto.ClientInfo{IP: "10.0.0.1"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Recovery code", func(t *testing.T) {
+		uc, mockRepo, mockTokenRepo, outbox := setup(AuthOptions{})
+		mockRepo.On("UseRecoveryCode", 1, helper.HashToken("abcdefghijklmnop")).Return(nil)
+		mockTokenRepo.On("CreateSession", mock.Anything).Return(&entities.Session{ID: 3}, nil)
+		mockTokenRepo.On("CreateRefreshToken", mock.Anything).Return(&entities.RefreshToken{ID: 1}, nil)
+		response, err := uc.VerifyMFA(&dto.MFALoginRequest{MFAToken: mfaToken, Code: "ABCD-EFGH-IJKL-MNOP"}, dto.ClientInfo{IP: "10.0.0.1"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		message, ok := outbox.Last("admin@example.com")
+		assert.True(t, ok)
+		assert.Equal(t, "A recovery code was used", message.Subject)
+	})
+
+	t.Run("Wrong codes lock out", func(t *testing.T) {
+		policy := lockout.Policy{LockoutThreshold: 2, LockoutDuration: time.Hour}
+		uc, mockRepo, _, _ := setup(AuthOptions{AccountLimiter: lockout.NewLimiter(lockout.NewMemoryStore(), policy)})
+		mockRepo.On("UseRecoveryCode", 1, mock.Anything).Return(repositories.ErrRecoveryCodeInvalid)
+		for i := 0; i < 2; i++ {
+			_, err := uc.VerifyMFA(&dto.MFALoginRequest{MFAToken: mfaToken, Code: "000000"}, dto.ClientInfo{IP: "10.0.0.1"})
+			assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		}
+		_, err := uc.VerifyMFA(&dto.MFALoginRequest{MFAToken: mfaToken, Code: "000000"}, dto.ClientInfo{IP: "10.0.0.1"})
+		assert.IsType(t, &errorHandler.TooManyRequestsError{}, err)
+	})
+
+	t.Run("Wrong token purpose", func(t *testing.T) {
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		verify, _ := helper.GenerateActionToken(user, helper.PurposeVerifyEmail, time.Minute)
+		_, err := uc.VerifyMFA(&dto.MFALoginRequest{MFAToken: verify, Code: "000000"}, dto.ClientInfo{IP: "10.0.0.1"})
+		assert.IsType(t, &errorHandler.UnAuthorizedError{}, err)
+	})
+}
+
+func TestAuthUsecase_EnrollTOTP(t *testing.T) {
+	claims := &helper.JWTClaims{Id: 1}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("FindTOTPCredential", 1).Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("SaveTOTPCredential", mock.MatchedBy(func(c *entities.TOTPCredential) bool {
+			return c.UserID == 1 && c.Secret != "" && c.ConfirmedAt == nil
+		})).Return(nil)
+		enrollment, err := uc.EnrollTOTP(claims)
+		assert.NoError(t, err)
+		assert.Contains(t, enrollment.URI, "otpauth://totp/Wishlist:admin@example.com?")
+		assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Already enabled", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		confirmedAt := time.Now()
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("FindTOTPCredential", 1).Return(&entities.TOTPCredential{UserID: 1, ConfirmedAt: &confirmedAt}, nil)
+		_, err := uc.EnrollTOTP(claims)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "SaveTOTPCredential", mock.Anything)
+	})
+}
+
+func TestAuthUsecase_ConfirmTOTP(t *testing.T) {
+	claims := &helper.JWTClaims{Id: 1}
+	secret, _ := helper.GenerateTOTPSecret()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("FindTOTPCredential", 1).Return(&entities.TOTPCredential{UserID: 1, Secret: secret}, nil)
+		step := helper.TOTPStep(time.Now())
+		var stored []*entities.RecoveryCode
+		mockRepo.On("ConfirmTOTPCredential", mock.MatchedBy(func(c *entities.TOTPCredential) bool {
+			return c.ConfirmedAt != nil && c.LastUsedStep == step
+		}), mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).([]*entities.RecoveryCode)
+		}).Return(nil)
+		code, _ := helper.TOTPCode(secret, step)
+		response, err := uc.ConfirmTOTP(claims, &dto.CodeRequest{Code: code})
+		assert.NoError(t, err)
+		assert.Len(t, response.RecoveryCodes, recoveryCodeCount)
+		assert.Len(t, stored, recoveryCodeCount)
+		assert.Equal(t, helper.HashToken(helper.NormalizeRecoveryCode(response.RecoveryCodes[0])), stored[0].CodeHash)
+		_, ok := outbox.Last("admin@example.com")
+		assert.True(t, ok)
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("FindTOTPCredential", 1).Return(&entities.TOTPCredential{UserID: 1, Secret: secret}, nil)
+		_, err := uc.ConfirmTOTP(claims, &dto.CodeRequest{Code: "abcdef"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "ConfirmTOTPCredential", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Not enrolled", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("FindTOTPCredential", 1).Return(nil, gorm.ErrRecordNotFound)
+		_, err := uc.ConfirmTOTP(claims, &dto.CodeRequest{Code: "123456"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
+
+func TestAuthUsecase_DisableTOTP(t *testing.T) {
+	claims := &helper.JWTClaims{Id: 1}
+	password, _ := helper.HashPassword("password")
+	confirmedAt := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", Password: password}, nil)
+		mockRepo.On("FindTOTPCredential", 1).Return(&entities.TOTPCredential{UserID: 1, ConfirmedAt: &confirmedAt}, nil)
+		mockRepo.On("DeleteTOTPCredential", 1).Return(nil)
+		assert.NoError(t, uc.DisableTOTP(claims, &dto.PasswordRequest{Password: "password"}))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", Password: password}, nil)
+		err := uc.DisableTOTP(claims, &dto.PasswordRequest{Password: "wrong"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "DeleteTOTPCredential", mock.Anything)
+	})
+}
@@ -1,29 +1,154 @@
 package usecases
 
 import (
+	"errors"
+	"fmt"
 	"go-wishlist-api-2/dto"
 	"go-wishlist-api-2/entities"
 	"go-wishlist-api-2/errorHandler"
 	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/lockout"
+	"go-wishlist-api-2/mailer"
+	"go-wishlist-api-2/oidc"
+	"go-wishlist-api-2/passwordpolicy"
 	"go-wishlist-api-2/repositories"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type AuthUsecase interface {
-	Register(request *dto.UserRequest) (*entities.User, error)
-	Login(request *dto.UserRequest) (*dto.LoginResponse, error)
+	Register(request *dto.UserRequest) (*dto.UserResponse, error)
+	Login(request *dto.UserRequest, client dto.ClientInfo) (*dto.LoginResponse, error)
+	Refresh(request *dto.RefreshRequest) (*dto.LoginResponse, error)
+	Logout(claims *helper.JWTClaims, request *dto.RefreshRequest) error
+	LogoutAll(claims *helper.JWTClaims) error
+	PurgeExpiredTokens() (int64, error)
+	VerifyEmail(token string) (*dto.UserResponse, error)
+	ResendVerification(request *dto.EmailRequest) error
+	ForgotPassword(request *dto.EmailRequest) error
+	ResetPassword(request *dto.PasswordResetRequest) error
+	ChangePassword(claims *helper.JWTClaims, request *dto.ChangePasswordRequest) error
+	ChangeEmail(claims *helper.JWTClaims, request *dto.ChangeEmailRequest) error
+	ConfirmEmailChange(token string) (*dto.UserResponse, error)
+	UnlockAccount(email string) error
+	VerifyMFA(request *dto.MFALoginRequest, client dto.ClientInfo) (*dto.LoginResponse, error)
+	EnrollTOTP(claims *helper.JWTClaims) (*dto.TOTPEnrollment, error)
+	ConfirmTOTP(claims *helper.JWTClaims, request *dto.CodeRequest) (*dto.RecoveryCodesResponse, error)
+	DisableTOTP(claims *helper.JWTClaims, request *dto.PasswordRequest) error
+	BeginOIDCLogin(provider string) (*dto.OIDCLoginRedirect, error)
+	CompleteOIDCLogin(provider, state, code string, client dto.ClientInfo) (*dto.LoginResponse, error)
+}
+
+// AuthOptions configures the account emails sent by the auth usecase. Zero
+// values fall back to the defaults below.
+type AuthOptions struct {
+	// AppURL is the public base URL used to build links in emails.
+	AppURL string
+	// VerificationTTL is how long an email verification link stays valid.
+	VerificationTTL time.Duration
+	// ResendInterval is the minimum time between two verification or
+	// password reset emails to the same account.
+	ResendInterval time.Duration
+	// PasswordResetTTL is how long a password reset link stays valid.
+	PasswordResetTTL time.Duration
+	// PasswordResetURL is the page password reset emails link to, with the
+	// token added as query parameter. It defaults to the form served at
+	// AppURL + "/password/reset".
+	PasswordResetURL string
+	// AccountLimiter and IPLimiter throttle failed logins per account and
+	// per client IP. They default to in-memory limiters with the default
+	// lockout policies.
+	AccountLimiter *lockout.Limiter
+	IPLimiter      *lockout.Limiter
+	// TOTPIssuer names the service in authenticator apps.
+	TOTPIssuer string
+	// MFAChallengeTTL is how long the second login step may take.
+	MFAChallengeTTL time.Duration
+	// PasswordPolicy is enforced on every new password. Defaults to
+	// passwordpolicy.DefaultPolicy.
+	PasswordPolicy *passwordpolicy.Policy
+	// OIDCProviders are the OpenID Connect providers users can sign in
+	// with, by the name used in the login URLs.
+	OIDCProviders map[string]*oidc.Provider
+	// OIDCLoginTTL is how long a sign-in at a provider may take.
+	OIDCLoginTTL time.Duration
 }
 
+const (
+	defaultAppURL           = "http://localhost:1323"
+	defaultVerificationTTL  = 24 * time.Hour
+	defaultResendInterval   = time.Minute
+	defaultPasswordResetTTL = time.Hour
+	defaultTOTPIssuer       = "Wishlist"
+	defaultMFAChallengeTTL  = 5 * time.Minute
+	defaultOIDCLoginTTL     = 10 * time.Minute
+)
+
 type authUsecase struct {
-	repository repositories.AuthRepository
+	repository      repositories.AuthRepository
+	tokenRepository repositories.TokenRepository
+	mailer          mailer.Mailer
+	options         AuthOptions
 }
 
-func NewAuthUsecase(repository repositories.AuthRepository) *authUsecase {
-	return &authUsecase{repository}
+func NewAuthUsecase(repository repositories.AuthRepository, tokenRepository repositories.TokenRepository, m mailer.Mailer, options AuthOptions) *authUsecase {
+	if options.AppURL == "" {
+		options.AppURL = defaultAppURL
+	}
+	if options.VerificationTTL <= 0 {
+		options.VerificationTTL = defaultVerificationTTL
+	}
+	if options.ResendInterval <= 0 {
+		options.ResendInterval = defaultResendInterval
+	}
+	if options.PasswordResetTTL <= 0 {
+		options.PasswordResetTTL = defaultPasswordResetTTL
+	}
+	if options.TOTPIssuer == "" {
+		options.TOTPIssuer = defaultTOTPIssuer
+	}
+	if options.MFAChallengeTTL <= 0 {
+		options.MFAChallengeTTL = defaultMFAChallengeTTL
+	}
+	if options.OIDCLoginTTL <= 0 {
+		options.OIDCLoginTTL = defaultOIDCLoginTTL
+	}
+	if options.PasswordPolicy == nil {
+		policy := passwordpolicy.DefaultPolicy
+		options.PasswordPolicy = &policy
+	}
+	if options.AccountLimiter == nil {
+		options.AccountLimiter = lockout.NewLimiter(lockout.NewMemoryStore(), lockout.DefaultAccountPolicy)
+	}
+	if options.IPLimiter == nil {
+		options.IPLimiter = lockout.NewLimiter(lockout.NewMemoryStore(), lockout.DefaultIPPolicy)
+	}
+	options.AppURL = strings.TrimRight(options.AppURL, "/")
+	if options.PasswordResetURL == "" {
+		options.PasswordResetURL = options.AppURL + "/password/reset"
+	}
+	return &authUsecase{repository, tokenRepository, m, options}
 }
 
-func (uc *authUsecase) Register(request *dto.UserRequest) (*entities.User, error) {
-	if request.Email == "" && request.Password == "" {
-		return nil, &errorHandler.BadRequestError{Message: "Field must be filled"}
+func (uc *authUsecase) Register(request *dto.UserRequest) (*dto.UserResponse, error) {
+	var fields []dto.FieldError
+	if request.Email == "" {
+		fields = append(fields, dto.FieldError{Field: "email", Message: "must be filled"})
+	} else if !validEmail(request.Email) {
+		fields = append(fields, dto.FieldError{Field: "email", Message: "must be a valid email address"})
+	}
+	passwordFields, err := uc.passwordProblems("password", request.Password, request.Email)
+	if err != nil {
+		return nil, err
+	}
+	if fields = append(fields, passwordFields...); len(fields) > 0 {
+		return nil, &errorHandler.BadRequestError{Message: "Register Failed: invalid fields", Fields: fields}
 	}
 
 	existingUser, _ := uc.repository.FindByEmail(request.Email)
@@ -37,34 +162,678 @@ func (uc *authUsecase) Register(request *dto.UserRequest) (*entities.User, error
 		return nil, &errorHandler.InternalServerError{Message: err.Error()}
 	}
 
+	now := time.Now()
 	user := &entities.User{
-		Email:    request.Email,
-		Password: hash,
+		Email:              request.Email,
+		Password:           hash,
+		VerificationSentAt: &now,
+		Role:               entities.RoleUser,
 	}
 
 	newUser, err := uc.repository.CreateUser(user)
 	if err != nil {
 		return nil, &errorHandler.InternalServerError{Message: err.Error()}
 	}
-	return newUser, nil
+	// The account exists at this point; a failed email can be resent.
+	if err := uc.sendVerification(newUser); err != nil {
+		log.Printf("sending verification email to user %d failed: %v", newUser.Id, err)
+	}
+	return toUserResponse(newUser), nil
 }
 
-func (uc *authUsecase) Login(request *dto.UserRequest) (*dto.LoginResponse, error) {
+// Login checks the credentials and issues a token pair. Failures are counted
+// per account and per client IP; both are slowed down and eventually locked
+// out. The error never tells whether the email exists.
+func (uc *authUsecase) Login(request *dto.UserRequest, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	accountKey := loginAccountKey(request.Email)
+	ipKey := "ip:" + client.IP
+	if err := uc.reserveLoginAttempt(accountKey, ipKey); err != nil {
+		return nil, err
+	}
 
+	invalid := &errorHandler.BadRequestError{Message: "Login Failed: invalid email or password"}
 	user, err := uc.repository.FindByEmail(request.Email)
 	if err != nil {
-		return nil, &errorHandler.BadRequestError{Message: "Login Failed: wrong email"}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		// Compare against a dummy hash so unknown emails take as long as
+		// wrong passwords.
+		helper.VerifyPassword(request.Password, dummyPasswordHash())
+		return nil, invalid
+	}
+
+	if err := helper.VerifyPassword(request.Password, user.Password); err != nil {
+		return nil, invalid
+	}
+	if err := uc.releaseLoginAttempt(accountKey, ipKey); err != nil {
+		return nil, err
+	}
+	uc.rehashPassword(user, request.Password)
+
+	if user.EmailVerifiedAt == nil {
+		return nil, &errorHandler.ForbiddenError{Message: "Login Failed: email is not verified"}
+	}
+
+	// With two-factor authentication the failure count is only reset once
+	// the second step succeeds, so that a known password does not give
+	// unlimited code guesses.
+	enabled, err := uc.totpEnabled(user.Id)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return uc.mfaChallenge(user)
+	}
+	if err := uc.options.AccountLimiter.Reset(accountKey); err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return uc.login(user, client)
+}
+
+// login starts a new session for user on the client and issues its first
+// token pair. Signing in cancels a pending deletion of the account.
+func (uc *authUsecase) login(user *entities.User, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	if user.DisabledAt != nil {
+		return nil, errAccountDisabled()
+	}
+	if user.DeletionScheduledAt != nil {
+		if err := uc.repository.CancelAccountDeletion(user.Id); err != nil {
+			return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		user.DeletionScheduledAt = nil
+	}
+	now := time.Now()
+	var response *dto.LoginResponse
+	err := uc.tokenRepository.CreateSession(&entities.Session{
+		UserID:     user.Id,
+		UserAgent:  truncate(client.UserAgent, maxUserAgentLen),
+		IP:         client.IP,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(helper.RefreshTokenTTL()),
+	}, func(session *entities.Session) (*entities.RefreshToken, error) {
+		var refreshToken *entities.RefreshToken
+		var err error
+		response, refreshToken, err = issueTokens(user, session.ID)
+		return refreshToken, err
+	})
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return response, nil
+}
+
+// UnlockAccount lifts the login lockout of the account with the given email.
+func (uc *authUsecase) UnlockAccount(email string) error {
+	if err := uc.options.AccountLimiter.Reset(loginAccountKey(email)); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+// Refresh exchanges a refresh token for a new access/refresh token pair. The
+// presented token is revoked; presenting it again is treated as token theft
+// and revokes every session of the user.
+func (uc *authUsecase) Refresh(request *dto.RefreshRequest) (*dto.LoginResponse, error) {
+	if request.RefreshToken == "" {
+		return nil, &errorHandler.BadRequestError{Message: "Refresh token is required"}
+	}
+	stored, err := uc.findRefreshToken(request.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if stored.RevokedAt != nil {
+		if err := uc.tokenRepository.RevokeAllForUser(stored.UserID); err != nil {
+			return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		return nil, &errorHandler.UnAuthorizedError{Message: "Refresh token has been revoked"}
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, &errorHandler.UnAuthorizedError{Message: "Refresh token has expired"}
+	}
+
+	user, err := uc.repository.FindByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.UnAuthorizedError{Message: "Invalid refresh token"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if user.DisabledAt != nil {
+		return nil, errAccountDisabled()
+	}
+	response, next, err := issueTokens(user, stored.SessionID)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if _, err := uc.tokenRepository.RotateRefreshToken(stored, next); err != nil {
+		if errors.Is(err, repositories.ErrRefreshTokenRevoked) {
+			return nil, &errorHandler.UnAuthorizedError{Message: "Refresh token has been revoked"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return response, nil
+}
+
+// Logout revokes the access token in claims and, if given, the refresh token
+// of the same session.
+func (uc *authUsecase) Logout(claims *helper.JWTClaims, request *dto.RefreshRequest) error {
+	if request.RefreshToken != "" {
+		stored, err := uc.findRefreshToken(request.RefreshToken)
+		if err != nil {
+			return err
+		}
+		if stored.UserID != claims.Id {
+			return &errorHandler.ForbiddenError{Message: "Refresh token belongs to another user"}
+		}
+		if err := uc.tokenRepository.RevokeRefreshToken(stored); err != nil {
+			return &errorHandler.InternalServerError{Message: err.Error()}
+		}
+	}
+	return uc.denyAccessToken(claims)
+}
+
+// LogoutAll revokes every refresh token of the user and the access tokens
+// issued with them.
+func (uc *authUsecase) LogoutAll(claims *helper.JWTClaims) error {
+	if err := uc.tokenRepository.RevokeAllForUser(claims.Id); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return uc.denyAccessToken(claims)
+}
+
+func (uc *authUsecase) PurgeExpiredTokens() (int64, error) {
+	purged, err := uc.tokenRepository.PurgeExpiredTokens(time.Now())
+	if err != nil {
+		return 0, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return purged, nil
+}
+
+// VerifyEmail marks the account the token was issued for as verified. The
+// token is only valid for the address it was sent to.
+func (uc *authUsecase) VerifyEmail(token string) (*dto.UserResponse, error) {
+	invalid := &errorHandler.BadRequestError{Message: "Invalid or expired verification token"}
+	claims, err := helper.ParseActionToken(token, helper.PurposeVerifyEmail)
+	if err != nil {
+		return nil, invalid
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, invalid
+	}
+	user, err := uc.repository.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if !strings.EqualFold(user.Email, claims.Email) {
+		return nil, invalid
+	}
+	if user.EmailVerifiedAt != nil {
+		return toUserResponse(user), nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	updated, err := uc.repository.UpdateUser(user)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return toUserResponse(updated), nil
+}
+
+// ResendVerification sends a new verification email unless the previous one
+// was sent less than ResendInterval ago. To not reveal which addresses are
+// registered, unknown, already verified and throttled addresses are silently
+// ignored and failures to send are only logged.
+func (uc *authUsecase) ResendVerification(request *dto.EmailRequest) error {
+	user, err := uc.repository.FindByEmail(request.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < uc.options.ResendInterval {
+		return nil
+	}
+
+	now := time.Now()
+	user.VerificationSentAt = &now
+	if _, err := uc.repository.UpdateUser(user); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if err := uc.sendVerification(user); err != nil {
+		log.Printf("sending verification email to user %d failed: %v", user.Id, err)
+	}
+	return nil
+}
+
+// ForgotPassword emails a password reset link. To not reveal which addresses
+// are registered it succeeds for unknown addresses and silently skips
+// accounts that were sent a link less than ResendInterval ago.
+func (uc *authUsecase) ForgotPassword(request *dto.EmailRequest) error {
+	user, err := uc.repository.FindByEmail(request.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	recent, err := uc.repository.CountPasswordResetTokensSince(user.Id, time.Now().Add(-uc.options.ResendInterval))
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	token, err := helper.GenerateResetToken()
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	_, err = uc.repository.CreatePasswordResetToken(&entities.PasswordResetToken{
+		UserID:    user.Id,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(uc.options.PasswordResetTTL),
+	})
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+
+	separator := "?"
+	if strings.Contains(uc.options.PasswordResetURL, "?") {
+		separator = "&"
+	}
+	link := uc.options.PasswordResetURL + separator + "token=" + url.QueryEscape(token)
+	err = uc.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your Wishlist account.\n\nOpen the link below to choose a new password:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not ask for this, you can ignore this email.\n",
+			link, uc.options.PasswordResetTTL),
+	})
+	// Failing here would tell registered addresses apart from unknown ones.
+	if err != nil {
+		log.Printf("sending password reset email to user %d failed: %v", user.Id, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword. The
+// token can only be used once, every session of the user is revoked and the
+// user's personal access tokens are deleted, since a reset usually means the
+// old password can no longer be trusted.
+func (uc *authUsecase) ResetPassword(request *dto.PasswordResetRequest) error {
+	invalid := &errorHandler.BadRequestError{Message: "Invalid or expired reset token"}
+	token, err := uc.repository.FindPasswordResetTokenByHash(helper.HashToken(request.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid
+		}
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return invalid
+	}
+	user, err := uc.repository.FindByID(token.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid
+		}
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if err := uc.validatePassword("password", request.Password, user.Email); err != nil {
+		return err
+	}
+
+	hash, err := helper.HashPassword(request.Password)
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	user.Password = hash
+	// Following the emailed link proves ownership of the address.
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := uc.repository.ResetPassword(token, user); err != nil {
+		if errors.Is(err, repositories.ErrResetTokenUsed) {
+			return invalid
+		}
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+// ChangePassword replaces the password of the signed in user after checking
+// the current one. Every other session of the user is signed out and the
+// user is notified by email. Personal access tokens are only deleted when the
+// request asks for it.
+func (uc *authUsecase) ChangePassword(claims *helper.JWTClaims, request *dto.ChangePasswordRequest) error {
+	if request.CurrentPassword == "" || request.NewPassword == "" {
+		return &errorHandler.BadRequestError{Message: "Current and new password must be filled"}
+	}
+	user, err := uc.currentUser(claims)
+	if err != nil {
+		return err
+	}
+	if err := helper.VerifyPassword(request.CurrentPassword, user.Password); err != nil {
+		return &errorHandler.BadRequestError{Message: "Current password is wrong"}
+	}
+	if err := uc.validatePassword("new_password", request.NewPassword, user.Email); err != nil {
+		return err
+	}
+
+	hash, err := helper.HashPassword(request.NewPassword)
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
 	}
+	user.Password = hash
+	if err := uc.repository.UpdatePassword(user, claims.StandardClaims.Id, request.RevokePersonalAccessTokens); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	uc.notify(user, "Your password was changed",
+		"The password of your Wishlist account was just changed and your other sessions were signed out.\n\nIf you did not do this, reset your password right away. Resetting it also deletes your personal access tokens.\n")
+	return nil
+}
 
+// ChangeEmail starts changing the address of the signed in user. The new
+// address only replaces the current one once the link sent to it is opened;
+// the current address is notified about the request.
+func (uc *authUsecase) ChangeEmail(claims *helper.JWTClaims, request *dto.ChangeEmailRequest) error {
+	if !validEmail(request.Email) {
+		return &errorHandler.BadRequestError{Message: "Invalid email address"}
+	}
+	user, err := uc.currentUser(claims)
+	if err != nil {
+		return err
+	}
 	if err := helper.VerifyPassword(request.Password, user.Password); err != nil {
-		return nil, &errorHandler.BadRequestError{Message: "Login Failed: wrong password"}
+		return &errorHandler.BadRequestError{Message: "Password is wrong"}
+	}
+	if strings.EqualFold(user.Email, request.Email) {
+		return &errorHandler.BadRequestError{Message: "Email is already the current email"}
+	}
+	if err := uc.ensureEmailAvailable(request.Email, user.Id); err != nil {
+		return err
 	}
 
-	token, err := helper.GenerateToken(user)
+	user.PendingEmail = &request.Email
+	if _, err := uc.repository.UpdateUser(user); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	token, err := helper.GenerateEmailChangeToken(user, request.Email, uc.options.VerificationTTL)
 	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	link := uc.options.AppURL + "/email/confirm?token=" + url.QueryEscape(token)
+	err = uc.mailer.Send(mailer.Message{
+		To:      request.Email,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Please confirm the new email address of your Wishlist account by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			link, uc.options.VerificationTTL),
+	})
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	uc.notify(user, "Email change requested",
+		fmt.Sprintf("Someone asked to change the email address of your Wishlist account to %s. The change only takes effect once the new address is confirmed.\n\nIf you did not do this, change your password right away.\n", request.Email))
+	return nil
+}
+
+// ConfirmEmailChange replaces the user's address with the pending address the
+// token was sent to. Only the most recently requested address can be
+// confirmed, and only once.
+func (uc *authUsecase) ConfirmEmailChange(token string) (*dto.UserResponse, error) {
+	invalid := &errorHandler.BadRequestError{Message: "Invalid or expired confirmation token"}
+	claims, err := helper.ParseActionToken(token, helper.PurposeChangeEmail)
+	if err != nil {
+		return nil, invalid
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, invalid
+	}
+	user, err := uc.repository.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
 		return nil, &errorHandler.InternalServerError{Message: err.Error()}
 	}
+	if user.PendingEmail == nil || *user.PendingEmail != claims.Email {
+		return nil, invalid
+	}
+	if err := uc.ensureEmailAvailable(claims.Email, user.Id); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repository.UpdateEmail(user.Id, claims.Email); err != nil {
+		if errors.Is(err, repositories.ErrEmailChangeStale) {
+			return nil, invalid
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	previous := *user
+	now := time.Now()
+	user.Email = claims.Email
+	user.PendingEmail = nil
+	user.EmailVerifiedAt = &now
+	uc.notify(&previous, "Your email address was changed",
+		fmt.Sprintf("The email address of your Wishlist account was changed to %s.\n\nIf you did not do this, contact support right away.\n", claims.Email))
+	return toUserResponse(user), nil
+}
+
+func loginAccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
 
-	response := dto.LoginResponse{token}
-	return &response, nil
+// reserveLoginAttempt counts a login attempt against both keys before the
+// credentials are checked, so that parallel guesses cannot all pass the
+// limits before the first of them failed. It returns a TooManyRequestsError,
+// counting nothing, if either key has to wait.
+func (uc *authUsecase) reserveLoginAttempt(accountKey, ipKey string) error {
+	tooMany := func(wait time.Duration) error {
+		return &errorHandler.TooManyRequestsError{
+			Message:    "Too many failed login attempts, please try again later",
+			RetryAfter: wait,
+		}
+	}
+	wait, err := uc.options.AccountLimiter.Reserve(accountKey)
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if wait > 0 {
+		return tooMany(wait)
+	}
+	wait, err = uc.options.IPLimiter.Reserve(ipKey)
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if wait > 0 {
+		if err := uc.options.AccountLimiter.Release(accountKey); err != nil {
+			return &errorHandler.InternalServerError{Message: err.Error()}
+		}
+		return tooMany(wait)
+	}
+	return nil
+}
+
+// releaseLoginAttempt takes back the attempt reserveLoginAttempt counted once
+// the credentials turned out to be right.
+func (uc *authUsecase) releaseLoginAttempt(accountKey, ipKey string) error {
+	if err := uc.options.AccountLimiter.Release(accountKey); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if err := uc.options.IPLimiter.Release(ipKey); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+// rehashPassword upgrades the stored hash of user to the configured hasher
+// and parameters. The login goes on even if that fails.
+func (uc *authUsecase) rehashPassword(user *entities.User, password string) {
+	if !helper.PasswordNeedsRehash(user.Password) {
+		return
+	}
+	hash, err := helper.HashPassword(password)
+	if err != nil {
+		log.Printf("rehashing password of user %d failed: %v", user.Id, err)
+		return
+	}
+	if err := uc.repository.UpdatePasswordHash(user.Id, user.Password, hash); err != nil {
+		log.Printf("rehashing password of user %d failed: %v", user.Id, err)
+		return
+	}
+	user.Password = hash
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = helper.HashPassword("dummy password")
+	})
+	return dummyHash
+}
+
+func errAccountDisabled() error {
+	return &errorHandler.ForbiddenError{Message: "Account is disabled"}
+}
+
+func (uc *authUsecase) currentUser(claims *helper.JWTClaims) (*entities.User, error) {
+	user, err := uc.repository.FindByID(claims.Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.UnAuthorizedError{Message: "User no longer exists"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return user, nil
+}
+
+func (uc *authUsecase) ensureEmailAvailable(email string, userID int) error {
+	existing, err := uc.repository.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if existing.Id != userID {
+		return &errorHandler.BadRequestError{Message: "Email already used"}
+	}
+	return nil
+}
+
+// notify sends a security notification to the user's current address. The
+// change it reports has already happened, so a failure is only logged.
+func (uc *authUsecase) notify(user *entities.User, subject, body string) {
+	err := uc.mailer.Send(mailer.Message{To: user.Email, Subject: subject, Body: body})
+	if err != nil {
+		log.Printf("sending %q notification to user %d failed: %v", subject, user.Id, err)
+	}
+}
+
+func (uc *authUsecase) sendVerification(user *entities.User) error {
+	token, err := helper.GenerateActionToken(user, helper.PurposeVerifyEmail, uc.options.VerificationTTL)
+	if err != nil {
+		return err
+	}
+	link := uc.options.AppURL + "/verify-email?token=" + url.QueryEscape(token)
+	return uc.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Welcome to Wishlist!\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			link, uc.options.VerificationTTL),
+	})
+}
+
+// validatePassword returns a BadRequestError listing every requirement of the
+// password policy that the new password in field does not meet.
+func (uc *authUsecase) validatePassword(field, password, email string) error {
+	fields, err := uc.passwordProblems(field, password, email)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		return &errorHandler.BadRequestError{Message: "Password does not meet the requirements", Fields: fields}
+	}
+	return nil
+}
+
+func (uc *authUsecase) passwordProblems(field, password, email string) ([]dto.FieldError, error) {
+	problems, err := uc.options.PasswordPolicy.Validate(password, email)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	fields := make([]dto.FieldError, len(problems))
+	for i, problem := range problems {
+		fields[i] = dto.FieldError{Field: field, Message: problem}
+	}
+	return fields, nil
+}
+
+func validEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+func (uc *authUsecase) findRefreshToken(token string) (*entities.RefreshToken, error) {
+	stored, err := uc.tokenRepository.FindRefreshTokenByHash(helper.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.UnAuthorizedError{Message: "Invalid refresh token"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return stored, nil
+}
+
+func (uc *authUsecase) denyAccessToken(claims *helper.JWTClaims) error {
+	if claims.StandardClaims.Id == "" {
+		return nil
+	}
+	err := uc.tokenRepository.DenyAccessToken(claims.StandardClaims.Id, time.Unix(claims.ExpiresAt, 0))
+	if err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+// issueTokens creates a token pair of the session. The refresh token is
+// returned for the caller to store.
+func issueTokens(user *entities.User, sessionID uint) (*dto.LoginResponse, *entities.RefreshToken, error) {
+	accessToken, err := helper.GenerateToken(user, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	refreshToken, err := helper.GenerateRefreshToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	stored := &entities.RefreshToken{
+		UserID:          user.Id,
+		SessionID:       sessionID,
+		TokenHash:       helper.HashToken(refreshToken),
+		AccessJTI:       accessToken.JTI,
+		AccessExpiresAt: accessToken.ExpiresAt,
+		ExpiresAt:       time.Now().Add(helper.RefreshTokenTTL()),
+	}
+	response := &dto.LoginResponse{
+		Token:        accessToken.Token,
+		ExpiresAt:    accessToken.ExpiresAt,
+		RefreshToken: refreshToken,
+	}
+	return response, stored, nil
 }
@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go-wishlist-api-2/drivers/mysql/mocks"
@@ -9,7 +10,18 @@ import (
 	"go-wishlist-api-2/entities"
 	"go-wishlist-api-2/errorHandler"
 	"go-wishlist-api-2/helper"
+	"go-wishlist-api-2/lockout"
+	"go-wishlist-api-2/mailer"
+	"go-wishlist-api-2/passwordpolicy"
+	"go-wishlist-api-2/repositories"
+	"gorm.io/gorm"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestAuthUsecase_Register(t *testing.T) {
@@ -25,29 +37,76 @@ func TestAuthUsecase_Register(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 
 		mockRepo := new(mocks.MockAuthRepository)
-		uc := NewAuthUsecase(mockRepo)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{AppURL: "https://wishlist.example/"})
 
 		mockRepo.On("FindByEmail", "admin@example.com").Return(nil, nil)
-		mockRepo.On("CreateUser", mock.Anything).Return(&expectedUser, nil)
+		mockRepo.On("CreateUser", mock.MatchedBy(func(user *entities.User) bool {
+			return user.EmailVerifiedAt == nil && user.VerificationSentAt != nil
+		})).Return(&expectedUser, nil)
 		newUser, err := uc.Register(req)
 		assert.NoError(t, err)
 		assert.Equal(t, newUser.Email, req.Email)
+
+		message, ok := outbox.Last("admin@example.com")
+		assert.True(t, ok)
+		assert.Contains(t, message.Body, "https://wishlist.example/verify-email?token=")
+	})
+
+	t.Run("Invalid email", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+
+		newUser, err := uc.Register(&dto.UserRequest{Email: "admin\r\nBcc: x@example.com", Password: "admin123"})
+		assert.Nil(t, newUser)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+	})
+
+	t.Run("Weak password", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+
+		newUser, err := uc.Register(&dto.UserRequest{Email: "admin@example.com", Password: "a"})
+		assert.Nil(t, newUser)
+		var badRequest *errorHandler.BadRequestError
+		assert.ErrorAs(t, err, &badRequest)
+		assert.Equal(t, []dto.FieldError{
+			{Field: "password", Message: "must be at least 8 characters long"},
+			{Field: "password", Message: "must contain at least 2 of: lowercase letters, uppercase letters, digits, symbols"},
+		}, badRequest.Fields)
+		mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+	})
+
+	t.Run("Breached password", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		policy := passwordpolicy.DefaultPolicy
+		policy.Breached = breachedPasswords{"admin123": true}
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{PasswordPolicy: &policy})
+
+		_, err := uc.Register(req)
+		var badRequest *errorHandler.BadRequestError
+		assert.ErrorAs(t, err, &badRequest)
+		assert.Len(t, badRequest.Fields, 1)
+		assert.Equal(t, "password", badRequest.Fields[0].Field)
 	})
 
 	t.Run("Request is empty", func(t *testing.T) {
 		mockRepo := new(mocks.MockAuthRepository)
-		uc := NewAuthUsecase(mockRepo)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
 
 		req := &dto.UserRequest{}
 		newUser, err := uc.Register(req)
 		assert.Error(t, err)
 		assert.Nil(t, newUser)
-		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		var badRequest *errorHandler.BadRequestError
+		assert.ErrorAs(t, err, &badRequest)
+		assert.Equal(t, "email", badRequest.Fields[0].Field)
 	})
 
 	t.Run("Email already used", func(t *testing.T) {
 		mockRepo := new(mocks.MockAuthRepository)
-		uc := NewAuthUsecase(mockRepo)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
 
 		user := &entities.User{
 			Id:       1,
@@ -65,7 +124,7 @@ func TestAuthUsecase_Register(t *testing.T) {
 
 	t.Run("Failed Register", func(t *testing.T) {
 		mockRepo := new(mocks.MockAuthRepository)
-		uc := NewAuthUsecase(mockRepo)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
 
 		expectedError := errors.New("Internal Server Error")
 		mockRepo.On("FindByEmail", "admin@example.com").Return(nil, nil)
@@ -78,6 +137,12 @@ func TestAuthUsecase_Register(t *testing.T) {
 
 }
 
+type breachedPasswords map[string]bool
+
+func (b breachedPasswords) IsBreached(password string) (bool, error) {
+	return b[password], nil
+}
+
 func TestAuthUsecase_Login(t *testing.T) {
 
 	const (
@@ -87,41 +152,143 @@ func TestAuthUsecase_Login(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		mockRepo := new(mocks.MockAuthRepository)
-		uc := NewAuthUsecase(mockRepo)
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(mockRepo, mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
 		password, _ := helper.HashPassword(testPassword)
+		verifiedAt := time.Now()
 		expectedUser := &entities.User{
-			Email:    testEmail,
-			Password: password,
+			Id:              1,
+			Email:           testEmail,
+			Password:        password,
+			EmailVerifiedAt: &verifiedAt,
 		}
 		mockRepo.On("FindByEmail", testEmail).Return(expectedUser, nil)
+		mockRepo.On("FindTOTPCredential", 1).Return(nil, gorm.ErrRecordNotFound)
+		mockTokenRepo.On("CreateSession", mock.MatchedBy(func(session *entities.Session) bool {
+			return session.UserID == 1 && session.IP == "10.0.0.1" && session.UserAgent == "wishlist-cli/1.0" && session.ExpiresAt.After(time.Now())
+		})).Return(&entities.Session{ID: 3, UserID: 1}, nil)
+		mockTokenRepo.On("CreateRefreshToken", mock.MatchedBy(func(token *entities.RefreshToken) bool {
+			return token.UserID == 1 && token.SessionID == 3 && len(token.TokenHash) == 64 && token.AccessJTI != ""
+		})).Return(&entities.RefreshToken{ID: 1}, nil)
 		req := &dto.UserRequest{
 			Email:    testEmail,
 			Password: testPassword,
 		}
-		user, err := uc.Login(req)
+		user, err := uc.Login(req, dto.ClientInfo{IP: "10.0.0.1", UserAgent: "wishlist-cli/1.0"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, user.Token)
+		assert.NotEmpty(t, user.RefreshToken)
+		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Outdated hash is upgraded", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(mockRepo, mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		oldHash, _ := helper.BcryptHasher{Cost: bcrypt.MinCost}.Hash(testPassword)
+		verifiedAt := time.Now()
+		expectedUser := &entities.User{
+			Id:              1,
+			Email:           testEmail,
+			Password:        oldHash,
+			EmailVerifiedAt: &verifiedAt,
+		}
+		mockRepo.On("FindByEmail", testEmail).Return(expectedUser, nil)
+		mockRepo.On("UpdatePasswordHash", 1, oldHash, mock.MatchedBy(func(hash string) bool {
+			return !helper.PasswordNeedsRehash(hash) && helper.VerifyPassword(testPassword, hash) == nil
+		})).Return(nil)
+		mockRepo.On("FindTOTPCredential", 1).Return(nil, gorm.ErrRecordNotFound)
+		mockTokenRepo.On("CreateSession", mock.Anything).Return(&entities.Session{ID: 3}, nil)
+		mockTokenRepo.On("CreateRefreshToken", mock.Anything).Return(&entities.RefreshToken{ID: 1}, nil)
+		user, err := uc.Login(&dto.UserRequest{Email: testEmail, Password: testPassword}, dto.ClientInfo{IP: "10.0.0.1"})
 		assert.NoError(t, err)
-		assert.NotEmpty(t, user)
+		assert.NotEmpty(t, user.Token)
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("Wrong Email", func(t *testing.T) {
+	t.Run("Failed rehash does not fail login", func(t *testing.T) {
 		mockRepo := new(mocks.MockAuthRepository)
-		uc := NewAuthUsecase(mockRepo)
-		expectedError := errors.New("Login Failed: wrong email")
-		mockRepo.On("FindByEmail", "test@example.com").Return(nil, expectedError)
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(mockRepo, mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		oldHash, _ := helper.BcryptHasher{Cost: bcrypt.MinCost}.Hash(testPassword)
+		verifiedAt := time.Now()
+		mockRepo.On("FindByEmail", testEmail).Return(&entities.User{
+			Id:              1,
+			Email:           testEmail,
+			Password:        oldHash,
+			EmailVerifiedAt: &verifiedAt,
+		}, nil)
+		mockRepo.On("UpdatePasswordHash", 1, oldHash, mock.Anything).Return(errors.New("db down"))
+		mockRepo.On("FindTOTPCredential", 1).Return(nil, gorm.ErrRecordNotFound)
+		mockTokenRepo.On("CreateSession", mock.Anything).Return(&entities.Session{ID: 3}, nil)
+		mockTokenRepo.On("CreateRefreshToken", mock.Anything).Return(&entities.RefreshToken{ID: 1}, nil)
+		user, err := uc.Login(&dto.UserRequest{Email: testEmail, Password: testPassword}, dto.ClientInfo{IP: "10.0.0.1"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, user.Token)
+	})
+
+	t.Run("Email not verified", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(mockRepo, mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		password, _ := helper.HashPassword(testPassword)
+		mockRepo.On("FindByEmail", testEmail).Return(&entities.User{Id: 1, Email: testEmail, Password: password}, nil)
+		response, err := uc.Login(&dto.UserRequest{Email: testEmail, Password: testPassword}, dto.ClientInfo{IP: "10.0.0.1"})
+		assert.Nil(t, response)
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		mockTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+	})
+
+	t.Run("Disabled account", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(mockRepo, mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		password, _ := helper.HashPassword(testPassword)
+		now := time.Now()
+		mockRepo.On("FindByEmail", testEmail).Return(&entities.User{Id: 1, Email: testEmail, Password: password, EmailVerifiedAt: &now, DisabledAt: &now}, nil)
+		mockRepo.On("FindTOTPCredential", 1).Return(nil, gorm.ErrRecordNotFound)
+		response, err := uc.Login(&dto.UserRequest{Email: testEmail, Password: testPassword}, dto.ClientInfo{IP: "10.0.0.1"})
+		assert.Nil(t, response)
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		mockTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+	})
+
+	t.Run("Cancels a pending deletion", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(mockRepo, mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		password, _ := helper.HashPassword(testPassword)
+		now := time.Now()
+		scheduledFor := now.Add(24 * time.Hour)
+		mockRepo.On("FindByEmail", testEmail).Return(&entities.User{Id: 1, Email: testEmail, Password: password, EmailVerifiedAt: &now, DeletionScheduledAt: &scheduledFor}, nil)
+		mockRepo.On("FindTOTPCredential", 1).Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("CancelAccountDeletion", 1).Return(nil)
+		mockTokenRepo.On("CreateSession", mock.Anything).Return(&entities.Session{ID: 3}, nil)
+		mockTokenRepo.On("CreateRefreshToken", mock.Anything).Return(&entities.RefreshToken{ID: 1}, nil)
+		response, err := uc.Login(&dto.UserRequest{Email: testEmail, Password: testPassword}, dto.ClientInfo{IP: "10.0.0.1"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Email", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByEmail", "test@example.com").Return(nil, gorm.ErrRecordNotFound)
 		req := &dto.UserRequest{
 			Email:    "test@example.com",
 			Password: testPassword,
 		}
-		_, err := uc.Login(req)
+		_, err := uc.Login(req, dto.ClientInfo{IP: "10.0.0.1"})
 		assert.Error(t, err)
 		assert.IsType(t, &errorHandler.BadRequestError{}, err)
-		assert.Equal(t, "Login Failed: wrong email", err.Error())
+		assert.Equal(t, "Login Failed: invalid email or password", err.Error())
 	})
 
 	t.Run("Wrong Password", func(t *testing.T) {
 		mockRepo := new(mocks.MockAuthRepository)
-		uc := NewAuthUsecase(mockRepo)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
 		password, _ := helper.HashPassword(testPassword)
 		expectedUser := &entities.User{
 			Email:    testEmail,
@@ -132,11 +299,588 @@ func TestAuthUsecase_Login(t *testing.T) {
 			Email:    testEmail,
 			Password: "wrongpassword",
 		}
-		_, err := uc.Login(req)
-		expectedError := errors.New("Login Failed: wrong password")
+		_, err := uc.Login(req, dto.ClientInfo{IP: "10.0.0.1"})
+		expectedError := errors.New("Login Failed: invalid email or password")
 		assert.Error(t, err)
 		assert.IsType(t, &errorHandler.BadRequestError{}, err)
 		assert.EqualError(t, expectedError, err.Error())
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Account lockout", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		policy := lockout.Policy{FreeAttempts: 1, BaseDelay: time.Minute, LockoutThreshold: 3, LockoutDuration: time.Hour}
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{
+			AccountLimiter: lockout.NewLimiter(lockout.NewMemoryStore(), policy),
+		})
+		password, _ := helper.HashPassword(testPassword)
+		mockRepo.On("FindByEmail", testEmail).Return(&entities.User{Id: 1, Email: testEmail, Password: password}, nil).Once()
+		_, err := uc.Login(&dto.UserRequest{Email: testEmail, Password: "guess"}, dto.ClientInfo{IP: "10.0.0.1"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+
+		// The second failure past FreeAttempts has to wait BaseDelay, even
+		// with the right password and from another IP.
+		mockRepo.On("FindByEmail", testEmail).Return(&entities.User{Id: 1, Email: testEmail, Password: password}, nil).Once()
+		_, err = uc.Login(&dto.UserRequest{Email: testEmail, Password: "guess"}, dto.ClientInfo{IP: "10.0.0.2"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		_, err = uc.Login(&dto.UserRequest{Email: "ADMIN@example.com", Password: testPassword}, dto.ClientInfo{IP: "10.0.0.3"})
+		var tooMany *errorHandler.TooManyRequestsError
+		assert.ErrorAs(t, err, &tooMany)
+		// The attempt is counted before the password is checked, so the
+		// delay already started while the hash was being verified.
+		assert.Greater(t, tooMany.RetryAfter, 30*time.Second)
+		mockRepo.AssertNumberOfCalls(t, "FindByEmail", 2)
+
+		assert.NoError(t, uc.UnlockAccount(testEmail))
+		mockRepo.On("FindByEmail", testEmail).Return(&entities.User{Id: 1, Email: testEmail, Password: password}, nil).Once()
+		_, err = uc.Login(&dto.UserRequest{Email: testEmail, Password: testPassword}, dto.ClientInfo{IP: "10.0.0.3"})
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+	})
+
+	t.Run("Parallel guesses are locked out", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		policy := lockout.Policy{FreeAttempts: 2, BaseDelay: time.Minute, LockoutThreshold: 3, LockoutDuration: time.Hour}
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{
+			AccountLimiter: lockout.NewLimiter(lockout.NewMemoryStore(), policy),
+		})
+		password, _ := helper.HashPassword(testPassword)
+		mockRepo.On("FindByEmail", testEmail).Return(&entities.User{Id: 1, Email: testEmail, Password: password}, nil)
+
+		var wg sync.WaitGroup
+		var checked atomic.Int32
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := uc.Login(&dto.UserRequest{Email: testEmail, Password: "guess"}, dto.ClientInfo{IP: fmt.Sprintf("10.0.0.%d", i)})
+				if _, ok := err.(*errorHandler.BadRequestError); ok {
+					checked.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(policy.FreeAttempts+1), checked.Load())
+		_, err := uc.Login(&dto.UserRequest{Email: testEmail, Password: testPassword}, dto.ClientInfo{IP: "10.0.0.99"})
+		assert.IsType(t, &errorHandler.TooManyRequestsError{}, err)
+	})
+
+	t.Run("IP lockout", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		policy := lockout.Policy{LockoutThreshold: 2, LockoutDuration: time.Hour}
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{
+			IPLimiter: lockout.NewLimiter(lockout.NewMemoryStore(), policy),
+		})
+		mockRepo.On("FindByEmail", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+		uc.Login(&dto.UserRequest{Email: "a@example.com", Password: "guess"}, dto.ClientInfo{IP: "10.0.0.1"})
+		uc.Login(&dto.UserRequest{Email: "b@example.com", Password: "guess"}, dto.ClientInfo{IP: "10.0.0.1"})
+		_, err := uc.Login(&dto.UserRequest{Email: "c@example.com", Password: "guess"}, dto.ClientInfo{IP: "10.0.0.1"})
+		assert.IsType(t, &errorHandler.TooManyRequestsError{}, err)
+		_, err = uc.Login(&dto.UserRequest{Email: "c@example.com", Password: "guess"}, dto.ClientInfo{IP: "10.0.0.2"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
+
+func TestAuthUsecase_Refresh(t *testing.T) {
+	const refreshToken = "refresh-token"
+	user := &entities.User{Id: 1, Email: "admin@example.com"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(mockRepo, mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		stored := &entities.RefreshToken{ID: 1, UserID: 1, SessionID: 3, ExpiresAt: time.Now().Add(time.Hour)}
+		mockTokenRepo.On("FindRefreshTokenByHash", helper.HashToken(refreshToken)).Return(stored, nil)
+		mockRepo.On("FindByID", 1).Return(user, nil)
+		mockTokenRepo.On("RotateRefreshToken", stored, mock.MatchedBy(func(next *entities.RefreshToken) bool {
+			return next.UserID == 1 && next.SessionID == 3 && next.TokenHash != helper.HashToken(refreshToken)
+		})).Return(&entities.RefreshToken{ID: 2}, nil)
+		response, err := uc.Refresh(&dto.RefreshRequest{RefreshToken: refreshToken})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		assert.NotEqual(t, refreshToken, response.RefreshToken)
+		mockRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Disabled account", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(mockRepo, mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		stored := &entities.RefreshToken{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+		disabledAt := time.Now()
+		mockTokenRepo.On("FindRefreshTokenByHash", helper.HashToken(refreshToken)).Return(stored, nil)
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, DisabledAt: &disabledAt}, nil)
+		_, err := uc.Refresh(&dto.RefreshRequest{RefreshToken: refreshToken})
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		mockTokenRepo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		mockTokenRepo.On("FindRefreshTokenByHash", helper.HashToken(refreshToken)).Return(nil, gorm.ErrRecordNotFound)
+		response, err := uc.Refresh(&dto.RefreshRequest{RefreshToken: refreshToken})
+		assert.Nil(t, response)
+		assert.IsType(t, &errorHandler.UnAuthorizedError{}, err)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		stored := &entities.RefreshToken{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}
+		mockTokenRepo.On("FindRefreshTokenByHash", helper.HashToken(refreshToken)).Return(stored, nil)
+		response, err := uc.Refresh(&dto.RefreshRequest{RefreshToken: refreshToken})
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "Refresh token has expired")
+	})
+
+	t.Run("Reused token revokes all sessions", func(t *testing.T) {
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		revokedAt := time.Now().Add(-time.Minute)
+		stored := &entities.RefreshToken{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+		mockTokenRepo.On("FindRefreshTokenByHash", helper.HashToken(refreshToken)).Return(stored, nil)
+		mockTokenRepo.On("RevokeAllForUser", 1).Return(nil)
+		response, err := uc.Refresh(&dto.RefreshRequest{RefreshToken: refreshToken})
+		assert.Nil(t, response)
+		assert.IsType(t, &errorHandler.UnAuthorizedError{}, err)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Concurrent refresh", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(mockRepo, mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		stored := &entities.RefreshToken{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+		mockTokenRepo.On("FindRefreshTokenByHash", helper.HashToken(refreshToken)).Return(stored, nil)
+		mockRepo.On("FindByID", 1).Return(user, nil)
+		mockTokenRepo.On("RotateRefreshToken", stored, mock.Anything).Return(nil, repositories.ErrRefreshTokenRevoked)
+		response, err := uc.Refresh(&dto.RefreshRequest{RefreshToken: refreshToken})
+		assert.Nil(t, response)
+		assert.IsType(t, &errorHandler.UnAuthorizedError{}, err)
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		response, err := uc.Refresh(&dto.RefreshRequest{})
+		assert.Nil(t, response)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
+
+func TestAuthUsecase_Logout(t *testing.T) {
+	expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	claims := &helper.JWTClaims{Id: 1, StandardClaims: jwt.StandardClaims{Id: "jti", ExpiresAt: expiresAt.Unix()}}
+
+	t.Run("Success", func(t *testing.T) {
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		stored := &entities.RefreshToken{ID: 1, UserID: 1}
+		mockTokenRepo.On("FindRefreshTokenByHash", helper.HashToken("refresh-token")).Return(stored, nil)
+		mockTokenRepo.On("RevokeRefreshToken", stored).Return(nil)
+		mockTokenRepo.On("DenyAccessToken", "jti", expiresAt).Return(nil)
+		err := uc.Logout(claims, &dto.RefreshRequest{RefreshToken: "refresh-token"})
+		assert.NoError(t, err)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refresh token of another user", func(t *testing.T) {
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		mockTokenRepo.On("FindRefreshTokenByHash", helper.HashToken("refresh-token")).Return(&entities.RefreshToken{ID: 1, UserID: 2}, nil)
+		err := uc.Logout(claims, &dto.RefreshRequest{RefreshToken: "refresh-token"})
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		mockTokenRepo.AssertNotCalled(t, "RevokeRefreshToken", mock.Anything)
+	})
+
+	t.Run("All sessions", func(t *testing.T) {
+		mockTokenRepo := new(mocks.MockTokenRepository)
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), mockTokenRepo, mailer.NewOutbox(""), AuthOptions{})
+		mockTokenRepo.On("RevokeAllForUser", 1).Return(nil)
+		mockTokenRepo.On("DenyAccessToken", "jti", expiresAt).Return(nil)
+		err := uc.LogoutAll(claims)
+		assert.NoError(t, err)
+		mockTokenRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthUsecase_VerifyEmail(t *testing.T) {
+	user := &entities.User{Id: 1, Email: "admin@example.com"}
+	token, err := helper.GenerateActionToken(user, helper.PurposeVerifyEmail, time.Hour)
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("UpdateUser", mock.MatchedBy(func(u *entities.User) bool {
+			return u.EmailVerifiedAt != nil
+		})).Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		_, err := uc.VerifyEmail(token)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Email changed since", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "other@example.com"}, nil)
+		_, err := uc.VerifyEmail(token)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		_, err := uc.VerifyEmail("not-a-token")
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
+
+func TestAuthUsecase_ResendVerification(t *testing.T) {
+	req := &dto.EmailRequest{Email: "admin@example.com"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{})
+		sentAt := time.Now().Add(-2 * time.Minute)
+		mockRepo.On("FindByEmail", "admin@example.com").Return(&entities.User{Id: 1, Email: "admin@example.com", VerificationSentAt: &sentAt}, nil)
+		mockRepo.On("UpdateUser", mock.Anything).Return(&entities.User{Id: 1}, nil)
+		err := uc.ResendVerification(req)
+		assert.NoError(t, err)
+		assert.Len(t, outbox.Messages(), 1)
+	})
+
+	t.Run("Throttled", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{})
+		sentAt := time.Now().Add(-10 * time.Second)
+		mockRepo.On("FindByEmail", "admin@example.com").Return(&entities.User{Id: 1, Email: "admin@example.com", VerificationSentAt: &sentAt}, nil)
+		err := uc.ResendVerification(req)
+		assert.NoError(t, err)
+		assert.Empty(t, outbox.Messages())
+		mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+	})
+
+	t.Run("Mailer failure is not reported", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), failingMailer{}, AuthOptions{})
+		mockRepo.On("FindByEmail", "admin@example.com").Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("UpdateUser", mock.Anything).Return(&entities.User{Id: 1}, nil)
+		assert.NoError(t, uc.ResendVerification(req))
+	})
+
+	t.Run("Already verified", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{})
+		verifiedAt := time.Now()
+		mockRepo.On("FindByEmail", "admin@example.com").Return(&entities.User{Id: 1, Email: "admin@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+		err := uc.ResendVerification(req)
+		assert.NoError(t, err)
+		assert.Empty(t, outbox.Messages())
+	})
+
+	t.Run("Unknown email", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByEmail", "admin@example.com").Return(nil, gorm.ErrRecordNotFound)
+		assert.NoError(t, uc.ResendVerification(req))
+	})
+}
+
+// failingMailer fails to send every message.
+type failingMailer struct{}
+
+func (failingMailer) Send(mailer.Message) error {
+	return errors.New("smtp unavailable")
+}
+
+func TestAuthUsecase_ForgotPassword(t *testing.T) {
+	req := &dto.EmailRequest{Email: "admin@example.com"}
+
+	t.Run("Configured reset page", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{
+			PasswordResetURL: "https://app.example.com/reset?lang=en",
+		})
+		mockRepo.On("FindByEmail", "admin@example.com").Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("CountPasswordResetTokensSince", 1, mock.Anything).Return(int64(0), nil)
+		mockRepo.On("CreatePasswordResetToken", mock.Anything).Return(&entities.PasswordResetToken{ID: 1}, nil)
+		assert.NoError(t, uc.ForgotPassword(req))
+		message, _ := outbox.Last("admin@example.com")
+		assert.Contains(t, message.Body, "https://app.example.com/reset?lang=en&token=")
+	})
+
+	t.Run("Mailer failure is not reported", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), failingMailer{}, AuthOptions{})
+		mockRepo.On("FindByEmail", "admin@example.com").Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("CountPasswordResetTokensSince", 1, mock.Anything).Return(int64(0), nil)
+		mockRepo.On("CreatePasswordResetToken", mock.Anything).Return(&entities.PasswordResetToken{ID: 1}, nil)
+		assert.NoError(t, uc.ForgotPassword(req))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{})
+		mockRepo.On("FindByEmail", "admin@example.com").Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("CountPasswordResetTokensSince", 1, mock.Anything).Return(int64(0), nil)
+		mockRepo.On("CreatePasswordResetToken", mock.MatchedBy(func(token *entities.PasswordResetToken) bool {
+			return token.UserID == 1 && len(token.TokenHash) == 64 && token.ExpiresAt.After(time.Now())
+		})).Return(&entities.PasswordResetToken{ID: 1}, nil)
+		err := uc.ForgotPassword(req)
+		assert.NoError(t, err)
+		message, ok := outbox.Last("admin@example.com")
+		assert.True(t, ok)
+		assert.Contains(t, message.Body, "/password/reset?token=")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Throttled", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{})
+		mockRepo.On("FindByEmail", "admin@example.com").Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		mockRepo.On("CountPasswordResetTokensSince", 1, mock.Anything).Return(int64(1), nil)
+		assert.NoError(t, uc.ForgotPassword(req))
+		assert.Empty(t, outbox.Messages())
+		mockRepo.AssertNotCalled(t, "CreatePasswordResetToken", mock.Anything)
+	})
+
+	t.Run("Unknown email", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{})
+		mockRepo.On("FindByEmail", "admin@example.com").Return(nil, gorm.ErrRecordNotFound)
+		assert.NoError(t, uc.ForgotPassword(req))
+		assert.Empty(t, outbox.Messages())
+	})
+}
+
+func TestAuthUsecase_ResetPassword(t *testing.T) {
+	req := &dto.PasswordResetRequest{Token: "reset-token", Password: "new-password"}
+	hash := helper.HashToken("reset-token")
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		token := &entities.PasswordResetToken{ID: 1, UserID: 1, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+		mockRepo.On("FindPasswordResetTokenByHash", hash).Return(token, nil)
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", Password: "old"}, nil)
+		mockRepo.On("ResetPassword", token, mock.MatchedBy(func(u *entities.User) bool {
+			return helper.VerifyPassword("new-password", u.Password) == nil && u.EmailVerifiedAt != nil
+		})).Return(nil)
+		assert.NoError(t, uc.ResetPassword(req))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindPasswordResetTokenByHash", hash).Return(&entities.PasswordResetToken{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+		assert.IsType(t, &errorHandler.BadRequestError{}, uc.ResetPassword(req))
+		mockRepo.AssertNotCalled(t, "ResetPassword", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Already used", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		usedAt := time.Now()
+		mockRepo.On("FindPasswordResetTokenByHash", hash).Return(&entities.PasswordResetToken{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}, nil)
+		assert.IsType(t, &errorHandler.BadRequestError{}, uc.ResetPassword(req))
+	})
+
+	t.Run("Used concurrently", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindPasswordResetTokenByHash", hash).Return(&entities.PasswordResetToken{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1}, nil)
+		mockRepo.On("ResetPassword", mock.Anything, mock.Anything).Return(repositories.ErrResetTokenUsed)
+		assert.IsType(t, &errorHandler.BadRequestError{}, uc.ResetPassword(req))
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindPasswordResetTokenByHash", hash).Return(nil, gorm.ErrRecordNotFound)
+		assert.IsType(t, &errorHandler.BadRequestError{}, uc.ResetPassword(req))
+	})
+
+	t.Run("Weak password", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindPasswordResetTokenByHash", hash).Return(&entities.PasswordResetToken{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com"}, nil)
+		err := uc.ResetPassword(&dto.PasswordResetRequest{Token: "reset-token", Password: "admin"})
+		var badRequest *errorHandler.BadRequestError
+		assert.ErrorAs(t, err, &badRequest)
+		assert.NotEmpty(t, badRequest.Fields)
+		mockRepo.AssertNotCalled(t, "ResetPassword", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthUsecase_ChangePassword(t *testing.T) {
+	current, _ := helper.HashPassword("old-password")
+	claims := &helper.JWTClaims{Id: 1, StandardClaims: jwt.StandardClaims{Id: "current-jti"}}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", Password: current}, nil)
+		mockRepo.On("UpdatePassword", mock.MatchedBy(func(u *entities.User) bool {
+			return helper.VerifyPassword("new-password", u.Password) == nil
+		}), "current-jti", false).Return(nil)
+		err := uc.ChangePassword(claims, &dto.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "new-password"})
+		assert.NoError(t, err)
+		message, ok := outbox.Last("admin@example.com")
+		assert.True(t, ok)
+		assert.Equal(t, "Your password was changed", message.Subject)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Revokes personal access tokens on request", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", Password: current}, nil)
+		mockRepo.On("UpdatePassword", mock.Anything, "current-jti", true).Return(nil)
+		err := uc.ChangePassword(claims, &dto.ChangePasswordRequest{
+			CurrentPassword:            "old-password",
+			NewPassword:                "new-password",
+			RevokePersonalAccessTokens: true,
+		})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong current password", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Password: current}, nil)
+		err := uc.ChangePassword(claims, &dto.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "new-password"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Missing fields", func(t *testing.T) {
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		err := uc.ChangePassword(claims, &dto.ChangePasswordRequest{CurrentPassword: "old-password"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Weak new password", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", Password: current}, nil)
+		err := uc.ChangePassword(claims, &dto.ChangePasswordRequest{CurrentPassword: "old-password", NewPassword: "admin@example.com"})
+		var badRequest *errorHandler.BadRequestError
+		assert.ErrorAs(t, err, &badRequest)
+		assert.Equal(t, []dto.FieldError{{Field: "new_password", Message: "must not be your email address"}}, badRequest.Fields)
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthUsecase_ChangeEmail(t *testing.T) {
+	password, _ := helper.HashPassword("password")
+	claims := &helper.JWTClaims{Id: 1}
+	req := &dto.ChangeEmailRequest{Email: "new@example.com", Password: "password"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", Password: password}, nil)
+		mockRepo.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("UpdateUser", mock.MatchedBy(func(u *entities.User) bool {
+			return u.Email == "admin@example.com" && u.PendingEmail != nil && *u.PendingEmail == "new@example.com"
+		})).Return(&entities.User{Id: 1}, nil)
+		err := uc.ChangeEmail(claims, req)
+		assert.NoError(t, err)
+		confirmation, ok := outbox.Last("new@example.com")
+		assert.True(t, ok)
+		assert.Contains(t, confirmation.Body, "/email/confirm?token=")
+		_, ok = outbox.Last("admin@example.com")
+		assert.True(t, ok)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Email already used", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", Password: password}, nil)
+		mockRepo.On("FindByEmail", "new@example.com").Return(&entities.User{Id: 2, Email: "new@example.com"}, nil)
+		err := uc.ChangeEmail(claims, req)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", Password: password}, nil)
+		err := uc.ChangeEmail(claims, &dto.ChangeEmailRequest{Email: "new@example.com", Password: "wrong"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Invalid email", func(t *testing.T) {
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		err := uc.ChangeEmail(claims, &dto.ChangeEmailRequest{Email: "not-an-email", Password: "password"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+}
+
+func TestAuthUsecase_ConfirmEmailChange(t *testing.T) {
+	user := &entities.User{Id: 1, Email: "admin@example.com"}
+	token, err := helper.GenerateEmailChangeToken(user, "new@example.com", time.Hour)
+	assert.NoError(t, err)
+	pending := "new@example.com"
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		outbox := mailer.NewOutbox("")
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), outbox, AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", PendingEmail: &pending}, nil)
+		mockRepo.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("UpdateEmail", 1, "new@example.com").Return(nil)
+		updated, err := uc.ConfirmEmailChange(token)
+		assert.NoError(t, err)
+		assert.Equal(t, "new@example.com", updated.Email)
+		assert.Nil(t, updated.PendingEmail)
+		_, ok := outbox.Last("admin@example.com")
+		assert.True(t, ok)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Superseded by another request", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		other := "other@example.com"
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", PendingEmail: &other}, nil)
+		_, err := uc.ConfirmEmailChange(token)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "UpdateEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Confirmed concurrently", func(t *testing.T) {
+		mockRepo := new(mocks.MockAuthRepository)
+		uc := NewAuthUsecase(mockRepo, new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		mockRepo.On("FindByID", 1).Return(&entities.User{Id: 1, Email: "admin@example.com", PendingEmail: &pending}, nil)
+		mockRepo.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("UpdateEmail", 1, "new@example.com").Return(repositories.ErrEmailChangeStale)
+		_, err := uc.ConfirmEmailChange(token)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Verification token", func(t *testing.T) {
+		verify, _ := helper.GenerateActionToken(user, helper.PurposeVerifyEmail, time.Hour)
+		uc := NewAuthUsecase(new(mocks.MockAuthRepository), new(mocks.MockTokenRepository), mailer.NewOutbox(""), AuthOptions{})
+		_, err := uc.ConfirmEmailChange(verify)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
 }
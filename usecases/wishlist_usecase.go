@@ -1,37 +1,89 @@
 package usecases
 
 import (
+	"errors"
 	"go-wishlist-api-2/dto"
 	"go-wishlist-api-2/entities"
 	"go-wishlist-api-2/errorHandler"
 	"go-wishlist-api-2/repositories"
+
+	"gorm.io/gorm"
+	"time"
 )
 
 type WishlistUsecase interface {
-	GetAll() ([]*entities.Wishlist, error)
-	Create(wishlist *dto.WishlistRequest) (*entities.Wishlist, error)
+	GetAll(userID int, query *dto.WishlistQuery) ([]*entities.Wishlist, *dto.PageMeta, error)
+	GetByID(userID int, id uint) (*entities.Wishlist, error)
+	Create(userID int, wishlist *dto.WishlistRequest) (*entities.Wishlist, error)
+	Update(userID int, id uint, wishlist *dto.WishlistRequest) (*entities.Wishlist, error)
+	Patch(userID int, id uint, wishlist *dto.WishlistPatchRequest) (*entities.Wishlist, error)
+	Delete(userID int, id uint) error
+	GetTrash(userID int) ([]*entities.Wishlist, error)
+	Restore(userID int, id uint) (*entities.Wishlist, error)
+	Purge(userID int, id uint) error
+	PurgeExpired(retention time.Duration) (int64, error)
+	History(userID int, id uint) ([]*entities.AchievementEvent, error)
 }
 
 type wishlistUsecase struct {
-	repository repositories.WishlistRepository
+	repository     repositories.WishlistRepository
+	listRepository repositories.ListRepository
+	tagRepository  repositories.TagRepository
 }
 
-func NewWishlistUsecase(r repositories.WishlistRepository) *wishlistUsecase {
-	return &wishlistUsecase{r}
+func NewWishlistUsecase(r repositories.WishlistRepository, lr repositories.ListRepository, tr repositories.TagRepository) *wishlistUsecase {
+	return &wishlistUsecase{r, lr, tr}
 }
 
-func (uc *wishlistUsecase) GetAll() ([]*entities.Wishlist, error) {
-	wishlists, err := uc.repository.GetAll()
+func (uc *wishlistUsecase) GetAll(userID int, query *dto.WishlistQuery) ([]*entities.Wishlist, *dto.PageMeta, error) {
+	filter, err := buildWishlistFilter(userID, query)
 	if err != nil {
-		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+		return nil, nil, err
+	}
+	if filter.ListID != nil {
+		if _, err := findOwnedList(uc.listRepository, userID, *filter.ListID); err != nil {
+			return nil, nil, err
+		}
+	}
+	limit := filter.Limit
+	// Fetch one extra row to find out whether there is a next page.
+	filter.Limit++
+
+	wishlists, total, err := uc.repository.GetAll(filter)
+	if err != nil {
+		return nil, nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+
+	meta := &dto.PageMeta{Total: total, Limit: limit, Offset: filter.Offset}
+	if len(wishlists) > limit {
+		wishlists = wishlists[:limit]
+		meta.NextCursor = encodeCursor(filter.Sort, wishlists[limit-1])
 	}
-	return wishlists, err
+	return wishlists, meta, nil
+}
+
+func (uc *wishlistUsecase) GetByID(userID int, id uint) (*entities.Wishlist, error) {
+	return uc.findOwned(userID, id)
 }
 
-func (uc *wishlistUsecase) Create(req *dto.WishlistRequest) (*entities.Wishlist, error) {
-	wishtlist := &entities.Wishlist{
-		Title:      req.Title,
-		IsAchieved: req.IsAchieved,
+func (uc *wishlistUsecase) Create(userID int, req *dto.WishlistRequest) (*entities.Wishlist, error) {
+	wishtlist := &entities.Wishlist{UserID: userID}
+	applyWishlistRequest(wishtlist, req)
+	if wishtlist.IsAchieved {
+		recordAchievement(wishtlist, time.Now())
+	}
+	if err := normalizeWishlist(wishtlist); err != nil {
+		return nil, err
+	}
+	listID, err := uc.resolveList(userID, req.ListID)
+	if err != nil {
+		return nil, err
+	}
+	wishtlist.ListID = &listID
+	if req.Tags != nil {
+		if wishtlist.Tags, err = uc.resolveTags(userID, req.Tags); err != nil {
+			return nil, err
+		}
 	}
 	newWishlist, err := uc.repository.CreateWishlist(wishtlist)
 	if err != nil {
@@ -39,3 +91,252 @@ func (uc *wishlistUsecase) Create(req *dto.WishlistRequest) (*entities.Wishlist,
 	}
 	return newWishlist, nil
 }
+
+func (uc *wishlistUsecase) Update(userID int, id uint, req *dto.WishlistRequest) (*entities.Wishlist, error) {
+	wishlist, err := uc.findOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.ListID != nil || wishlist.ListID == nil {
+		listID, err := uc.resolveList(userID, req.ListID)
+		if err != nil {
+			return nil, err
+		}
+		wishlist.ListID = &listID
+	}
+	wasAchieved := wishlist.IsAchieved
+	applyWishlistRequest(wishlist, req)
+	if wishlist.IsAchieved != wasAchieved {
+		recordAchievement(wishlist, time.Now())
+	}
+	if err := normalizeWishlist(wishlist); err != nil {
+		return nil, err
+	}
+	if req.Tags != nil {
+		if wishlist.Tags, err = uc.resolveTags(userID, req.Tags); err != nil {
+			return nil, err
+		}
+	}
+	return uc.save(wishlist)
+}
+
+func (uc *wishlistUsecase) Patch(userID int, id uint, req *dto.WishlistPatchRequest) (*entities.Wishlist, error) {
+	wishlist, err := uc.findOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.ListID != nil {
+		if _, err := findOwnedList(uc.listRepository, userID, *req.ListID); err != nil {
+			return nil, err
+		}
+		wishlist.ListID = req.ListID
+	}
+	if req.Title != nil {
+		wishlist.Title = *req.Title
+	}
+	if req.IsAchieved != nil && *req.IsAchieved != wishlist.IsAchieved {
+		wishlist.IsAchieved = *req.IsAchieved
+		recordAchievement(wishlist, time.Now())
+	}
+	if req.Price != nil {
+		wishlist.PriceAmount = req.Price
+	}
+	if req.Currency != nil {
+		wishlist.Currency = *req.Currency
+	}
+	if req.URL != nil {
+		wishlist.URL = *req.URL
+	}
+	if req.Priority != nil {
+		wishlist.Priority = *req.Priority
+	}
+	if req.Quantity != nil {
+		wishlist.Quantity = *req.Quantity
+	}
+	if req.Notes != nil {
+		wishlist.Notes = *req.Notes
+	}
+	if req.TargetDate != nil {
+		wishlist.TargetDate = req.TargetDate.TimePtr()
+	}
+	if err := normalizeWishlist(wishlist); err != nil {
+		return nil, err
+	}
+	if req.Tags != nil {
+		if wishlist.Tags, err = uc.resolveTags(userID, req.Tags); err != nil {
+			return nil, err
+		}
+	}
+	return uc.save(wishlist)
+}
+
+func (uc *wishlistUsecase) Delete(userID int, id uint) error {
+	if _, err := uc.findOwned(userID, id); err != nil {
+		return err
+	}
+	if err := uc.repository.DeleteWishlist(id); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+func (uc *wishlistUsecase) GetTrash(userID int) ([]*entities.Wishlist, error) {
+	wishlists, err := uc.repository.GetTrash(userID)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return wishlists, nil
+}
+
+func (uc *wishlistUsecase) Restore(userID int, id uint) (*entities.Wishlist, error) {
+	if _, err := uc.findTrashed(userID, id); err != nil {
+		return nil, err
+	}
+	if err := uc.repository.RestoreWishlist(id); err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	wishlist, err := uc.findOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	// The list the item came from may have been deleted in the meantime.
+	if wishlist.ListID != nil {
+		if _, err := uc.listRepository.FindByID(*wishlist.ListID); err == nil {
+			return wishlist, nil
+		}
+	}
+	listID, err := uc.resolveList(userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	wishlist.ListID = &listID
+	return uc.save(wishlist)
+}
+
+func (uc *wishlistUsecase) Purge(userID int, id uint) error {
+	if _, err := uc.findTrashed(userID, id); err != nil {
+		return err
+	}
+	if err := uc.repository.PurgeWishlist(id); err != nil {
+		return &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return nil
+}
+
+// PurgeExpired permanently removes every trashed wishlist that has been in
+// the trash for longer than retention.
+func (uc *wishlistUsecase) PurgeExpired(retention time.Duration) (int64, error) {
+	purged, err := uc.repository.PurgeDeletedBefore(time.Now().Add(-retention))
+	if err != nil {
+		return 0, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return purged, nil
+}
+
+func (uc *wishlistUsecase) History(userID int, id uint) ([]*entities.AchievementEvent, error) {
+	if _, err := uc.findOwned(userID, id); err != nil {
+		return nil, err
+	}
+	events, err := uc.repository.GetAchievementHistory(id)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return events, nil
+}
+
+func (uc *wishlistUsecase) save(wishlist *entities.Wishlist) (*entities.Wishlist, error) {
+	updated, err := uc.repository.UpdateWishlist(wishlist)
+	if err != nil {
+		if errors.Is(err, repositories.ErrQuantityBelowClaimed) {
+			return nil, &errorHandler.ConflictError{Message: "Quantity cannot be lower than the units guests already claimed"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return updated, nil
+}
+
+func applyWishlistRequest(wishlist *entities.Wishlist, req *dto.WishlistRequest) {
+	wishlist.Title = req.Title
+	wishlist.IsAchieved = req.IsAchieved
+	wishlist.PriceAmount = req.Price
+	wishlist.Currency = req.Currency
+	wishlist.URL = req.URL
+	wishlist.Priority = req.Priority
+	wishlist.Quantity = req.Quantity
+	wishlist.Notes = req.Notes
+	wishlist.TargetDate = req.TargetDate.TimePtr()
+}
+
+// recordAchievement stamps AchievedAt according to the current IsAchieved
+// value and appends the matching event, which is stored on the next save.
+func recordAchievement(wishlist *entities.Wishlist, at time.Time) {
+	if wishlist.IsAchieved {
+		wishlist.AchievedAt = &at
+	} else {
+		wishlist.AchievedAt = nil
+	}
+	wishlist.AchievementEvents = append(wishlist.AchievementEvents, entities.AchievementEvent{
+		UserID:    wishlist.UserID,
+		Achieved:  wishlist.IsAchieved,
+		CreatedAt: at,
+	})
+}
+
+// resolveList returns the id of the list an item should be stored in: the
+// requested one if the user owns it, otherwise the user's default list.
+func (uc *wishlistUsecase) resolveList(userID int, listID *uint) (uint, error) {
+	if listID != nil {
+		list, err := findOwnedList(uc.listRepository, userID, *listID)
+		if err != nil {
+			return 0, err
+		}
+		return list.ID, nil
+	}
+	list, err := uc.listRepository.FindOrCreateDefault(userID)
+	if err != nil {
+		return 0, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return list.ID, nil
+}
+
+func (uc *wishlistUsecase) resolveTags(userID int, names []string) ([]entities.Tag, error) {
+	names, err := normalizeTagNames(names)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := uc.tagRepository.FindOrCreate(userID, names)
+	if err != nil {
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	return tags, nil
+}
+
+// findOwned loads a wishlist and makes sure it belongs to userID.
+func (uc *wishlistUsecase) findOwned(userID int, id uint) (*entities.Wishlist, error) {
+	wishlist, err := uc.repository.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.NotFoundError{Message: "Wishlist not found"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if wishlist.UserID != userID {
+		return nil, &errorHandler.ForbiddenError{Message: "You don't have access to this wishlist"}
+	}
+	return wishlist, nil
+}
+
+// findTrashed is findOwned for wishlists that are currently in the trash.
+func (uc *wishlistUsecase) findTrashed(userID int, id uint) (*entities.Wishlist, error) {
+	wishlist, err := uc.repository.FindTrashedByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &errorHandler.NotFoundError{Message: "Wishlist not found in trash"}
+		}
+		return nil, &errorHandler.InternalServerError{Message: err.Error()}
+	}
+	if wishlist.UserID != userID {
+		return nil, &errorHandler.ForbiddenError{Message: "You don't have access to this wishlist"}
+	}
+	return wishlist, nil
+}
@@ -7,7 +7,11 @@ import (
 	"go-wishlist-api-2/drivers/mysql/mocks"
 	"go-wishlist-api-2/dto"
 	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"go-wishlist-api-2/repositories"
+	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
 func TestWishlistUsecase_GetAll(t *testing.T) {
@@ -17,27 +21,84 @@ func TestWishlistUsecase_GetAll(t *testing.T) {
 			{ID: 2, Title: "Wishlist 2", IsAchieved: true},
 		}
 		mockRepo := new(mocks.MockWishlistRepository)
-		uc := NewWishlistUsecase(mockRepo)
-		mockRepo.On("GetAll").Return(mockWishlists, nil)
-		wishlists, err := uc.GetAll()
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("GetAll", mock.MatchedBy(func(f *dto.WishlistFilter) bool {
+			return f.UserID == 1 && f.Limit == defaultPageLimit+1 && len(f.Sort) == 1 && f.Sort[0].Column == "id"
+		})).Return(mockWishlists, int64(2), nil)
+		wishlists, meta, err := uc.GetAll(1, &dto.WishlistQuery{})
 		assert.NoError(t, err)
 		assert.NotNil(t, wishlists)
 		assert.Equal(t, len(mockWishlists), len(wishlists))
+		assert.Equal(t, int64(2), meta.Total)
+		assert.Equal(t, defaultPageLimit, meta.Limit)
+		assert.Empty(t, meta.NextCursor)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Failed", func(t *testing.T) {
 		mockRepo := new(mocks.MockWishlistRepository)
-		uc := NewWishlistUsecase(mockRepo)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
 		expectedError := errors.New("Failed to get wishlists")
-		mockRepo.On("GetAll").Return(nil, expectedError)
-		wishlists, err := uc.GetAll()
+		mockRepo.On("GetAll", mock.Anything).Return(nil, int64(0), expectedError)
+		wishlists, _, err := uc.GetAll(1, &dto.WishlistQuery{})
 		assert.Error(t, err)
 		assert.Nil(t, wishlists)
 		assert.EqualError(t, err, expectedError.Error())
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Next cursor", func(t *testing.T) {
+		mockWishlists := []*entities.Wishlist{
+			{ID: 3, Title: "c"},
+			{ID: 2, Title: "b"},
+			{ID: 1, Title: "a"},
+		}
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("GetAll", mock.Anything).Return(mockWishlists, int64(3), nil).Once()
+		wishlists, meta, err := uc.GetAll(1, &dto.WishlistQuery{Limit: 2, Sort: "-title"})
+		assert.NoError(t, err)
+		assert.Len(t, wishlists, 2)
+		assert.NotEmpty(t, meta.NextCursor)
+
+		mockRepo.On("GetAll", mock.MatchedBy(func(f *dto.WishlistFilter) bool {
+			return len(f.After) == 2 && f.After[0] == "b" && f.After[1] == uint(2)
+		})).Return(mockWishlists[2:], int64(3), nil).Once()
+		wishlists, meta, err = uc.GetAll(1, &dto.WishlistQuery{Limit: 2, Sort: "-title", Cursor: meta.NextCursor})
+		assert.NoError(t, err)
+		assert.Len(t, wishlists, 1)
+		assert.Empty(t, meta.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Tag filter", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("GetAll", mock.MatchedBy(func(f *dto.WishlistFilter) bool {
+			return len(f.Tags) == 2 && f.Tags[0] == "tech" && !f.MatchAllTags
+		})).Return([]*entities.Wishlist{}, int64(0), nil)
+		_, _, err := uc.GetAll(1, &dto.WishlistQuery{Tags: []string{"Tech", "books"}, TagMode: "OR"})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+
+		_, _, err = uc.GetAll(1, &dto.WishlistQuery{Tags: []string{"tech"}, TagMode: "xor"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
+
+	t.Run("Invalid sort", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		_, _, err := uc.GetAll(1, &dto.WishlistQuery{Sort: "password"})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "GetAll", mock.Anything)
+	})
+
+	t.Run("Cursor with offset", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		_, _, err := uc.GetAll(1, &dto.WishlistQuery{Cursor: "abc", Offset: 10})
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+	})
 }
 
 func TestWishlistUsecase_Create(t *testing.T) {
@@ -47,31 +108,343 @@ func TestWishlistUsecase_Create(t *testing.T) {
 	}
 	expectedResult := &entities.Wishlist{
 		ID:         1,
+		UserID:     1,
 		Title:      req.Title,
 		IsAchieved: req.IsAchieved,
 	}
 	t.Run("Success", func(t *testing.T) {
 		mockRepo := new(mocks.MockWishlistRepository)
-		uc := NewWishlistUsecase(mockRepo)
-		mockRepo.On("CreateWishlist", mock.Anything).Return(expectedResult, nil)
-		newWishlist, err := uc.Create(req)
+		mockListRepo := new(mocks.MockListRepository)
+		uc := NewWishlistUsecase(mockRepo, mockListRepo, new(mocks.MockTagRepository))
+		mockListRepo.On("FindOrCreateDefault", 1).Return(&entities.List{ID: 5, UserID: 1, IsDefault: true}, nil)
+		mockRepo.On("CreateWishlist", mock.MatchedBy(func(w *entities.Wishlist) bool {
+			return w.ListID != nil && *w.ListID == 5
+		})).Return(expectedResult, nil)
+		newWishlist, err := uc.Create(1, req)
 		assert.NoError(t, err)
 		assert.NotNil(t, newWishlist)
 		assert.Equal(t, req.Title, newWishlist.Title)
 		assert.Equal(t, req.IsAchieved, newWishlist.IsAchieved)
+		assert.Equal(t, 1, newWishlist.UserID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("With tags", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		mockListRepo := new(mocks.MockListRepository)
+		mockTagRepo := new(mocks.MockTagRepository)
+		uc := NewWishlistUsecase(mockRepo, mockListRepo, mockTagRepo)
+		mockListRepo.On("FindOrCreateDefault", 1).Return(&entities.List{ID: 5, UserID: 1, IsDefault: true}, nil)
+		mockTagRepo.On("FindOrCreate", 1, []string{"tech", "books"}).Return([]entities.Tag{{ID: 1, Name: "tech"}, {ID: 2, Name: "books"}}, nil)
+		mockRepo.On("CreateWishlist", mock.MatchedBy(func(w *entities.Wishlist) bool {
+			return len(w.Tags) == 2 && w.Tags[1].ID == 2
+		})).Return(expectedResult, nil)
+		_, err := uc.Create(1, &dto.WishlistRequest{Title: "ngoding", Tags: []string{"Tech", "books", "tech"}})
+		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
+		mockTagRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid fields", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		mockListRepo := new(mocks.MockListRepository)
+		uc := NewWishlistUsecase(mockRepo, mockListRepo, new(mocks.MockTagRepository))
+		price := int64(1999)
+		newWishlist, err := uc.Create(1, &dto.WishlistRequest{Title: "ngoding", Price: &price})
+		assert.Nil(t, newWishlist)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "CreateWishlist", mock.Anything)
+		mockListRepo.AssertNotCalled(t, "FindOrCreateDefault", mock.Anything)
+	})
+
+	t.Run("Into another user's list", func(t *testing.T) {
+		listID := uint(7)
+		mockRepo := new(mocks.MockWishlistRepository)
+		mockListRepo := new(mocks.MockListRepository)
+		uc := NewWishlistUsecase(mockRepo, mockListRepo, new(mocks.MockTagRepository))
+		mockListRepo.On("FindByID", listID).Return(&entities.List{ID: listID, UserID: 2}, nil)
+		newWishlist, err := uc.Create(1, &dto.WishlistRequest{ListID: &listID, Title: "ngoding"})
+		assert.Nil(t, newWishlist)
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		mockRepo.AssertNotCalled(t, "CreateWishlist", mock.Anything)
 	})
 
 	t.Run("Failed", func(t *testing.T) {
 		mockRepo := new(mocks.MockWishlistRepository)
-		uc := NewWishlistUsecase(mockRepo)
+		mockListRepo := new(mocks.MockListRepository)
+		uc := NewWishlistUsecase(mockRepo, mockListRepo, new(mocks.MockTagRepository))
+		mockListRepo.On("FindOrCreateDefault", 1).Return(&entities.List{ID: 5, UserID: 1, IsDefault: true}, nil)
 
 		expectedError := errors.New("Create wishlist failed")
 		mockRepo.On("CreateWishlist", mock.Anything).Return(nil, expectedError)
-		newWishlist, err := uc.Create(req)
+		newWishlist, err := uc.Create(1, req)
 		assert.Error(t, err)
 		assert.Empty(t, newWishlist)
 		assert.EqualError(t, err, expectedError.Error())
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestWishlistUsecase_GetByID(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1, Title: "ngoding"}, nil)
+		wishlist, err := uc.GetByID(1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "ngoding", wishlist.Title)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindByID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+		wishlist, err := uc.GetByID(1, 1)
+		assert.Nil(t, wishlist)
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Owned by another user", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 2}, nil)
+		wishlist, err := uc.GetByID(1, 1)
+		assert.Nil(t, wishlist)
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestWishlistUsecase_Update(t *testing.T) {
+	req := &dto.WishlistRequest{Title: "ngoding golang", IsAchieved: true}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		listID := uint(5)
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1, ListID: &listID, Title: "ngoding"}, nil)
+		mockRepo.On("UpdateWishlist", mock.MatchedBy(func(w *entities.Wishlist) bool {
+			return w.Title == req.Title && w.IsAchieved && *w.ListID == listID
+		})).Return(&entities.Wishlist{ID: 1, UserID: 1, Title: req.Title, IsAchieved: true}, nil)
+		updated, err := uc.Update(1, 1, req)
+		assert.NoError(t, err)
+		assert.Equal(t, req.Title, updated.Title)
+		assert.True(t, updated.IsAchieved)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Un-achieve", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		listID := uint(5)
+		achievedAt := time.Now()
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1, ListID: &listID, Title: "ngoding", IsAchieved: true, AchievedAt: &achievedAt}, nil)
+		mockRepo.On("UpdateWishlist", mock.MatchedBy(func(w *entities.Wishlist) bool {
+			return !w.IsAchieved && w.AchievedAt == nil && len(w.AchievementEvents) == 1 && !w.AchievementEvents[0].Achieved
+		})).Return(&entities.Wishlist{ID: 1, UserID: 1, Title: "ngoding"}, nil)
+		_, err := uc.Update(1, 1, &dto.WishlistRequest{Title: "ngoding"})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unchanged achievement", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		listID := uint(5)
+		achievedAt := time.Now().Add(-time.Hour)
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1, ListID: &listID, Title: "ngoding", IsAchieved: true, AchievedAt: &achievedAt}, nil)
+		mockRepo.On("UpdateWishlist", mock.MatchedBy(func(w *entities.Wishlist) bool {
+			return w.AchievedAt.Equal(achievedAt) && len(w.AchievementEvents) == 0
+		})).Return(&entities.Wishlist{ID: 1, UserID: 1, Title: req.Title, IsAchieved: true}, nil)
+		_, err := uc.Update(1, 1, req)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindByID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+		updated, err := uc.Update(1, 1, req)
+		assert.Nil(t, updated)
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+		mockRepo.AssertNotCalled(t, "UpdateWishlist", mock.Anything)
+	})
+
+	t.Run("Quantity below claimed", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		listID := uint(5)
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1, ListID: &listID, Title: "ngoding", Quantity: 3}, nil)
+		mockRepo.On("UpdateWishlist", mock.Anything).Return(nil, repositories.ErrQuantityBelowClaimed)
+		updated, err := uc.Update(1, 1, &dto.WishlistRequest{Title: "ngoding", Quantity: 1})
+		assert.Nil(t, updated)
+		assert.IsType(t, &errorHandler.ConflictError{}, err)
+	})
+}
+
+func TestWishlistUsecase_Patch(t *testing.T) {
+	t.Run("Invalid priority", func(t *testing.T) {
+		priority := "someday"
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1, Title: "ngoding"}, nil)
+		updated, err := uc.Patch(1, 1, &dto.WishlistPatchRequest{Priority: &priority})
+		assert.Nil(t, updated)
+		assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		mockRepo.AssertNotCalled(t, "UpdateWishlist", mock.Anything)
+	})
+
+	achieved := true
+	mockRepo := new(mocks.MockWishlistRepository)
+	uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+	mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1, Title: "ngoding"}, nil)
+	mockRepo.On("UpdateWishlist", mock.MatchedBy(func(w *entities.Wishlist) bool {
+		return w.Title == "ngoding" && w.IsAchieved && w.AchievedAt != nil &&
+			len(w.AchievementEvents) == 1 && w.AchievementEvents[0].Achieved
+	})).Return(&entities.Wishlist{ID: 1, UserID: 1, Title: "ngoding", IsAchieved: true}, nil)
+	updated, err := uc.Patch(1, 1, &dto.WishlistPatchRequest{IsAchieved: &achieved})
+	assert.NoError(t, err)
+	assert.Equal(t, "ngoding", updated.Title)
+	assert.True(t, updated.IsAchieved)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWishlistUsecase_History(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1}, nil)
+		mockRepo.On("GetAchievementHistory", uint(1)).Return([]*entities.AchievementEvent{
+			{ID: 1, WishlistID: 1, UserID: 1, Achieved: true},
+			{ID: 2, WishlistID: 1, UserID: 1, Achieved: false},
+		}, nil)
+		events, err := uc.History(1, 1)
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Owned by another user", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 2}, nil)
+		events, err := uc.History(1, 1)
+		assert.Nil(t, events)
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		mockRepo.AssertNotCalled(t, "GetAchievementHistory", mock.Anything)
+	})
+}
+
+func TestWishlistUsecase_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1}, nil)
+		mockRepo.On("DeleteWishlist", uint(1)).Return(nil)
+		err := uc.Delete(1, 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Owned by another user", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 2}, nil)
+		err := uc.Delete(1, 1)
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		mockRepo.AssertNotCalled(t, "DeleteWishlist", mock.Anything)
+	})
+}
+
+func TestWishlistUsecase_GetTrash(t *testing.T) {
+	mockRepo := new(mocks.MockWishlistRepository)
+	uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+	mockRepo.On("GetTrash", 1).Return([]*entities.Wishlist{{ID: 1, UserID: 1}}, nil)
+	wishlists, err := uc.GetTrash(1)
+	assert.NoError(t, err)
+	assert.Len(t, wishlists, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWishlistUsecase_Restore(t *testing.T) {
+	listID := uint(5)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		mockListRepo := new(mocks.MockListRepository)
+		uc := NewWishlistUsecase(mockRepo, mockListRepo, new(mocks.MockTagRepository))
+		mockRepo.On("FindTrashedByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1, ListID: &listID}, nil)
+		mockRepo.On("RestoreWishlist", uint(1)).Return(nil)
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1, ListID: &listID}, nil)
+		mockListRepo.On("FindByID", listID).Return(&entities.List{ID: listID, UserID: 1}, nil)
+		wishlist, err := uc.Restore(1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), wishlist.ID)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpdateWishlist", mock.Anything)
+	})
+
+	t.Run("List was deleted", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		mockListRepo := new(mocks.MockListRepository)
+		uc := NewWishlistUsecase(mockRepo, mockListRepo, new(mocks.MockTagRepository))
+		mockRepo.On("FindTrashedByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1, ListID: &listID}, nil)
+		mockRepo.On("RestoreWishlist", uint(1)).Return(nil)
+		mockRepo.On("FindByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1, ListID: &listID}, nil)
+		mockListRepo.On("FindByID", listID).Return(nil, gorm.ErrRecordNotFound)
+		mockListRepo.On("FindOrCreateDefault", 1).Return(&entities.List{ID: 9, UserID: 1, IsDefault: true}, nil)
+		mockRepo.On("UpdateWishlist", mock.MatchedBy(func(w *entities.Wishlist) bool {
+			return *w.ListID == 9
+		})).Return(&entities.Wishlist{ID: 1, UserID: 1}, nil)
+		_, err := uc.Restore(1, 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockListRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not in trash", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindTrashedByID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+		wishlist, err := uc.Restore(1, 1)
+		assert.Nil(t, wishlist)
+		assert.IsType(t, &errorHandler.NotFoundError{}, err)
+		mockRepo.AssertNotCalled(t, "RestoreWishlist", mock.Anything)
+	})
+}
+
+func TestWishlistUsecase_Purge(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindTrashedByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 1}, nil)
+		mockRepo.On("PurgeWishlist", uint(1)).Return(nil)
+		err := uc.Purge(1, 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Owned by another user", func(t *testing.T) {
+		mockRepo := new(mocks.MockWishlistRepository)
+		uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+		mockRepo.On("FindTrashedByID", uint(1)).Return(&entities.Wishlist{ID: 1, UserID: 2}, nil)
+		err := uc.Purge(1, 1)
+		assert.IsType(t, &errorHandler.ForbiddenError{}, err)
+		mockRepo.AssertNotCalled(t, "PurgeWishlist", mock.Anything)
+	})
+}
+
+func TestWishlistUsecase_PurgeExpired(t *testing.T) {
+	mockRepo := new(mocks.MockWishlistRepository)
+	uc := NewWishlistUsecase(mockRepo, new(mocks.MockListRepository), new(mocks.MockTagRepository))
+	mockRepo.On("PurgeDeletedBefore", mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= 24*time.Hour && time.Since(before) < 25*time.Hour
+	})).Return(int64(3), nil)
+	purged, err := uc.PurgeExpired(24 * time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,77 @@
+package usecases
+
+import (
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/currency"
+)
+
+const (
+	maxTitleLength = 255
+	maxURLLength   = 2048
+	maxNotesLength = 2000
+	maxQuantity    = 1000
+)
+
+// normalizeWishlist fills in defaults and validates the user supplied fields
+// of a wishlist before it is stored.
+func normalizeWishlist(wishlist *entities.Wishlist) error {
+	wishlist.Title = strings.TrimSpace(wishlist.Title)
+	if wishlist.Title == "" {
+		return &errorHandler.BadRequestError{Message: "Title must be filled"}
+	}
+	if utf8.RuneCountInString(wishlist.Title) > maxTitleLength {
+		return &errorHandler.BadRequestError{Message: "Title is too long"}
+	}
+
+	wishlist.Currency = strings.ToUpper(strings.TrimSpace(wishlist.Currency))
+	if wishlist.PriceAmount != nil {
+		if *wishlist.PriceAmount < 0 {
+			return &errorHandler.BadRequestError{Message: "Price must not be negative"}
+		}
+		if wishlist.Currency == "" {
+			return &errorHandler.BadRequestError{Message: "Currency is required when a price is set"}
+		}
+	}
+	if wishlist.Currency != "" {
+		if _, err := currency.ParseISO(wishlist.Currency); err != nil {
+			return &errorHandler.BadRequestError{Message: "Currency must be an ISO 4217 code"}
+		}
+	}
+
+	wishlist.URL = strings.TrimSpace(wishlist.URL)
+	if wishlist.URL != "" {
+		parsed, err := url.ParseRequestURI(wishlist.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return &errorHandler.BadRequestError{Message: "URL must be an absolute http or https URL"}
+		}
+		if len(wishlist.URL) > maxURLLength {
+			return &errorHandler.BadRequestError{Message: "URL is too long"}
+		}
+	}
+
+	wishlist.Priority = strings.ToLower(strings.TrimSpace(wishlist.Priority))
+	switch wishlist.Priority {
+	case "":
+		wishlist.Priority = entities.PriorityMedium
+	case entities.PriorityLow, entities.PriorityMedium, entities.PriorityHigh:
+	default:
+		return &errorHandler.BadRequestError{Message: "Priority must be one of low, medium or high"}
+	}
+
+	if wishlist.Quantity == 0 {
+		wishlist.Quantity = 1
+	}
+	if wishlist.Quantity < 1 || wishlist.Quantity > maxQuantity {
+		return &errorHandler.BadRequestError{Message: "Quantity must be between 1 and 1000"}
+	}
+
+	if utf8.RuneCountInString(wishlist.Notes) > maxNotesLength {
+		return &errorHandler.BadRequestError{Message: "Notes are too long"}
+	}
+	return nil
+}
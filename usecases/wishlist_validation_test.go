@@ -0,0 +1,55 @@
+package usecases
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-wishlist-api-2/entities"
+	"go-wishlist-api-2/errorHandler"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeWishlist(t *testing.T) {
+	price := int64(1999)
+	negative := int64(-1)
+
+	t.Run("Defaults", func(t *testing.T) {
+		wishlist := &entities.Wishlist{Title: "  Headphones "}
+		err := normalizeWishlist(wishlist)
+		assert.NoError(t, err)
+		assert.Equal(t, "Headphones", wishlist.Title)
+		assert.Equal(t, entities.PriorityMedium, wishlist.Priority)
+		assert.Equal(t, 1, wishlist.Quantity)
+	})
+
+	t.Run("Normalizes currency and priority", func(t *testing.T) {
+		wishlist := &entities.Wishlist{Title: "Headphones", PriceAmount: &price, Currency: "idr", Priority: "HIGH"}
+		err := normalizeWishlist(wishlist)
+		assert.NoError(t, err)
+		assert.Equal(t, "IDR", wishlist.Currency)
+		assert.Equal(t, entities.PriorityHigh, wishlist.Priority)
+	})
+
+	invalid := []struct {
+		name     string
+		wishlist entities.Wishlist
+	}{
+		{"Empty title", entities.Wishlist{Title: " "}},
+		{"Title too long", entities.Wishlist{Title: strings.Repeat("a", maxTitleLength+1)}},
+		{"Negative price", entities.Wishlist{Title: "a", PriceAmount: &negative, Currency: "USD"}},
+		{"Price without currency", entities.Wishlist{Title: "a", PriceAmount: &price}},
+		{"Unknown currency", entities.Wishlist{Title: "a", PriceAmount: &price, Currency: "XYZ"}},
+		{"Relative URL", entities.Wishlist{Title: "a", URL: "/item/1"}},
+		{"Non http URL", entities.Wishlist{Title: "a", URL: "javascript:alert(1)"}},
+		{"Unknown priority", entities.Wishlist{Title: "a", Priority: "urgent"}},
+		{"Negative quantity", entities.Wishlist{Title: "a", Quantity: -1}},
+		{"Too many", entities.Wishlist{Title: "a", Quantity: maxQuantity + 1}},
+		{"Notes too long", entities.Wishlist{Title: "a", Notes: strings.Repeat("a", maxNotesLength+1)}},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			wishlist := tc.wishlist
+			err := normalizeWishlist(&wishlist)
+			assert.IsType(t, &errorHandler.BadRequestError{}, err)
+		})
+	}
+}